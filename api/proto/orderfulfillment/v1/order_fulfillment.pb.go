@@ -0,0 +1,1377 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: orderfulfillment/v1/order_fulfillment.proto
+
+package orderfulfillmentv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FulfillmentMode int32
+
+const (
+	FulfillmentMode_FULFILLMENT_MODE_UNSPECIFIED FulfillmentMode = 0 // same as OVER
+	FulfillmentMode_FULFILLMENT_MODE_OVER        FulfillmentMode = 1 // ship at least the quantity
+	FulfillmentMode_FULFILLMENT_MODE_UNDER       FulfillmentMode = 2 // ship the most items not exceeding the quantity
+	FulfillmentMode_FULFILLMENT_MODE_EXACT_ONLY  FulfillmentMode = 3 // ship exactly the quantity or fail
+)
+
+// Enum value maps for FulfillmentMode.
+var (
+	FulfillmentMode_name = map[int32]string{
+		0: "FULFILLMENT_MODE_UNSPECIFIED",
+		1: "FULFILLMENT_MODE_OVER",
+		2: "FULFILLMENT_MODE_UNDER",
+		3: "FULFILLMENT_MODE_EXACT_ONLY",
+	}
+	FulfillmentMode_value = map[string]int32{
+		"FULFILLMENT_MODE_UNSPECIFIED": 0,
+		"FULFILLMENT_MODE_OVER":        1,
+		"FULFILLMENT_MODE_UNDER":       2,
+		"FULFILLMENT_MODE_EXACT_ONLY":  3,
+	}
+)
+
+func (x FulfillmentMode) Enum() *FulfillmentMode {
+	p := new(FulfillmentMode)
+	*p = x
+	return p
+}
+
+func (x FulfillmentMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FulfillmentMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_orderfulfillment_v1_order_fulfillment_proto_enumTypes[0].Descriptor()
+}
+
+func (FulfillmentMode) Type() protoreflect.EnumType {
+	return &file_orderfulfillment_v1_order_fulfillment_proto_enumTypes[0]
+}
+
+func (x FulfillmentMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FulfillmentMode.Descriptor instead.
+func (FulfillmentMode) EnumDescriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{0}
+}
+
+type Product struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Sku           string                 `protobuf:"bytes,3,opt,name=sku,proto3" json:"sku,omitempty"`
+	Version       int64                  `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Product) Reset() {
+	*x = Product{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Product) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Product) ProtoMessage() {}
+
+func (x *Product) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Product.ProtoReflect.Descriptor instead.
+func (*Product) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Product) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Product) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Product) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *Product) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type CreateProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Sku           string                 `protobuf:"bytes,2,opt,name=sku,proto3" json:"sku,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateProductRequest) Reset() {
+	*x = CreateProductRequest{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateProductRequest) ProtoMessage() {}
+
+func (x *CreateProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateProductRequest.ProtoReflect.Descriptor instead.
+func (*CreateProductRequest) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateProductRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateProductRequest) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+type GetProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductRequest) Reset() {
+	*x = GetProductRequest{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductRequest) ProtoMessage() {}
+
+func (x *GetProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductRequest.ProtoReflect.Descriptor instead.
+func (*GetProductRequest) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetProductRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProductsRequest) Reset() {
+	*x = ListProductsRequest{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductsRequest) ProtoMessage() {}
+
+func (x *ListProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductsRequest.ProtoReflect.Descriptor instead.
+func (*ListProductsRequest) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{3}
+}
+
+type ListProductsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Products      []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProductsResponse) Reset() {
+	*x = ListProductsResponse{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductsResponse) ProtoMessage() {}
+
+func (x *ListProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductsResponse.ProtoReflect.Descriptor instead.
+func (*ListProductsResponse) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListProductsResponse) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+type UpdateProductRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Sku   string                 `protobuf:"bytes,3,opt,name=sku,proto3" json:"sku,omitempty"`
+	// Version the product must still have; 0 skips the check.
+	ExpectedVersion int64 `protobuf:"varint,4,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *UpdateProductRequest) Reset() {
+	*x = UpdateProductRequest{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProductRequest) ProtoMessage() {}
+
+func (x *UpdateProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProductRequest.ProtoReflect.Descriptor instead.
+func (*UpdateProductRequest) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateProductRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateProductRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateProductRequest) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *UpdateProductRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+type DeleteProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProductRequest) Reset() {
+	*x = DeleteProductRequest{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProductRequest) ProtoMessage() {}
+
+func (x *DeleteProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProductRequest.ProtoReflect.Descriptor instead.
+func (*DeleteProductRequest) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteProductRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteProductResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProductResponse) Reset() {
+	*x = DeleteProductResponse{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProductResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProductResponse) ProtoMessage() {}
+
+func (x *DeleteProductResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProductResponse.ProtoReflect.Descriptor instead.
+func (*DeleteProductResponse) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{7}
+}
+
+type Pack struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pack) Reset() {
+	*x = Pack{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pack) ProtoMessage() {}
+
+func (x *Pack) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pack.ProtoReflect.Descriptor instead.
+func (*Pack) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Pack) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Pack) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type PackSet struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Version       int64                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	Packs         []*Pack                `protobuf:"bytes,3,rep,name=packs,proto3" json:"packs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PackSet) Reset() {
+	*x = PackSet{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PackSet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PackSet) ProtoMessage() {}
+
+func (x *PackSet) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PackSet.ProtoReflect.Descriptor instead.
+func (*PackSet) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PackSet) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *PackSet) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *PackSet) GetPacks() []*Pack {
+	if x != nil {
+		return x.Packs
+	}
+	return nil
+}
+
+type GetPacksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPacksRequest) Reset() {
+	*x = GetPacksRequest{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPacksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPacksRequest) ProtoMessage() {}
+
+func (x *GetPacksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPacksRequest.ProtoReflect.Descriptor instead.
+func (*GetPacksRequest) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetPacksRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+type ReplacePacksRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Sizes     []int64                `protobuf:"varint,2,rep,packed,name=sizes,proto3" json:"sizes,omitempty"`
+	// Version the pack set must still have; 0 skips the check.
+	ExpectedVersion int64 `protobuf:"varint,3,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ReplacePacksRequest) Reset() {
+	*x = ReplacePacksRequest{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplacePacksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplacePacksRequest) ProtoMessage() {}
+
+func (x *ReplacePacksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplacePacksRequest.ProtoReflect.Descriptor instead.
+func (*ReplacePacksRequest) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ReplacePacksRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *ReplacePacksRequest) GetSizes() []int64 {
+	if x != nil {
+		return x.Sizes
+	}
+	return nil
+}
+
+func (x *ReplacePacksRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+type FulfillRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity  int64                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	// Customer whose pack rules apply; empty for none.
+	CustomerId    string          `protobuf:"bytes,3,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	Mode          FulfillmentMode `protobuf:"varint,4,opt,name=mode,proto3,enum=orderfulfillment.v1.FulfillmentMode" json:"mode,omitempty"`
+	MaxOverage    *int64          `protobuf:"varint,5,opt,name=max_overage,json=maxOverage,proto3,oneof" json:"max_overage,omitempty"`
+	MaxOveragePct *float64        `protobuf:"fixed64,6,opt,name=max_overage_pct,json=maxOveragePct,proto3,oneof" json:"max_overage_pct,omitempty"`
+	MaxPacks      *int64          `protobuf:"varint,7,opt,name=max_packs,json=maxPacks,proto3,oneof" json:"max_packs,omitempty"`
+	// Client-chosen ID echoed in the BatchFulfillResponse answering this order.
+	RequestId     string `protobuf:"bytes,8,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FulfillRequest) Reset() {
+	*x = FulfillRequest{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FulfillRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FulfillRequest) ProtoMessage() {}
+
+func (x *FulfillRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FulfillRequest.ProtoReflect.Descriptor instead.
+func (*FulfillRequest) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *FulfillRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *FulfillRequest) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *FulfillRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *FulfillRequest) GetMode() FulfillmentMode {
+	if x != nil {
+		return x.Mode
+	}
+	return FulfillmentMode_FULFILLMENT_MODE_UNSPECIFIED
+}
+
+func (x *FulfillRequest) GetMaxOverage() int64 {
+	if x != nil && x.MaxOverage != nil {
+		return *x.MaxOverage
+	}
+	return 0
+}
+
+func (x *FulfillRequest) GetMaxOveragePct() float64 {
+	if x != nil && x.MaxOveragePct != nil {
+		return *x.MaxOveragePct
+	}
+	return 0
+}
+
+func (x *FulfillRequest) GetMaxPacks() int64 {
+	if x != nil && x.MaxPacks != nil {
+		return *x.MaxPacks
+	}
+	return 0
+}
+
+func (x *FulfillRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type FulfillResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	ProductId         string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	RequestedQuantity int64                  `protobuf:"varint,2,opt,name=requested_quantity,json=requestedQuantity,proto3" json:"requested_quantity,omitempty"`
+	TotalItems        int64                  `protobuf:"varint,3,opt,name=total_items,json=totalItems,proto3" json:"total_items,omitempty"`
+	Overage           int64                  `protobuf:"varint,4,opt,name=overage,proto3" json:"overage,omitempty"`
+	Shortfall         int64                  `protobuf:"varint,5,opt,name=shortfall,proto3" json:"shortfall,omitempty"`
+	PackCount         int64                  `protobuf:"varint,6,opt,name=pack_count,json=packCount,proto3" json:"pack_count,omitempty"`
+	// Largest size first.
+	Packs         []*FulfilledPack `protobuf:"bytes,7,rep,name=packs,proto3" json:"packs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FulfillResponse) Reset() {
+	*x = FulfillResponse{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FulfillResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FulfillResponse) ProtoMessage() {}
+
+func (x *FulfillResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FulfillResponse.ProtoReflect.Descriptor instead.
+func (*FulfillResponse) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *FulfillResponse) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *FulfillResponse) GetRequestedQuantity() int64 {
+	if x != nil {
+		return x.RequestedQuantity
+	}
+	return 0
+}
+
+func (x *FulfillResponse) GetTotalItems() int64 {
+	if x != nil {
+		return x.TotalItems
+	}
+	return 0
+}
+
+func (x *FulfillResponse) GetOverage() int64 {
+	if x != nil {
+		return x.Overage
+	}
+	return 0
+}
+
+func (x *FulfillResponse) GetShortfall() int64 {
+	if x != nil {
+		return x.Shortfall
+	}
+	return 0
+}
+
+func (x *FulfillResponse) GetPackCount() int64 {
+	if x != nil {
+		return x.PackCount
+	}
+	return 0
+}
+
+func (x *FulfillResponse) GetPacks() []*FulfilledPack {
+	if x != nil {
+		return x.Packs
+	}
+	return nil
+}
+
+type FulfilledPack struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PackId        string                 `protobuf:"bytes,1,opt,name=pack_id,json=packId,proto3" json:"pack_id,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Count         int64                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	Subtotal      int64                  `protobuf:"varint,4,opt,name=subtotal,proto3" json:"subtotal,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FulfilledPack) Reset() {
+	*x = FulfilledPack{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FulfilledPack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FulfilledPack) ProtoMessage() {}
+
+func (x *FulfilledPack) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FulfilledPack.ProtoReflect.Descriptor instead.
+func (*FulfilledPack) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *FulfilledPack) GetPackId() string {
+	if x != nil {
+		return x.PackId
+	}
+	return ""
+}
+
+func (x *FulfilledPack) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FulfilledPack) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *FulfilledPack) GetSubtotal() int64 {
+	if x != nil {
+		return x.Subtotal
+	}
+	return 0
+}
+
+// Infeasible explains why no pack combination satisfies an order's constraints.
+type Infeasible struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Constraints   []string               `protobuf:"bytes,1,rep,name=constraints,proto3" json:"constraints,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	MinOverage    int64                  `protobuf:"varint,3,opt,name=min_overage,json=minOverage,proto3" json:"min_overage,omitempty"`
+	MinPacks      int64                  `protobuf:"varint,4,opt,name=min_packs,json=minPacks,proto3" json:"min_packs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Infeasible) Reset() {
+	*x = Infeasible{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Infeasible) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Infeasible) ProtoMessage() {}
+
+func (x *Infeasible) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Infeasible.ProtoReflect.Descriptor instead.
+func (*Infeasible) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Infeasible) GetConstraints() []string {
+	if x != nil {
+		return x.Constraints
+	}
+	return nil
+}
+
+func (x *Infeasible) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Infeasible) GetMinOverage() int64 {
+	if x != nil {
+		return x.MinOverage
+	}
+	return 0
+}
+
+func (x *Infeasible) GetMinPacks() int64 {
+	if x != nil {
+		return x.MinPacks
+	}
+	return 0
+}
+
+type BatchFulfillResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	RequestId string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// Types that are valid to be assigned to Outcome:
+	//
+	//	*BatchFulfillResponse_Result
+	//	*BatchFulfillResponse_Error
+	Outcome       isBatchFulfillResponse_Outcome `protobuf_oneof:"outcome"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchFulfillResponse) Reset() {
+	*x = BatchFulfillResponse{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchFulfillResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchFulfillResponse) ProtoMessage() {}
+
+func (x *BatchFulfillResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchFulfillResponse.ProtoReflect.Descriptor instead.
+func (*BatchFulfillResponse) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *BatchFulfillResponse) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *BatchFulfillResponse) GetOutcome() isBatchFulfillResponse_Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return nil
+}
+
+func (x *BatchFulfillResponse) GetResult() *FulfillResponse {
+	if x != nil {
+		if x, ok := x.Outcome.(*BatchFulfillResponse_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+func (x *BatchFulfillResponse) GetError() *BatchFulfillError {
+	if x != nil {
+		if x, ok := x.Outcome.(*BatchFulfillResponse_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isBatchFulfillResponse_Outcome interface {
+	isBatchFulfillResponse_Outcome()
+}
+
+type BatchFulfillResponse_Result struct {
+	Result *FulfillResponse `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+type BatchFulfillResponse_Error struct {
+	Error *BatchFulfillError `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*BatchFulfillResponse_Result) isBatchFulfillResponse_Outcome() {}
+
+func (*BatchFulfillResponse_Error) isBatchFulfillResponse_Outcome() {}
+
+type BatchFulfillError struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// google.rpc.Code of the failure, as Fulfill would have returned it.
+	Code    int32  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Set when code is FAILED_PRECONDITION because of the order's constraints.
+	Infeasible    *Infeasible `protobuf:"bytes,3,opt,name=infeasible,proto3" json:"infeasible,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchFulfillError) Reset() {
+	*x = BatchFulfillError{}
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchFulfillError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchFulfillError) ProtoMessage() {}
+
+func (x *BatchFulfillError) ProtoReflect() protoreflect.Message {
+	mi := &file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchFulfillError.ProtoReflect.Descriptor instead.
+func (*BatchFulfillError) Descriptor() ([]byte, []int) {
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *BatchFulfillError) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *BatchFulfillError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *BatchFulfillError) GetInfeasible() *Infeasible {
+	if x != nil {
+		return x.Infeasible
+	}
+	return nil
+}
+
+var File_orderfulfillment_v1_order_fulfillment_proto protoreflect.FileDescriptor
+
+const file_orderfulfillment_v1_order_fulfillment_proto_rawDesc = "" +
+	"\n" +
+	"+orderfulfillment/v1/order_fulfillment.proto\x12\x13orderfulfillment.v1\"Y\n" +
+	"\aProduct\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x10\n" +
+	"\x03sku\x18\x03 \x01(\tR\x03sku\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x03R\aversion\"<\n" +
+	"\x14CreateProductRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
+	"\x03sku\x18\x02 \x01(\tR\x03sku\"#\n" +
+	"\x11GetProductRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x15\n" +
+	"\x13ListProductsRequest\"P\n" +
+	"\x14ListProductsResponse\x128\n" +
+	"\bproducts\x18\x01 \x03(\v2\x1c.orderfulfillment.v1.ProductR\bproducts\"w\n" +
+	"\x14UpdateProductRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x10\n" +
+	"\x03sku\x18\x03 \x01(\tR\x03sku\x12)\n" +
+	"\x10expected_version\x18\x04 \x01(\x03R\x0fexpectedVersion\"&\n" +
+	"\x14DeleteProductRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x17\n" +
+	"\x15DeleteProductResponse\"*\n" +
+	"\x04Pack\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\"s\n" +
+	"\aPackSet\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x03R\aversion\x12/\n" +
+	"\x05packs\x18\x03 \x03(\v2\x19.orderfulfillment.v1.PackR\x05packs\"0\n" +
+	"\x0fGetPacksRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\"u\n" +
+	"\x13ReplacePacksRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x14\n" +
+	"\x05sizes\x18\x02 \x03(\x03R\x05sizes\x12)\n" +
+	"\x10expected_version\x18\x03 \x01(\x03R\x0fexpectedVersion\"\xec\x02\n" +
+	"\x0eFulfillRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x03R\bquantity\x12\x1f\n" +
+	"\vcustomer_id\x18\x03 \x01(\tR\n" +
+	"customerId\x128\n" +
+	"\x04mode\x18\x04 \x01(\x0e2$.orderfulfillment.v1.FulfillmentModeR\x04mode\x12$\n" +
+	"\vmax_overage\x18\x05 \x01(\x03H\x00R\n" +
+	"maxOverage\x88\x01\x01\x12+\n" +
+	"\x0fmax_overage_pct\x18\x06 \x01(\x01H\x01R\rmaxOveragePct\x88\x01\x01\x12 \n" +
+	"\tmax_packs\x18\a \x01(\x03H\x02R\bmaxPacks\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\b \x01(\tR\trequestIdB\x0e\n" +
+	"\f_max_overageB\x12\n" +
+	"\x10_max_overage_pctB\f\n" +
+	"\n" +
+	"_max_packs\"\x91\x02\n" +
+	"\x0fFulfillResponse\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12-\n" +
+	"\x12requested_quantity\x18\x02 \x01(\x03R\x11requestedQuantity\x12\x1f\n" +
+	"\vtotal_items\x18\x03 \x01(\x03R\n" +
+	"totalItems\x12\x18\n" +
+	"\aoverage\x18\x04 \x01(\x03R\aoverage\x12\x1c\n" +
+	"\tshortfall\x18\x05 \x01(\x03R\tshortfall\x12\x1d\n" +
+	"\n" +
+	"pack_count\x18\x06 \x01(\x03R\tpackCount\x128\n" +
+	"\x05packs\x18\a \x03(\v2\".orderfulfillment.v1.FulfilledPackR\x05packs\"n\n" +
+	"\rFulfilledPack\x12\x17\n" +
+	"\apack_id\x18\x01 \x01(\tR\x06packId\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x03R\x05count\x12\x1a\n" +
+	"\bsubtotal\x18\x04 \x01(\x03R\bsubtotal\"\x84\x01\n" +
+	"\n" +
+	"Infeasible\x12 \n" +
+	"\vconstraints\x18\x01 \x03(\tR\vconstraints\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x1f\n" +
+	"\vmin_overage\x18\x03 \x01(\x03R\n" +
+	"minOverage\x12\x1b\n" +
+	"\tmin_packs\x18\x04 \x01(\x03R\bminPacks\"\xc0\x01\n" +
+	"\x14BatchFulfillResponse\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12>\n" +
+	"\x06result\x18\x02 \x01(\v2$.orderfulfillment.v1.FulfillResponseH\x00R\x06result\x12>\n" +
+	"\x05error\x18\x03 \x01(\v2&.orderfulfillment.v1.BatchFulfillErrorH\x00R\x05errorB\t\n" +
+	"\aoutcome\"\x82\x01\n" +
+	"\x11BatchFulfillError\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12?\n" +
+	"\n" +
+	"infeasible\x18\x03 \x01(\v2\x1f.orderfulfillment.v1.InfeasibleR\n" +
+	"infeasible*\x8b\x01\n" +
+	"\x0fFulfillmentMode\x12 \n" +
+	"\x1cFULFILLMENT_MODE_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15FULFILLMENT_MODE_OVER\x10\x01\x12\x1a\n" +
+	"\x16FULFILLMENT_MODE_UNDER\x10\x02\x12\x1f\n" +
+	"\x1bFULFILLMENT_MODE_EXACT_ONLY\x10\x032\xd0\x06\n" +
+	"\x17OrderFulfillmentService\x12X\n" +
+	"\rCreateProduct\x12).orderfulfillment.v1.CreateProductRequest\x1a\x1c.orderfulfillment.v1.Product\x12R\n" +
+	"\n" +
+	"GetProduct\x12&.orderfulfillment.v1.GetProductRequest\x1a\x1c.orderfulfillment.v1.Product\x12c\n" +
+	"\fListProducts\x12(.orderfulfillment.v1.ListProductsRequest\x1a).orderfulfillment.v1.ListProductsResponse\x12X\n" +
+	"\rUpdateProduct\x12).orderfulfillment.v1.UpdateProductRequest\x1a\x1c.orderfulfillment.v1.Product\x12f\n" +
+	"\rDeleteProduct\x12).orderfulfillment.v1.DeleteProductRequest\x1a*.orderfulfillment.v1.DeleteProductResponse\x12N\n" +
+	"\bGetPacks\x12$.orderfulfillment.v1.GetPacksRequest\x1a\x1c.orderfulfillment.v1.PackSet\x12V\n" +
+	"\fReplacePacks\x12(.orderfulfillment.v1.ReplacePacksRequest\x1a\x1c.orderfulfillment.v1.PackSet\x12T\n" +
+	"\aFulfill\x12#.orderfulfillment.v1.FulfillRequest\x1a$.orderfulfillment.v1.FulfillResponse\x12b\n" +
+	"\fBatchFulfill\x12#.orderfulfillment.v1.FulfillRequest\x1a).orderfulfillment.v1.BatchFulfillResponse(\x010\x01BXZVgithub.com/rlpaul93/order-fulfillment/api/proto/orderfulfillment/v1;orderfulfillmentv1b\x06proto3"
+
+var (
+	file_orderfulfillment_v1_order_fulfillment_proto_rawDescOnce sync.Once
+	file_orderfulfillment_v1_order_fulfillment_proto_rawDescData []byte
+)
+
+func file_orderfulfillment_v1_order_fulfillment_proto_rawDescGZIP() []byte {
+	file_orderfulfillment_v1_order_fulfillment_proto_rawDescOnce.Do(func() {
+		file_orderfulfillment_v1_order_fulfillment_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_orderfulfillment_v1_order_fulfillment_proto_rawDesc), len(file_orderfulfillment_v1_order_fulfillment_proto_rawDesc)))
+	})
+	return file_orderfulfillment_v1_order_fulfillment_proto_rawDescData
+}
+
+var file_orderfulfillment_v1_order_fulfillment_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_orderfulfillment_v1_order_fulfillment_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_orderfulfillment_v1_order_fulfillment_proto_goTypes = []any{
+	(FulfillmentMode)(0),          // 0: orderfulfillment.v1.FulfillmentMode
+	(*Product)(nil),               // 1: orderfulfillment.v1.Product
+	(*CreateProductRequest)(nil),  // 2: orderfulfillment.v1.CreateProductRequest
+	(*GetProductRequest)(nil),     // 3: orderfulfillment.v1.GetProductRequest
+	(*ListProductsRequest)(nil),   // 4: orderfulfillment.v1.ListProductsRequest
+	(*ListProductsResponse)(nil),  // 5: orderfulfillment.v1.ListProductsResponse
+	(*UpdateProductRequest)(nil),  // 6: orderfulfillment.v1.UpdateProductRequest
+	(*DeleteProductRequest)(nil),  // 7: orderfulfillment.v1.DeleteProductRequest
+	(*DeleteProductResponse)(nil), // 8: orderfulfillment.v1.DeleteProductResponse
+	(*Pack)(nil),                  // 9: orderfulfillment.v1.Pack
+	(*PackSet)(nil),               // 10: orderfulfillment.v1.PackSet
+	(*GetPacksRequest)(nil),       // 11: orderfulfillment.v1.GetPacksRequest
+	(*ReplacePacksRequest)(nil),   // 12: orderfulfillment.v1.ReplacePacksRequest
+	(*FulfillRequest)(nil),        // 13: orderfulfillment.v1.FulfillRequest
+	(*FulfillResponse)(nil),       // 14: orderfulfillment.v1.FulfillResponse
+	(*FulfilledPack)(nil),         // 15: orderfulfillment.v1.FulfilledPack
+	(*Infeasible)(nil),            // 16: orderfulfillment.v1.Infeasible
+	(*BatchFulfillResponse)(nil),  // 17: orderfulfillment.v1.BatchFulfillResponse
+	(*BatchFulfillError)(nil),     // 18: orderfulfillment.v1.BatchFulfillError
+}
+var file_orderfulfillment_v1_order_fulfillment_proto_depIdxs = []int32{
+	1,  // 0: orderfulfillment.v1.ListProductsResponse.products:type_name -> orderfulfillment.v1.Product
+	9,  // 1: orderfulfillment.v1.PackSet.packs:type_name -> orderfulfillment.v1.Pack
+	0,  // 2: orderfulfillment.v1.FulfillRequest.mode:type_name -> orderfulfillment.v1.FulfillmentMode
+	15, // 3: orderfulfillment.v1.FulfillResponse.packs:type_name -> orderfulfillment.v1.FulfilledPack
+	14, // 4: orderfulfillment.v1.BatchFulfillResponse.result:type_name -> orderfulfillment.v1.FulfillResponse
+	18, // 5: orderfulfillment.v1.BatchFulfillResponse.error:type_name -> orderfulfillment.v1.BatchFulfillError
+	16, // 6: orderfulfillment.v1.BatchFulfillError.infeasible:type_name -> orderfulfillment.v1.Infeasible
+	2,  // 7: orderfulfillment.v1.OrderFulfillmentService.CreateProduct:input_type -> orderfulfillment.v1.CreateProductRequest
+	3,  // 8: orderfulfillment.v1.OrderFulfillmentService.GetProduct:input_type -> orderfulfillment.v1.GetProductRequest
+	4,  // 9: orderfulfillment.v1.OrderFulfillmentService.ListProducts:input_type -> orderfulfillment.v1.ListProductsRequest
+	6,  // 10: orderfulfillment.v1.OrderFulfillmentService.UpdateProduct:input_type -> orderfulfillment.v1.UpdateProductRequest
+	7,  // 11: orderfulfillment.v1.OrderFulfillmentService.DeleteProduct:input_type -> orderfulfillment.v1.DeleteProductRequest
+	11, // 12: orderfulfillment.v1.OrderFulfillmentService.GetPacks:input_type -> orderfulfillment.v1.GetPacksRequest
+	12, // 13: orderfulfillment.v1.OrderFulfillmentService.ReplacePacks:input_type -> orderfulfillment.v1.ReplacePacksRequest
+	13, // 14: orderfulfillment.v1.OrderFulfillmentService.Fulfill:input_type -> orderfulfillment.v1.FulfillRequest
+	13, // 15: orderfulfillment.v1.OrderFulfillmentService.BatchFulfill:input_type -> orderfulfillment.v1.FulfillRequest
+	1,  // 16: orderfulfillment.v1.OrderFulfillmentService.CreateProduct:output_type -> orderfulfillment.v1.Product
+	1,  // 17: orderfulfillment.v1.OrderFulfillmentService.GetProduct:output_type -> orderfulfillment.v1.Product
+	5,  // 18: orderfulfillment.v1.OrderFulfillmentService.ListProducts:output_type -> orderfulfillment.v1.ListProductsResponse
+	1,  // 19: orderfulfillment.v1.OrderFulfillmentService.UpdateProduct:output_type -> orderfulfillment.v1.Product
+	8,  // 20: orderfulfillment.v1.OrderFulfillmentService.DeleteProduct:output_type -> orderfulfillment.v1.DeleteProductResponse
+	10, // 21: orderfulfillment.v1.OrderFulfillmentService.GetPacks:output_type -> orderfulfillment.v1.PackSet
+	10, // 22: orderfulfillment.v1.OrderFulfillmentService.ReplacePacks:output_type -> orderfulfillment.v1.PackSet
+	14, // 23: orderfulfillment.v1.OrderFulfillmentService.Fulfill:output_type -> orderfulfillment.v1.FulfillResponse
+	17, // 24: orderfulfillment.v1.OrderFulfillmentService.BatchFulfill:output_type -> orderfulfillment.v1.BatchFulfillResponse
+	16, // [16:25] is the sub-list for method output_type
+	7,  // [7:16] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_orderfulfillment_v1_order_fulfillment_proto_init() }
+func file_orderfulfillment_v1_order_fulfillment_proto_init() {
+	if File_orderfulfillment_v1_order_fulfillment_proto != nil {
+		return
+	}
+	file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[12].OneofWrappers = []any{}
+	file_orderfulfillment_v1_order_fulfillment_proto_msgTypes[16].OneofWrappers = []any{
+		(*BatchFulfillResponse_Result)(nil),
+		(*BatchFulfillResponse_Error)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_orderfulfillment_v1_order_fulfillment_proto_rawDesc), len(file_orderfulfillment_v1_order_fulfillment_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   18,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_orderfulfillment_v1_order_fulfillment_proto_goTypes,
+		DependencyIndexes: file_orderfulfillment_v1_order_fulfillment_proto_depIdxs,
+		EnumInfos:         file_orderfulfillment_v1_order_fulfillment_proto_enumTypes,
+		MessageInfos:      file_orderfulfillment_v1_order_fulfillment_proto_msgTypes,
+	}.Build()
+	File_orderfulfillment_v1_order_fulfillment_proto = out.File
+	file_orderfulfillment_v1_order_fulfillment_proto_goTypes = nil
+	file_orderfulfillment_v1_order_fulfillment_proto_depIdxs = nil
+}
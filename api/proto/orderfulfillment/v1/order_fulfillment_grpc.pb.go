@@ -0,0 +1,439 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: orderfulfillment/v1/order_fulfillment.proto
+
+package orderfulfillmentv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	OrderFulfillmentService_CreateProduct_FullMethodName = "/orderfulfillment.v1.OrderFulfillmentService/CreateProduct"
+	OrderFulfillmentService_GetProduct_FullMethodName    = "/orderfulfillment.v1.OrderFulfillmentService/GetProduct"
+	OrderFulfillmentService_ListProducts_FullMethodName  = "/orderfulfillment.v1.OrderFulfillmentService/ListProducts"
+	OrderFulfillmentService_UpdateProduct_FullMethodName = "/orderfulfillment.v1.OrderFulfillmentService/UpdateProduct"
+	OrderFulfillmentService_DeleteProduct_FullMethodName = "/orderfulfillment.v1.OrderFulfillmentService/DeleteProduct"
+	OrderFulfillmentService_GetPacks_FullMethodName      = "/orderfulfillment.v1.OrderFulfillmentService/GetPacks"
+	OrderFulfillmentService_ReplacePacks_FullMethodName  = "/orderfulfillment.v1.OrderFulfillmentService/ReplacePacks"
+	OrderFulfillmentService_Fulfill_FullMethodName       = "/orderfulfillment.v1.OrderFulfillmentService/Fulfill"
+	OrderFulfillmentService_BatchFulfill_FullMethodName  = "/orderfulfillment.v1.OrderFulfillmentService/BatchFulfill"
+)
+
+// OrderFulfillmentServiceClient is the client API for OrderFulfillmentService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// OrderFulfillmentService manages products and their packs and calculates pack fulfillments, like the /v1
+// REST API. IDs are UUID strings. Errors use the standard status codes: INVALID_ARGUMENT for malformed
+// requests, NOT_FOUND, ALREADY_EXISTS for a duplicate SKU, ABORTED when an expected version no longer
+// matches, FAILED_PRECONDITION when no plan satisfies an order's constraints (with an Infeasible detail)
+// and DEADLINE_EXCEEDED when the solver runs out of its time budget.
+type OrderFulfillmentServiceClient interface {
+	CreateProduct(ctx context.Context, in *CreateProductRequest, opts ...grpc.CallOption) (*Product, error)
+	GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*Product, error)
+	ListProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error)
+	UpdateProduct(ctx context.Context, in *UpdateProductRequest, opts ...grpc.CallOption) (*Product, error)
+	DeleteProduct(ctx context.Context, in *DeleteProductRequest, opts ...grpc.CallOption) (*DeleteProductResponse, error)
+	GetPacks(ctx context.Context, in *GetPacksRequest, opts ...grpc.CallOption) (*PackSet, error)
+	ReplacePacks(ctx context.Context, in *ReplacePacksRequest, opts ...grpc.CallOption) (*PackSet, error)
+	// Fulfill calculates the optimal combination of packs for an order, like GET /v1/fulfill.
+	Fulfill(ctx context.Context, in *FulfillRequest, opts ...grpc.CallOption) (*FulfillResponse, error)
+	// BatchFulfill fulfills a stream of orders, answering each one in the order received. A failed order
+	// is answered with its error and does not end the stream.
+	BatchFulfill(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[FulfillRequest, BatchFulfillResponse], error)
+}
+
+type orderFulfillmentServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOrderFulfillmentServiceClient(cc grpc.ClientConnInterface) OrderFulfillmentServiceClient {
+	return &orderFulfillmentServiceClient{cc}
+}
+
+func (c *orderFulfillmentServiceClient) CreateProduct(ctx context.Context, in *CreateProductRequest, opts ...grpc.CallOption) (*Product, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Product)
+	err := c.cc.Invoke(ctx, OrderFulfillmentService_CreateProduct_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderFulfillmentServiceClient) GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*Product, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Product)
+	err := c.cc.Invoke(ctx, OrderFulfillmentService_GetProduct_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderFulfillmentServiceClient) ListProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListProductsResponse)
+	err := c.cc.Invoke(ctx, OrderFulfillmentService_ListProducts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderFulfillmentServiceClient) UpdateProduct(ctx context.Context, in *UpdateProductRequest, opts ...grpc.CallOption) (*Product, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Product)
+	err := c.cc.Invoke(ctx, OrderFulfillmentService_UpdateProduct_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderFulfillmentServiceClient) DeleteProduct(ctx context.Context, in *DeleteProductRequest, opts ...grpc.CallOption) (*DeleteProductResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteProductResponse)
+	err := c.cc.Invoke(ctx, OrderFulfillmentService_DeleteProduct_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderFulfillmentServiceClient) GetPacks(ctx context.Context, in *GetPacksRequest, opts ...grpc.CallOption) (*PackSet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PackSet)
+	err := c.cc.Invoke(ctx, OrderFulfillmentService_GetPacks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderFulfillmentServiceClient) ReplacePacks(ctx context.Context, in *ReplacePacksRequest, opts ...grpc.CallOption) (*PackSet, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PackSet)
+	err := c.cc.Invoke(ctx, OrderFulfillmentService_ReplacePacks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderFulfillmentServiceClient) Fulfill(ctx context.Context, in *FulfillRequest, opts ...grpc.CallOption) (*FulfillResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FulfillResponse)
+	err := c.cc.Invoke(ctx, OrderFulfillmentService_Fulfill_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderFulfillmentServiceClient) BatchFulfill(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[FulfillRequest, BatchFulfillResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OrderFulfillmentService_ServiceDesc.Streams[0], OrderFulfillmentService_BatchFulfill_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[FulfillRequest, BatchFulfillResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderFulfillmentService_BatchFulfillClient = grpc.BidiStreamingClient[FulfillRequest, BatchFulfillResponse]
+
+// OrderFulfillmentServiceServer is the server API for OrderFulfillmentService service.
+// All implementations must embed UnimplementedOrderFulfillmentServiceServer
+// for forward compatibility.
+//
+// OrderFulfillmentService manages products and their packs and calculates pack fulfillments, like the /v1
+// REST API. IDs are UUID strings. Errors use the standard status codes: INVALID_ARGUMENT for malformed
+// requests, NOT_FOUND, ALREADY_EXISTS for a duplicate SKU, ABORTED when an expected version no longer
+// matches, FAILED_PRECONDITION when no plan satisfies an order's constraints (with an Infeasible detail)
+// and DEADLINE_EXCEEDED when the solver runs out of its time budget.
+type OrderFulfillmentServiceServer interface {
+	CreateProduct(context.Context, *CreateProductRequest) (*Product, error)
+	GetProduct(context.Context, *GetProductRequest) (*Product, error)
+	ListProducts(context.Context, *ListProductsRequest) (*ListProductsResponse, error)
+	UpdateProduct(context.Context, *UpdateProductRequest) (*Product, error)
+	DeleteProduct(context.Context, *DeleteProductRequest) (*DeleteProductResponse, error)
+	GetPacks(context.Context, *GetPacksRequest) (*PackSet, error)
+	ReplacePacks(context.Context, *ReplacePacksRequest) (*PackSet, error)
+	// Fulfill calculates the optimal combination of packs for an order, like GET /v1/fulfill.
+	Fulfill(context.Context, *FulfillRequest) (*FulfillResponse, error)
+	// BatchFulfill fulfills a stream of orders, answering each one in the order received. A failed order
+	// is answered with its error and does not end the stream.
+	BatchFulfill(grpc.BidiStreamingServer[FulfillRequest, BatchFulfillResponse]) error
+	mustEmbedUnimplementedOrderFulfillmentServiceServer()
+}
+
+// UnimplementedOrderFulfillmentServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedOrderFulfillmentServiceServer struct{}
+
+func (UnimplementedOrderFulfillmentServiceServer) CreateProduct(context.Context, *CreateProductRequest) (*Product, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateProduct not implemented")
+}
+func (UnimplementedOrderFulfillmentServiceServer) GetProduct(context.Context, *GetProductRequest) (*Product, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProduct not implemented")
+}
+func (UnimplementedOrderFulfillmentServiceServer) ListProducts(context.Context, *ListProductsRequest) (*ListProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProducts not implemented")
+}
+func (UnimplementedOrderFulfillmentServiceServer) UpdateProduct(context.Context, *UpdateProductRequest) (*Product, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateProduct not implemented")
+}
+func (UnimplementedOrderFulfillmentServiceServer) DeleteProduct(context.Context, *DeleteProductRequest) (*DeleteProductResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteProduct not implemented")
+}
+func (UnimplementedOrderFulfillmentServiceServer) GetPacks(context.Context, *GetPacksRequest) (*PackSet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPacks not implemented")
+}
+func (UnimplementedOrderFulfillmentServiceServer) ReplacePacks(context.Context, *ReplacePacksRequest) (*PackSet, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplacePacks not implemented")
+}
+func (UnimplementedOrderFulfillmentServiceServer) Fulfill(context.Context, *FulfillRequest) (*FulfillResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Fulfill not implemented")
+}
+func (UnimplementedOrderFulfillmentServiceServer) BatchFulfill(grpc.BidiStreamingServer[FulfillRequest, BatchFulfillResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method BatchFulfill not implemented")
+}
+func (UnimplementedOrderFulfillmentServiceServer) mustEmbedUnimplementedOrderFulfillmentServiceServer() {
+}
+func (UnimplementedOrderFulfillmentServiceServer) testEmbeddedByValue() {}
+
+// UnsafeOrderFulfillmentServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OrderFulfillmentServiceServer will
+// result in compilation errors.
+type UnsafeOrderFulfillmentServiceServer interface {
+	mustEmbedUnimplementedOrderFulfillmentServiceServer()
+}
+
+func RegisterOrderFulfillmentServiceServer(s grpc.ServiceRegistrar, srv OrderFulfillmentServiceServer) {
+	// If the following call pancis, it indicates UnimplementedOrderFulfillmentServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&OrderFulfillmentService_ServiceDesc, srv)
+}
+
+func _OrderFulfillmentService_CreateProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderFulfillmentServiceServer).CreateProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderFulfillmentService_CreateProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderFulfillmentServiceServer).CreateProduct(ctx, req.(*CreateProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderFulfillmentService_GetProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderFulfillmentServiceServer).GetProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderFulfillmentService_GetProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderFulfillmentServiceServer).GetProduct(ctx, req.(*GetProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderFulfillmentService_ListProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderFulfillmentServiceServer).ListProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderFulfillmentService_ListProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderFulfillmentServiceServer).ListProducts(ctx, req.(*ListProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderFulfillmentService_UpdateProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderFulfillmentServiceServer).UpdateProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderFulfillmentService_UpdateProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderFulfillmentServiceServer).UpdateProduct(ctx, req.(*UpdateProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderFulfillmentService_DeleteProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderFulfillmentServiceServer).DeleteProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderFulfillmentService_DeleteProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderFulfillmentServiceServer).DeleteProduct(ctx, req.(*DeleteProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderFulfillmentService_GetPacks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPacksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderFulfillmentServiceServer).GetPacks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderFulfillmentService_GetPacks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderFulfillmentServiceServer).GetPacks(ctx, req.(*GetPacksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderFulfillmentService_ReplacePacks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplacePacksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderFulfillmentServiceServer).ReplacePacks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderFulfillmentService_ReplacePacks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderFulfillmentServiceServer).ReplacePacks(ctx, req.(*ReplacePacksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderFulfillmentService_Fulfill_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FulfillRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderFulfillmentServiceServer).Fulfill(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderFulfillmentService_Fulfill_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderFulfillmentServiceServer).Fulfill(ctx, req.(*FulfillRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderFulfillmentService_BatchFulfill_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(OrderFulfillmentServiceServer).BatchFulfill(&grpc.GenericServerStream[FulfillRequest, BatchFulfillResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderFulfillmentService_BatchFulfillServer = grpc.BidiStreamingServer[FulfillRequest, BatchFulfillResponse]
+
+// OrderFulfillmentService_ServiceDesc is the grpc.ServiceDesc for OrderFulfillmentService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var OrderFulfillmentService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "orderfulfillment.v1.OrderFulfillmentService",
+	HandlerType: (*OrderFulfillmentServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateProduct",
+			Handler:    _OrderFulfillmentService_CreateProduct_Handler,
+		},
+		{
+			MethodName: "GetProduct",
+			Handler:    _OrderFulfillmentService_GetProduct_Handler,
+		},
+		{
+			MethodName: "ListProducts",
+			Handler:    _OrderFulfillmentService_ListProducts_Handler,
+		},
+		{
+			MethodName: "UpdateProduct",
+			Handler:    _OrderFulfillmentService_UpdateProduct_Handler,
+		},
+		{
+			MethodName: "DeleteProduct",
+			Handler:    _OrderFulfillmentService_DeleteProduct_Handler,
+		},
+		{
+			MethodName: "GetPacks",
+			Handler:    _OrderFulfillmentService_GetPacks_Handler,
+		},
+		{
+			MethodName: "ReplacePacks",
+			Handler:    _OrderFulfillmentService_ReplacePacks_Handler,
+		},
+		{
+			MethodName: "Fulfill",
+			Handler:    _OrderFulfillmentService_Fulfill_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BatchFulfill",
+			Handler:       _OrderFulfillmentService_BatchFulfill_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "orderfulfillment/v1/order_fulfillment.proto",
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/in/cli"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+	"github.com/rlpaul93/order-fulfillment/internal/infrastructure/db"
+)
+
+func (a *app) migrate(args []string) error {
+	sub, rest, err := subcommand("migrate", args)
+	if err != nil {
+		return err
+	}
+	switch sub {
+	case "up":
+		if err := db.MigrateUp(a.conn); err != nil {
+			return err
+		}
+	case "down":
+		fs := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		steps := fs.Int("steps", 1, "number of migrations to roll back")
+		all := fs.Bool("all", false, "roll back every migration, dropping all tables and data")
+		yes := fs.Bool("yes", false, "with --all, skip the confirmation prompt")
+		if err := fs.Parse(rest); err != nil {
+			return err
+		}
+		if *all {
+			if !*yes && !a.confirm("Roll back every migration, dropping all tables and data? Type yes to continue: ") {
+				return fmt.Errorf("migrate down: aborted")
+			}
+			err = db.MigrateDownAll(a.conn)
+		} else {
+			err = db.MigrateDown(a.conn, *steps)
+		}
+		if err != nil {
+			return err
+		}
+	case "version":
+	default:
+		return fmt.Errorf("migrate: unknown subcommand %q", sub)
+	}
+	version, dirty, err := db.MigrationVersion(a.conn)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "schema version %d (dirty: %t)\n", version, dirty)
+	return nil
+}
+
+// confirm asks prompt on stdout and reports whether the answer read from stdin is yes.
+func (a *app) confirm(prompt string) bool {
+	fmt.Fprint(a.stdout, prompt)
+	answer, _ := bufio.NewReader(a.stdin).ReadString('\n')
+	return strings.TrimSpace(answer) == "yes"
+}
+
+func (a *app) products(ctx context.Context, args []string) error {
+	sub, rest, err := subcommand("products", args)
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("products "+sub, flag.ContinueOnError)
+	name := fs.String("name", "", "product name")
+	sku := fs.String("sku", "", "product SKU")
+	id := fs.String("id", "", "product UUID")
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+	switch sub {
+	case "list":
+		products, err := a.prodSvc.List(ctx)
+		if err != nil {
+			return err
+		}
+		slices.SortFunc(products, func(x, y *model.Product) int { return strings.Compare(x.Name, y.Name) })
+		tw := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tSKU\tNAME")
+		for _, p := range products {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", p.ID, p.SKU, p.Name)
+		}
+		return tw.Flush()
+	case "create":
+		if *name == "" {
+			return fmt.Errorf("products create: --name is required")
+		}
+		p := &model.Product{Name: *name, SKU: *sku}
+		if err := a.prodSvc.Create(ctx, p); err != nil {
+			return err
+		}
+		fmt.Fprintln(a.stdout, p.ID)
+		return nil
+	case "delete":
+		productID, err := uuid.Parse(*id)
+		if err != nil {
+			return fmt.Errorf("products delete: invalid --id: %w", err)
+		}
+		return a.prodSvc.Delete(ctx, productID)
+	default:
+		return fmt.Errorf("products: unknown subcommand %q", sub)
+	}
+}
+
+func (a *app) packs(ctx context.Context, args []string) error {
+	sub, rest, err := subcommand("packs", args)
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("packs "+sub, flag.ContinueOnError)
+	id := fs.String("product-id", "", "product UUID")
+	sizesFlag := fs.String("sizes", "", "comma-separated pack sizes")
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+	productID, err := uuid.Parse(*id)
+	if err != nil {
+		return fmt.Errorf("packs %s: invalid --product-id: %w", sub, err)
+	}
+	switch sub {
+	case "list":
+		packs, err := a.packSvc.ListByProduct(ctx, productID)
+		if err != nil {
+			return err
+		}
+		sizes := make([]int, len(packs))
+		for i, p := range packs {
+			sizes[i] = p.Size
+		}
+		slices.Sort(sizes)
+		fmt.Fprintln(a.stdout, joinSizes(sizes))
+		return nil
+	case "set":
+		sizes, err := cli.ParsePackSizes(*sizesFlag)
+		if err != nil {
+			return err
+		}
+		if len(sizes) == 0 {
+			return fmt.Errorf("packs set: --sizes is required")
+		}
+		if _, err := a.prodSvc.GetByID(ctx, productID); err != nil {
+			return fmt.Errorf("packs set: product %s: %w", productID, err)
+		}
+		_, err = a.packSvc.ReplaceByProduct(ctx, productID, sizes)
+		return err
+	default:
+		return fmt.Errorf("packs: unknown subcommand %q", sub)
+	}
+}
+
+func (a *app) catalogCmd(ctx context.Context, args []string) error {
+	sub, rest, err := subcommand("catalog", args)
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("catalog "+sub, flag.ContinueOnError)
+	output := fs.String("output", "", "file to write the catalog to (stdout by default)")
+	file := fs.String("file", "", "catalog file to import: a JSON array or NDJSON, as exported")
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+	switch sub {
+	case "export":
+		catalog, err := a.catalog.Export(ctx)
+		if err != nil {
+			return err
+		}
+		w := a.stdout
+		if *output != "" {
+			f, err := os.Create(*output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(catalog)
+	case "import":
+		if *file == "" {
+			return fmt.Errorf("catalog import: --file is required")
+		}
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		catalog, err := cli.ReadCatalog(f)
+		if err != nil {
+			return fmt.Errorf("catalog import: decode %s: %w", *file, err)
+		}
+		return a.importCatalog(ctx, catalog)
+	default:
+		return fmt.Errorf("catalog: unknown subcommand %q", sub)
+	}
+}
+
+// seedFile is the declarative seed format, e.g.
+//
+//	products:
+//	  - name: Generic Shoes
+//	    pack_sizes: [250, 500, 1000, 2000, 5000]
+type seedFile struct {
+	Products []struct {
+		SKU       string `yaml:"sku" json:"sku"`
+		Name      string `yaml:"name" json:"name"`
+		PackSizes []int  `yaml:"pack_sizes" json:"pack_sizes"`
+	} `yaml:"products" json:"products"`
+}
+
+func (a *app) seed(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	file := fs.String("file", "", "YAML or JSON seed file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("seed: --file is required")
+	}
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	var seed seedFile
+	if strings.EqualFold(filepath.Ext(*file), ".json") {
+		err = json.Unmarshal(data, &seed)
+	} else {
+		err = yaml.Unmarshal(data, &seed)
+	}
+	if err != nil {
+		return fmt.Errorf("seed: decode %s: %w", *file, err)
+	}
+
+	items := make([]model.CatalogProduct, len(seed.Products))
+	for i, p := range seed.Products {
+		items[i] = model.CatalogProduct{SKU: p.SKU, Name: p.Name, PackSizes: p.PackSizes}
+	}
+	return a.importCatalog(ctx, items)
+}
+
+// importCatalog upserts items and prints the outcome, failing if any item was rejected.
+func (a *app) importCatalog(ctx context.Context, items []model.CatalogProduct) error {
+	rows := make([]service.CatalogRow, len(items))
+	for i, item := range items {
+		rows[i] = service.CatalogRow{Row: i + 1, Product: item}
+	}
+	report, err := a.catalog.Import(ctx, rows, false)
+	if err != nil {
+		return err
+	}
+	for _, row := range report.Rows {
+		if row.Action == service.CatalogActionError {
+			fmt.Fprintf(a.stdout, "item %d (%s): %s\n", row.Row, row.Name, strings.Join(row.Errors, "; "))
+		}
+	}
+	fmt.Fprintf(a.stdout, "created %d, updated %d, unchanged %d, failed %d\n", report.Created, report.Updated, report.Unchanged, report.Failed)
+	if report.Failed > 0 {
+		return fmt.Errorf("%d items were rejected", report.Failed)
+	}
+	return nil
+}
+
+func joinSizes(sizes []int) string {
+	parts := make([]string, len(sizes))
+	for i, size := range sizes {
+		parts[i] = strconv.Itoa(size)
+	}
+	return strings.Join(parts, ",")
+}
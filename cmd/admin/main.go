@@ -0,0 +1,121 @@
+// Command admin manages the PostgreSQL catalog: migrations, products, pack sizes,
+// catalog import/export and declarative seeding.
+//
+// Usage:
+//
+//	admin [--database-url URL] migrate up|down [--steps N | --all [--yes]]|version
+//	admin [--database-url URL] products list|create --name NAME [--sku SKU]|delete --id ID
+//	admin [--database-url URL] packs list --product-id ID|set --product-id ID --sizes 250,500
+//	admin [--database-url URL] catalog export [--output FILE]|import --file FILE
+//	admin [--database-url URL] seed --file seed.yaml
+//
+// The database URL defaults to $DATABASE_URL.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+	"github.com/rlpaul93/order-fulfillment/internal/infrastructure/db"
+)
+
+const usage = `usage: admin [--database-url URL] <command> [arguments]
+
+commands:
+  migrate up                      apply all pending migrations
+  migrate down [--steps N]        roll back N migrations (1 if omitted)
+  migrate down --all [--yes]      roll back every migration, dropping all data, after confirmation
+  migrate version                 print the current schema version
+  products list                   list products
+  products create --name NAME [--sku SKU]
+                                  create a product
+  products delete --id ID         delete a product and its packs
+  packs list --product-id ID      list a product's pack sizes
+  packs set --product-id ID --sizes 250,500
+                                  replace a product's pack sizes
+  catalog export [--output FILE]  write the catalog as JSON (stdout by default)
+  catalog import --file FILE      upsert products and packs from a JSON or NDJSON catalog
+  seed --file FILE                idempotently apply a YAML or JSON seed file
+`
+
+// app holds the services the commands operate on.
+type app struct {
+	conn    *sql.DB
+	prodSvc *service.ProductService
+	packSvc *service.PackService
+	catalog *service.CatalogService
+	stdin   io.Reader
+	stdout  io.Writer
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("admin", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(fs.Output(), usage) }
+	databaseURL := fs.String("database-url", os.Getenv("DATABASE_URL"), "PostgreSQL connection URL")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("missing command")
+	}
+	if *databaseURL == "" {
+		return fmt.Errorf("--database-url or DATABASE_URL is required")
+	}
+
+	conn, err := db.NewConnection(*databaseURL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	prodSvc := &service.ProductService{Repo: &out.ProductRepositoryPg{DB: conn}}
+	packSvc := &service.PackService{Repo: &out.PackRepositoryPg{DB: conn}}
+	a := &app{
+		conn:    conn,
+		prodSvc: prodSvc,
+		packSvc: packSvc,
+		catalog: &service.CatalogService{Products: prodSvc, Packs: packSvc},
+		stdin:   stdin,
+		stdout:  stdout,
+	}
+
+	ctx := context.Background()
+	cmd, rest := fs.Arg(0), fs.Args()[1:]
+	switch cmd {
+	case "migrate":
+		return a.migrate(rest)
+	case "products":
+		return a.products(ctx, rest)
+	case "packs":
+		return a.packs(ctx, rest)
+	case "catalog":
+		return a.catalogCmd(ctx, rest)
+	case "seed":
+		return a.seed(ctx, rest)
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+// subcommand splits args into a subcommand name and its flags.
+func subcommand(cmd string, args []string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("%s: missing subcommand", cmd)
+	}
+	return args[0], args[1:], nil
+}
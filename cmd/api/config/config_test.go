@@ -0,0 +1,105 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "api.yaml", "api_port: \"9000\"\nrate_limit_rps: 5\nrate_limit_burst: 7\nhttp_write_timeout: 2m\n")
+	t.Setenv("RATE_LIMIT_RPS", "6")
+	t.Setenv("FULFILL_CACHE_SIZE", "50")
+
+	cfg, err := Load([]string{"--config", path, "--fulfill-cache-size", "60"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.APIPort != "9000" || cfg.RateLimitBurst != 7 || cfg.WriteTimeout != 2*time.Minute {
+		t.Errorf("file settings not applied: %+v", cfg)
+	}
+	if cfg.RateLimitRPS != 6 {
+		t.Errorf("expected env to override file, got rate_limit_rps %v", cfg.RateLimitRPS)
+	}
+	if cfg.FulfillCacheSize != 60 {
+		t.Errorf("expected flag to override env, got fulfill_cache_size %d", cfg.FulfillCacheSize)
+	}
+	if cfg.SwaggerHost != "localhost:9000" {
+		t.Errorf("expected swagger host to follow the port, got %q", cfg.SwaggerHost)
+	}
+}
+
+func TestLoad_TOMLFile(t *testing.T) {
+	path := writeFile(t, "api.toml", "storage_mode = \"memory\"\nfulfill_table_max_quantity = 1000\nrate_limit_trusted_proxies = 2\n")
+	t.Setenv("CONFIG_FILE", path)
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.FulfillTableMax != 1000 || cfg.RateLimitProxies != 2 {
+		t.Errorf("TOML settings not applied: %+v", cfg)
+	}
+}
+
+func TestLoad_FailsFast(t *testing.T) {
+	tests := map[string]struct {
+		env  map[string]string
+		args []string
+		file string
+		want string
+	}{
+		"unknown storage mode": {env: map[string]string{"STORAGE_MODE": "postgress"}, want: "storage_mode"},
+		"postgres without url": {args: []string{"--storage-mode", "postgres"}, want: "database_url is required"},
+		"malformed env value":  {env: map[string]string{"RATE_LIMIT_BURST": "lots"}, want: "RATE_LIMIT_BURST"},
+		"unknown file setting": {file: "rate_limit: 5\n", want: `unknown setting "rate_limit"`},
+		"negative duration":    {args: []string{"--http-idle-timeout", "-1s"}, want: "http_idle_timeout"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			args := tt.args
+			if tt.file != "" {
+				args = append(args, "--config", writeFile(t, "api.yaml", tt.file))
+			}
+			_, err := Load(args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestPrint_RedactsSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.DatabaseURL = "postgres://user:hunter2@db:5432/orders"
+
+	var buf bytes.Buffer
+	if err := cfg.Print(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("password leaked into printed config:\n%s", out)
+	}
+	if !strings.Contains(out, "database_url: postgres://user:xxxxx@db:5432/orders") {
+		t.Errorf("expected redacted database URL, got:\n%s", out)
+	}
+	if !strings.Contains(out, "http_write_timeout: 1m0s") {
+		t.Errorf("expected durations to be printed as strings, got:\n%s", out)
+	}
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Reload triggers.
+const (
+	TriggerStartup = "startup"
+	TriggerSignal  = "signal"
+	TriggerFile    = "file"
+	TriggerAPI     = "api"
+)
+
+// Reload results.
+const (
+	ReloadApplied   = "applied"
+	ReloadUnchanged = "unchanged"
+	ReloadFailed    = "failed"
+)
+
+// ReloadOutcome describes one attempt to reload the configuration.
+type ReloadOutcome struct {
+	At              time.Time `json:"at"`
+	Trigger         string    `json:"trigger"`
+	Result          string    `json:"result"`
+	Changed         []string  `json:"changed,omitempty"`
+	RestartRequired []string  `json:"restart_required,omitempty"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// Status reports the active configuration and the outcome of the last reload.
+type Status struct {
+	Version    int            `json:"version"`
+	Checksum   string         `json:"checksum"`
+	File       string         `json:"file,omitempty"`
+	AppliedAt  time.Time      `json:"applied_at"`
+	LastReload ReloadOutcome  `json:"last_reload"`
+	Settings   map[string]any `json:"settings"`
+}
+
+// Reloader re-reads the configuration from the same sources Load used and hands the settings that can
+// change at runtime to an apply function. Settings that need a restart keep their running value and are
+// reported instead. Reloads are serialized, so apply never runs concurrently with itself.
+type Reloader struct {
+	args  []string
+	apply func(*Config)
+
+	mu        sync.Mutex
+	current   *Config
+	version   int
+	appliedAt time.Time
+	last      ReloadOutcome
+}
+
+// NewReloader creates a reloader for cfg, which was loaded from args, and applies cfg.
+func NewReloader(args []string, cfg *Config, apply func(*Config)) *Reloader {
+	r := &Reloader{args: args, apply: apply, current: cfg, version: 1, appliedAt: time.Now()}
+	r.last = ReloadOutcome{At: r.appliedAt, Trigger: TriggerStartup, Result: ReloadApplied}
+	apply(cfg)
+	return r
+}
+
+// Current returns the active configuration.
+func (r *Reloader) Current() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Reload loads and validates the configuration and applies the runtime-changeable settings that differ.
+// An invalid configuration is rejected as a whole and the running configuration is kept.
+func (r *Reloader) Reload(trigger string) ReloadOutcome {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	outcome := ReloadOutcome{At: time.Now(), Trigger: trigger}
+	loaded, err := Load(r.args)
+	if err != nil {
+		outcome.Result, outcome.Error = ReloadFailed, err.Error()
+		r.last = outcome
+		log.Printf("Config reload (%s) failed: %v", trigger, err)
+		return outcome
+	}
+
+	next := *r.current
+	current, target, incoming := r.current.settings(), next.settings(), loaded.settings()
+	for i, s := range current {
+		if reflect.DeepEqual(reflect.ValueOf(s.value).Elem().Interface(), reflect.ValueOf(incoming[i].value).Elem().Interface()) {
+			continue
+		}
+		if !s.reload {
+			outcome.RestartRequired = append(outcome.RestartRequired, s.name)
+			continue
+		}
+		reflect.ValueOf(target[i].value).Elem().Set(reflect.ValueOf(incoming[i].value).Elem())
+		outcome.Changed = append(outcome.Changed, s.name)
+	}
+
+	outcome.Result = ReloadUnchanged
+	if len(outcome.Changed) > 0 {
+		r.apply(&next)
+		r.current = &next
+		r.version++
+		r.appliedAt = outcome.At
+		outcome.Result = ReloadApplied
+	}
+	r.last = outcome
+	log.Printf("Config reload (%s): %s, changed %v, restart required for %v", trigger, outcome.Result, outcome.Changed, outcome.RestartRequired)
+	return outcome
+}
+
+// Status returns the active configuration version and the outcome of the last reload.
+func (r *Reloader) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var buf bytes.Buffer
+	r.current.Print(&buf)
+	sum := sha256.Sum256(buf.Bytes())
+	return Status{
+		Version:    r.version,
+		Checksum:   hex.EncodeToString(sum[:8]),
+		File:       r.current.File,
+		AppliedAt:  r.appliedAt,
+		LastReload: r.last,
+		Settings:   r.current.Redacted(),
+	}
+}
+
+// Watch reloads on SIGHUP and, when a config file is in use and config_watch_interval is positive, whenever
+// the file's modification time or size changes. It returns when ctx is done.
+func (r *Reloader) Watch(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	cfg := r.Current()
+	var tick <-chan time.Time
+	var lastStat os.FileInfo
+	if cfg.File != "" && cfg.ConfigWatchInterval > 0 {
+		ticker := time.NewTicker(cfg.ConfigWatchInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+		lastStat, _ = os.Stat(cfg.File)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			r.Reload(TriggerSignal)
+		case <-tick:
+			stat, err := os.Stat(cfg.File)
+			if err != nil || (lastStat != nil && stat.ModTime().Equal(lastStat.ModTime()) && stat.Size() == lastStat.Size()) {
+				continue
+			}
+			lastStat = stat
+			r.Reload(TriggerFile)
+		}
+	}
+}
+
+// StatusHandler serves Status as JSON.
+func (r *Reloader) StatusHandler() http.Handler {
+	return r.authorize(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(r.Status())
+	}))
+}
+
+// ReloadHandler reloads the configuration and serves the outcome as JSON, with status 422 if it was rejected.
+func (r *Reloader) ReloadHandler() http.Handler {
+	return r.authorize(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		outcome := r.Reload(TriggerAPI)
+		w.Header().Set("Content-Type", "application/json")
+		if outcome.Result == ReloadFailed {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		json.NewEncoder(w).Encode(outcome)
+	}))
+}
+
+// authorize requires the admin token as a bearer token. Without a configured token, e.g. after a reload
+// cleared it, every request is refused.
+func (r *Reloader) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token := r.Current().AdminToken
+		given, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
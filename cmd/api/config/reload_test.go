@@ -0,0 +1,99 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestReloader_AppliesRuntimeSettings(t *testing.T) {
+	path := writeFile(t, "api.yaml", "rate_limit_rps: 5\n")
+	args := []string{"--config", path}
+	cfg, err := Load(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var applied []*Config
+	r := NewReloader(args, cfg, func(c *Config) { applied = append(applied, c) })
+
+	if outcome := r.Reload(TriggerAPI); outcome.Result != ReloadUnchanged {
+		t.Fatalf("expected %q without changes, got %+v", ReloadUnchanged, outcome)
+	}
+
+	if err := os.WriteFile(path, []byte("rate_limit_rps: 1\napi_port: \"9999\"\nfulfill_strategies: [cache]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	outcome := r.Reload(TriggerFile)
+	if outcome.Result != ReloadApplied {
+		t.Fatalf("expected %q, got %+v", ReloadApplied, outcome)
+	}
+	if !slices.Equal(outcome.Changed, []string{"rate_limit_rps", "fulfill_strategies"}) {
+		t.Errorf("unexpected changed settings %v", outcome.Changed)
+	}
+	if !slices.Equal(outcome.RestartRequired, []string{"api_port", "swagger_host"}) {
+		t.Errorf("expected api_port and the derived swagger_host to require a restart, got %v", outcome.RestartRequired)
+	}
+	current := r.Current()
+	if current.RateLimitRPS != 1 || current.APIPort != "8080" || !slices.Equal(current.FulfillStrategies, []string{"cache"}) {
+		t.Errorf("unexpected active configuration %+v", current)
+	}
+	if len(applied) != 2 || applied[1] != current {
+		t.Errorf("expected the reloaded configuration to be applied, got %d applications", len(applied))
+	}
+	if status := r.Status(); status.Version != 2 || status.LastReload.Trigger != TriggerFile {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
+
+func TestReloader_RejectsInvalidConfiguration(t *testing.T) {
+	path := writeFile(t, "api.yaml", "rate_limit_rps: 5\n")
+	args := []string{"--config", path}
+	cfg, err := Load(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReloader(args, cfg, func(*Config) {})
+
+	if err := os.WriteFile(path, []byte("rate_limit_rps: 1\nfulfill_strategies: [magic]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	outcome := r.Reload(TriggerSignal)
+	if outcome.Result != ReloadFailed || outcome.Error == "" {
+		t.Fatalf("expected the reload to fail, got %+v", outcome)
+	}
+	if r.Current().RateLimitRPS != 5 {
+		t.Error("expected the running configuration to be kept")
+	}
+	if status := r.Status(); status.Version != 1 || status.LastReload.Result != ReloadFailed {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
+
+func TestReloader_AdminHandlersRequireToken(t *testing.T) {
+	cfg := Default()
+	r := NewReloader(nil, cfg, func(*Config) {})
+	get := func(auth string) int {
+		req := httptest.NewRequest(http.MethodGet, "/admin/config", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		r.StatusHandler().ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Without a token, every request is refused.
+	for _, auth := range []string{"", "Bearer "} {
+		if code := get(auth); code != http.StatusUnauthorized {
+			t.Errorf("no token, Authorization %q: expected status 401, got %d", auth, code)
+		}
+	}
+	cfg.AdminToken = "secret"
+	for auth, want := range map[string]int{"": http.StatusUnauthorized, "Bearer wrong": http.StatusUnauthorized, "Bearer secret": http.StatusOK} {
+		if code := get(auth); code != want {
+			t.Errorf("Authorization %q: expected status %d, got %d", auth, want, code)
+		}
+	}
+}
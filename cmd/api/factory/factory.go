@@ -1,44 +1,183 @@
 package factory
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"slices"
 
 	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/cmd/api/config"
 	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+	"github.com/rlpaul93/order-fulfillment/internal/infrastructure/metrics"
 )
 
 // BuildServices wires up dependencies for the API
 // If dbConn is nil, in-memory repositories are used
-func BuildServices(dbConn *sql.DB) (prodSvc *service.ProductService, packSvc *service.PackService, fulfillSvc *service.PackFulfillmentService) {
+func BuildServices(cfg *config.Config, dbConn *sql.DB, m *metrics.Metrics) (prodSvc *service.ProductService, packSvc *service.PackService, fulfillSvc *service.PackFulfillmentService, customerSvc *service.CustomerService) {
 	var prodRepo port.ProductRepository
 	var packRepo port.PackRepository
+	var customerRepo port.CustomerRepository
 
 	if dbConn != nil {
-		prodRepo = &out.ProductRepositoryPg{DB: dbConn}
-		packRepo = &out.PackRepositoryPg{DB: dbConn}
+		prodRepo = &out.ProductRepositoryPg{DB: dbConn, Metrics: m}
+		packRepo = &out.PackRepositoryPg{DB: dbConn, Metrics: m}
+		customerRepo = &out.CustomerRepositoryPg{DB: dbConn, Metrics: m}
 	} else {
 		prodRepo = out.NewProductRepositoryMem()
 		packRepo = out.NewPackRepositoryMem()
+		customerRepo = out.NewCustomerRepositoryMem()
 		seedDefaultData(prodRepo, packRepo)
 	}
 
 	prodSvc = &service.ProductService{Repo: prodRepo}
 	packSvc = &service.PackService{Repo: packRepo}
-	fulfillSvc = &service.PackFulfillmentService{}
+	customerSvc = &service.CustomerService{Repo: customerRepo}
+	fulfillSvc = &service.PackFulfillmentService{Packs: packSvc, Customers: customerSvc, Metrics: m}
+
+	// The cache always exists so that it can be resized, or enabled, when the configuration is reloaded
+	cache := service.NewFulfillmentCache(cfg.FulfillCacheSize)
+	fulfillSvc.Cache = cache
+	packSvc.Listeners = append(packSvc.Listeners, cache)
+	m.RegisterFulfillmentCache(cache)
+
+	if cfg.FulfillTableMax > 0 {
+		tables := service.NewFulfillmentTables(cfg.FulfillTableMax)
+		fulfillSvc.Tables = tables
+		packSvc.Listeners = append(packSvc.Listeners, tables)
+		prodSvc.Listeners = append(prodSvc.Listeners, tables)
+		buildFulfillmentTables(prodRepo, packRepo, tables)
+	}
 	return
 }
 
+// BuildShipments returns the carton catalog and the service planning shipments over the given services.
+// If dbConn is nil, the catalog is kept in memory.
+func BuildShipments(cfg *config.Config, dbConn *sql.DB, m *metrics.Metrics, fulfillSvc *service.PackFulfillmentService) (*service.CartonService, *service.ShipmentService) {
+	var repo port.CartonRepository
+	if dbConn != nil {
+		repo = &out.CartonRepositoryPg{DB: dbConn, Metrics: m}
+	} else {
+		repo = out.NewCartonRepositoryMem()
+	}
+	cartonSvc := &service.CartonService{Repo: repo}
+	return cartonSvc, &service.ShipmentService{
+		Cartons:     cartonSvc,
+		Fulfillment: fulfillSvc,
+		Limits:      service.ShipmentLimits{MaxWeightG: cfg.ShipmentMaxWeightG, MaxVolumeCM3: int64(cfg.ShipmentMaxVolumeCM3)},
+	}
+}
+
+// BuildIdempotency returns the service storing responses to requests with idempotency keys, or nil if
+// the configured window disables them. If dbConn is nil, the responses are kept in memory.
+func BuildIdempotency(cfg *config.Config, dbConn *sql.DB, m *metrics.Metrics) *service.IdempotencyService {
+	if cfg.IdempotencyWindow <= 0 {
+		return nil
+	}
+	var repo port.IdempotencyRepository
+	if dbConn != nil {
+		repo = &out.IdempotencyRepositoryPg{DB: dbConn, Metrics: m}
+	} else {
+		repo = out.NewIdempotencyRepositoryMem()
+	}
+	return &service.IdempotencyService{Repo: repo, Window: cfg.IdempotencyWindow}
+}
+
+// BuildWebhooks returns the service delivering domain events to webhook subscriptions, or nil if webhook
+// is not one of the configured event publishers. If dbConn is nil, subscriptions and deliveries are kept
+// in memory.
+func BuildWebhooks(cfg *config.Config, dbConn *sql.DB, m *metrics.Metrics) *service.WebhookService {
+	if !slices.Contains(cfg.EventPublishers, "webhook") {
+		return nil
+	}
+	var repo port.WebhookRepository
+	if dbConn != nil {
+		repo = &out.WebhookRepositoryPg{DB: dbConn, Metrics: m}
+	} else {
+		repo = out.NewWebhookRepositoryMem()
+	}
+	return &service.WebhookService{
+		Repo:                repo,
+		Sender:              out.WebhookSenderHTTP{Client: out.NewWebhookClient(cfg.WebhookTimeout, cfg.WebhookAllowPrivate)},
+		MaxAttempts:         cfg.WebhookMaxAttempts,
+		Backoff:             cfg.WebhookBackoff,
+		MaxBackoff:          cfg.WebhookMaxBackoff,
+		AllowPrivateTargets: cfg.WebhookAllowPrivate,
+	}
+}
+
+// BuildEventStream returns the stream serving domain events to GET /events, or nil if stream is not one
+// of the configured event publishers.
+func BuildEventStream(cfg *config.Config) *service.EventStream {
+	if !slices.Contains(cfg.EventPublishers, "stream") {
+		return nil
+	}
+	return service.NewEventStream(cfg.EventStreamBuffer)
+}
+
+// BuildEvents returns the recorder writing the services' domain events to the outbox and the relay
+// delivering them to the configured publishers, or nil for both if no publisher is configured. If dbConn
+// is nil, the outbox is an in-memory queue. webhooks and stream are the publishers built by BuildWebhooks
+// and BuildEventStream.
+func BuildEvents(cfg *config.Config, dbConn *sql.DB, m *metrics.Metrics, webhooks *service.WebhookService, stream *service.EventStream) (*service.EventRecorder, *service.OutboxRelay) {
+	if len(cfg.EventPublishers) == 0 {
+		return nil, nil
+	}
+	recorder := &service.EventRecorder{}
+	if dbConn != nil {
+		recorder.Tx = &out.TransactorPg{DB: dbConn}
+		recorder.Outbox = &out.OutboxRepositoryPg{DB: dbConn, Metrics: m}
+	} else {
+		recorder.Tx = out.TransactorMem{}
+		recorder.Outbox = out.NewOutboxRepositoryMem()
+	}
+	relay := &service.OutboxRelay{Outbox: recorder.Outbox, Publishers: map[string]port.EventPublisher{}, Interval: cfg.OutboxRelayInterval}
+	for _, name := range cfg.EventPublishers {
+		switch name {
+		case "log":
+			relay.Publishers[name] = out.LogPublisher{}
+		case "webhook":
+			relay.Publishers[name] = webhooks
+		case "stream":
+			relay.Publishers[name] = stream
+		}
+	}
+	recorder.Wants = relay.Wants
+	return recorder, relay
+}
+
+// buildFulfillmentTables starts building the fulfillment table of every existing product
+func buildFulfillmentTables(prodRepo port.ProductRepository, packRepo port.PackRepository, tables *service.FulfillmentTables) {
+	ctx := context.Background()
+	products, err := prodRepo.List(ctx)
+	if err != nil {
+		log.Printf("Failed to list products for fulfillment tables: %v", err)
+		return
+	}
+	for _, product := range products {
+		packs, err := packRepo.ListByProduct(ctx, product.ID)
+		if err != nil {
+			log.Printf("Failed to list packs for product %s: %v", product.ID, err)
+			continue
+		}
+		sizes := make([]int, len(packs))
+		for i, p := range packs {
+			sizes[i] = p.Size
+		}
+		tables.Rebuild(product.ID, sizes)
+	}
+}
+
 // seedDefaultData adds a default product with packs for in-memory storage
 func seedDefaultData(prodRepo port.ProductRepository, packRepo port.PackRepository) {
 	product := &model.Product{
 		ID:   uuid.New(),
 		Name: "Default Product",
 	}
-	if err := prodRepo.Create(product); err != nil {
+	if err := prodRepo.Create(context.Background(), product); err != nil {
 		log.Printf("Failed to seed default product: %v", err)
 		return
 	}
@@ -49,7 +188,7 @@ func seedDefaultData(prodRepo port.ProductRepository, packRepo port.PackReposito
 			ProductID: product.ID,
 			Size:      size,
 		}
-		if err := packRepo.Create(pack); err != nil {
+		if err := packRepo.Create(context.Background(), pack); err != nil {
 			log.Printf("Failed to seed pack size %d: %v", size, err)
 		}
 	}
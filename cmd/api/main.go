@@ -1,34 +1,70 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/rlpaul93/order-fulfillment/cmd/api/config"
 	"github.com/rlpaul93/order-fulfillment/cmd/api/factory"
 	"github.com/rlpaul93/order-fulfillment/docs"
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/in"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
 	"github.com/rlpaul93/order-fulfillment/internal/infrastructure/db"
+	"github.com/rlpaul93/order-fulfillment/internal/infrastructure/metrics"
+	"github.com/rlpaul93/order-fulfillment/internal/infrastructure/ratelimit"
 	"github.com/rlpaul93/order-fulfillment/internal/infrastructure/server"
+	"github.com/rlpaul93/order-fulfillment/internal/infrastructure/tracing"
 )
 
+// idempotencyPurgeInterval is how often expired idempotency records are deleted.
+const idempotencyPurgeInterval = time.Hour
+
 // @title Order Fulfillment API
 // @version 1.0
 // @description REST API for product and pack management with optimal order fulfillment
 // @host localhost:8080
-// @BasePath /
+// @BasePath /v1
 // @schemes http
 func main() {
-	cfg := config.Load()
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cfg.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if cfg.File != "" {
+		log.Printf("Loaded config file %s", cfg.File)
+	}
 
 	// Set Swagger host and scheme dynamically
 	docs.SwaggerInfo.Host = cfg.SwaggerHost
 	docs.SwaggerInfo.Schemes = []string{cfg.SwaggerScheme}
 	log.Printf("Swagger: %s://%s", cfg.SwaggerScheme, cfg.SwaggerHost)
 
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TraceExporter, "order-fulfillment")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer shutdownTracing(context.Background())
+	log.Printf("Tracing exporter: %s", cfg.TraceExporter)
+
 	var dbConn *sql.DB
 	if cfg.StorageMode == "postgres" {
-		var err error
 		dbConn, err = db.NewConnection(cfg.DatabaseURL)
 		if err != nil {
 			log.Fatal(err)
@@ -39,9 +75,130 @@ func main() {
 		log.Println("Using in-memory storage")
 	}
 
-	prodSvc, packSvc, fulfillSvc := factory.BuildServices(dbConn)
-	handler := server.NewHandler(prodSvc, packSvc, fulfillSvc)
+	m := metrics.New()
+	prodSvc, packSvc, fulfillSvc, customerSvc := factory.BuildServices(cfg, dbConn, m)
+	cartonSvc, shipmentSvc := factory.BuildShipments(cfg, dbConn, m, fulfillSvc)
+
+	webhookSvc := factory.BuildWebhooks(cfg, dbConn, m)
+	eventStream := factory.BuildEventStream(cfg)
+	if recorder, relay := factory.BuildEvents(cfg, dbConn, m, webhookSvc, eventStream); recorder != nil {
+		prodSvc.Events, packSvc.Events, fulfillSvc.Events = recorder, recorder, recorder
+		go relay.Run(context.Background())
+		log.Printf("Domain events: relayed to %v", cfg.EventPublishers)
+	}
 
+	// The limiters always exist so that a reload can enable, tune or disable them
+	clientLimiter := ratelimit.NewClientLimiter(cfg.RateLimitRPS, cfg.RateLimitBurst, clientIdentity(cfg))
+	solverLimiter := ratelimit.NewConcurrencyLimiter(cfg.FulfillConcurrency)
+	fulfillSvc.Solvers = solverLimiter
+	reloader := config.NewReloader(os.Args[1:], cfg, func(c *config.Config) {
+		applySettings(c, clientLimiter, solverLimiter, fulfillSvc)
+	})
+	go reloader.Watch(context.Background())
+
+	serverOpts := []server.Option{
+		server.WithMetrics(m),
+		server.WithTracing(),
+		server.WithFulfillRateLimit(clientLimiter),
+		server.WithCustomers(customerSvc),
+		server.WithShipments(cartonSvc, shipmentSvc),
+	}
+	// The admin routes fail closed: without a token they are not served at all
+	if cfg.AdminToken != "" {
+		serverOpts = append(serverOpts, server.WithConfigAdmin(reloader.StatusHandler(), reloader.ReloadHandler()))
+	} else {
+		log.Printf("Admin endpoints: disabled, no admin_token configured")
+	}
+	if webhookSvc != nil {
+		serverOpts = append(serverOpts, server.WithWebhooks(webhookSvc))
+		go webhookSvc.Run(context.Background(), cfg.WebhookDispatchInterval)
+		log.Printf("Webhooks: delivered every %s, up to %d attempts", cfg.WebhookDispatchInterval, cfg.WebhookMaxAttempts)
+	}
+	if eventStream != nil {
+		serverOpts = append(serverOpts, server.WithEventStream(eventStream))
+	}
+	if cfg.GraphQL {
+		schema, err := in.NewGraphQLSchema(in.GraphQLServices{
+			Products:    prodSvc,
+			Packs:       packSvc,
+			Fulfillment: fulfillSvc,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		limits := in.GraphQLLimits{MaxDepth: cfg.GraphQLMaxDepth, MaxComplexity: cfg.GraphQLMaxComplexity, MaxFulfillments: cfg.GraphQLMaxFulfillments}
+		serverOpts = append(serverOpts, server.WithGraphQL(in.GraphQLHandler(schema, limits)))
+	}
+	if idempotencySvc := factory.BuildIdempotency(cfg, dbConn, m); idempotencySvc != nil {
+		serverOpts = append(serverOpts, server.WithIdempotency(idempotencySvc))
+		go purgeIdempotencyKeys(context.Background(), idempotencySvc)
+		log.Printf("Idempotency keys: responses replayed for %s", cfg.IdempotencyWindow)
+	}
+	if cfg.LegacyRoutes {
+		sunset, _ := cfg.LegacySunset() // validated by config.Load
+		serverOpts = append(serverOpts, server.WithLegacyRoutes(sunset))
+	}
+	handler := server.NewHandler(prodSvc, packSvc, fulfillSvc, serverOpts...)
+
+	srv := &http.Server{
+		Addr:              ":" + cfg.APIPort,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+	if cfg.GRPCPort != "" {
+		go serveGRPC(cfg.GRPCPort, &in.GRPCServer{
+			Products:    prodSvc,
+			Packs:       packSvc,
+			Fulfillment: fulfillSvc,
+		}, clientLimiter)
+	}
 	log.Printf("API running on :%s", cfg.APIPort)
-	log.Fatal(http.ListenAndServe(":"+cfg.APIPort, handler))
+	log.Fatal(srv.ListenAndServe())
+}
+
+// serveGRPC serves the gRPC API on port, rate limiting fulfillments per client like the REST API, exiting
+// if it cannot.
+func serveGRPC(port string, svc *in.GRPCServer, clients *ratelimit.ClientLimiter) {
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Fatalf("gRPC API cannot listen: %v", err)
+	}
+	log.Printf("gRPC API running on :%s", port)
+	log.Fatal(server.NewGRPCServer(svc, clients).Serve(lis))
+}
+
+// purgeIdempotencyKeys periodically deletes the idempotency records whose window has passed.
+func purgeIdempotencyKeys(ctx context.Context, svc *service.IdempotencyService) {
+	for range time.Tick(idempotencyPurgeInterval) {
+		n, err := svc.PurgeExpired(ctx)
+		if err != nil {
+			slog.Error("Failed to purge idempotency keys", "error", err)
+			continue
+		}
+		slog.Debug("Purged idempotency keys", "count", n)
+	}
+}
+
+// clientIdentity returns how the rate limiter configured by cfg tells clients apart.
+func clientIdentity(cfg *config.Config) ratelimit.ClientIdentity {
+	return ratelimit.ClientIdentity{TrustedProxies: cfg.RateLimitProxies, APIKeys: cfg.RateLimitAPIKeys}
+}
+
+// applySettings hands the runtime-changeable settings of cfg to the running components.
+func applySettings(cfg *config.Config, clientLimiter *ratelimit.ClientLimiter, solverLimiter *ratelimit.ConcurrencyLimiter, fulfillSvc *service.PackFulfillmentService) {
+	slog.SetLogLoggerLevel(cfg.SlogLevel())
+	clientLimiter.SetLimits(cfg.RateLimitRPS, cfg.RateLimitBurst, clientIdentity(cfg))
+	solverLimiter.SetMax(cfg.FulfillConcurrency)
+	fulfillSvc.Cache.Resize(cfg.FulfillCacheSize)
+
+	strategies := map[string]bool{}
+	for _, strategy := range cfg.FulfillStrategies {
+		strategies[strategy] = true
+	}
+	fulfillSvc.Configure(service.FulfillmentSettings{MaxQuantity: cfg.FulfillMaxQuantity, SolverTimeout: cfg.SolverTimeout, Strategies: strategies})
+
+	log.Printf("Fulfill limits: %.2f req/s per client (burst %d), %d concurrent, max quantity %d, solver timeout %s, cache size %d, strategies %v",
+		cfg.RateLimitRPS, cfg.RateLimitBurst, cfg.FulfillConcurrency, cfg.FulfillMaxQuantity, cfg.SolverTimeout, cfg.FulfillCacheSize, cfg.FulfillStrategies)
 }
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/in/cli"
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+	"github.com/rlpaul93/order-fulfillment/internal/infrastructure/db"
+)
+
+// readQuantities reads whitespace or comma separated quantities, e.g. from stdin.
+func readQuantities(r io.Reader) ([]int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	var quantities []int
+	for scanner.Scan() {
+		for _, field := range strings.Split(scanner.Text(), ",") {
+			if field == "" {
+				continue
+			}
+			q, err := parseQuantity(field)
+			if err != nil {
+				return nil, err
+			}
+			quantities = append(quantities, q)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(quantities) == 0 {
+		return nil, errors.New("no quantities given; use --qty, --input or stdin")
+	}
+	return quantities, nil
+}
+
+// readQuantitiesFile reads quantities from a JSON file (an array of numbers or of {"quantity": n} objects)
+// or a CSV file (a "quantity" column, or the first column if there is no header).
+func readQuantitiesFile(path string) ([]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return readQuantitiesJSON(f)
+	}
+	return readQuantitiesCSV(f)
+}
+
+func readQuantitiesJSON(r io.Reader) ([]int, error) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("decode quantities: %w", err)
+	}
+	quantities := make([]int, 0, len(items))
+	for i, item := range items {
+		var q int
+		if err := json.Unmarshal(item, &q); err != nil {
+			var obj struct {
+				Quantity *int `json:"quantity"`
+			}
+			if err := json.Unmarshal(item, &obj); err != nil || obj.Quantity == nil {
+				return nil, fmt.Errorf("item %d: expected a number or an object with a quantity", i)
+			}
+			q = *obj.Quantity
+		}
+		if q <= 0 {
+			return nil, fmt.Errorf("item %d: quantity must be positive", i)
+		}
+		quantities = append(quantities, q)
+	}
+	return quantities, nil
+}
+
+func readQuantitiesCSV(r io.Reader) ([]int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read quantities: %w", err)
+	}
+	column := 0
+	if len(records) > 0 {
+		for i, name := range records[0] {
+			if strings.EqualFold(strings.TrimSpace(name), "quantity") {
+				column = i
+				records = records[1:]
+				break
+			}
+		}
+	}
+	quantities := make([]int, 0, len(records))
+	for i, record := range records {
+		if column >= len(record) {
+			return nil, fmt.Errorf("row %d: missing quantity", i+1)
+		}
+		q, err := parseQuantity(record[column])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		quantities = append(quantities, q)
+	}
+	return quantities, nil
+}
+
+func parseQuantity(s string) (int, error) {
+	q, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || q <= 0 {
+		return 0, fmt.Errorf("invalid quantity %q", s)
+	}
+	return q, nil
+}
+
+// packSizesFromSnapshot loads a product's pack sizes from a catalog snapshot file, a JSON array or NDJSON;
+// see cli.ReadCatalog.
+func packSizesFromSnapshot(path string, productID uuid.UUID) ([]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	catalog, err := cli.ReadCatalog(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+	for _, p := range catalog {
+		if p.ID == productID {
+			return p.PackSizes, nil
+		}
+	}
+	return nil, fmt.Errorf("product %s not found in %s", productID, path)
+}
+
+// packSizesFromDatabase loads a product's pack sizes from PostgreSQL.
+func packSizesFromDatabase(ctx context.Context, databaseURL string, productID uuid.UUID) ([]int, error) {
+	if databaseURL == "" {
+		return nil, errors.New("--snapshot or --database-url is required with --product-id")
+	}
+	conn, err := db.NewConnection(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	repo := &out.PackRepositoryPg{DB: conn}
+	packs, err := repo.ListByProduct(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("load packs: %w", err)
+	}
+	sizes := make([]int, len(packs))
+	for i, p := range packs {
+		sizes[i] = p.Size
+	}
+	return sizes, nil
+}
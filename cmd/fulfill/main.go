@@ -0,0 +1,94 @@
+// Command fulfill computes optimal pack plans offline, using the same solver as the API.
+//
+// Usage:
+//
+//	fulfill --packs 250,500,1000 --qty 12001
+//	fulfill --packs 250,500,1000 --input quantities.csv --format csv
+//	echo "251 12001" | fulfill --packs 250,500,1000 --format json
+//	fulfill --product-id <uuid> --snapshot catalog.json --qty 12001
+//	fulfill --product-id <uuid> --database-url postgres://... --qty 12001
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/in/cli"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "fulfill:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("fulfill", flag.ContinueOnError)
+	packsFlag := fs.String("packs", "", "comma-separated pack sizes, e.g. 250,500,1000")
+	qty := fs.Int("qty", 0, "quantity to fulfill; if omitted, quantities are read from --input or stdin")
+	input := fs.String("input", "", "CSV or JSON file of quantities")
+	format := fs.String("format", "table", "output format: table, json or csv")
+	productIDFlag := fs.String("product-id", "", "load pack sizes of this product instead of --packs")
+	snapshot := fs.String("snapshot", "", "catalog snapshot file (JSON array or NDJSON, as exported) to load --product-id from")
+	databaseURL := fs.String("database-url", os.Getenv("DATABASE_URL"), "PostgreSQL URL to load --product-id from")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	var sizes []int
+	var err error
+	switch {
+	case *packsFlag != "":
+		sizes, err = cli.ParsePackSizes(*packsFlag)
+	case *productIDFlag != "":
+		productID, perr := uuid.Parse(*productIDFlag)
+		if perr != nil {
+			return fmt.Errorf("invalid --product-id: %w", perr)
+		}
+		if *snapshot != "" {
+			sizes, err = packSizesFromSnapshot(*snapshot, productID)
+		} else {
+			sizes, err = packSizesFromDatabase(ctx, *databaseURL, productID)
+		}
+	default:
+		return fmt.Errorf("either --packs or --product-id is required")
+	}
+	if err != nil {
+		return err
+	}
+	if len(sizes) == 0 {
+		return fmt.Errorf("no pack sizes")
+	}
+
+	var quantities []int
+	switch {
+	case *qty > 0:
+		quantities = []int{*qty}
+	case *input != "":
+		quantities, err = readQuantitiesFile(*input)
+	default:
+		quantities, err = readQuantities(stdin)
+	}
+	if err != nil {
+		return err
+	}
+
+	svc := &service.PackFulfillmentService{}
+	plans := make([]plan, 0, len(quantities))
+	for _, q := range quantities {
+		result, err := svc.FulfillOrder(ctx, q, append([]int(nil), sizes...), service.FulfillmentOptions{})
+		if err != nil {
+			return fmt.Errorf("quantity %d: %w", q, err)
+		}
+		plans = append(plans, newPlan(q, result))
+	}
+	return writePlans(stdout, *format, plans)
+}
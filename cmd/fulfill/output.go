@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+type planPack struct {
+	Size  int `json:"size"`
+	Count int `json:"count"`
+}
+
+// plan is the printable form of a fulfillment result, with packs ordered by size descending.
+type plan struct {
+	Quantity   int        `json:"quantity"`
+	TotalItems int        `json:"total_items"`
+	Overage    int        `json:"overage"`
+	PackCount  int        `json:"pack_count"`
+	Packs      []planPack `json:"packs"`
+}
+
+func newPlan(quantity int, result service.PackFulfillmentResult) plan {
+	p := plan{Quantity: quantity, TotalItems: result.TotalItems, Overage: result.TotalItems - quantity, Packs: []planPack{}}
+	for size, count := range result.Packs {
+		p.Packs = append(p.Packs, planPack{Size: size, Count: count})
+		p.PackCount += count
+	}
+	slices.SortFunc(p.Packs, func(a, b planPack) int { return b.Size - a.Size })
+	return p
+}
+
+// summary renders the packs as e.g. "2x5000 1x250".
+func (p plan) summary() string {
+	parts := make([]string, len(p.Packs))
+	for i, pack := range p.Packs {
+		parts[i] = fmt.Sprintf("%dx%d", pack.Count, pack.Size)
+	}
+	return strings.Join(parts, " ")
+}
+
+func writePlans(w io.Writer, format string, plans []plan) error {
+	switch format {
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "QUANTITY\tTOTAL ITEMS\tOVERAGE\tPACKS\tPLAN")
+		for _, p := range plans {
+			fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%s\n", p.Quantity, p.TotalItems, p.Overage, p.PackCount, p.summary())
+		}
+		return tw.Flush()
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(plans)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"quantity", "total_items", "overage", "pack_count", "plan"})
+		for _, p := range plans {
+			cw.Write([]string{
+				strconv.Itoa(p.Quantity),
+				strconv.Itoa(p.TotalItems),
+				strconv.Itoa(p.Overage),
+				strconv.Itoa(p.PackCount),
+				p.summary(),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unknown format %q; use table, json or csv", format)
+	}
+}
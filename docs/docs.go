@@ -15,47 +15,78 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/fulfill": {
+        "/cartons": {
             "get": {
-                "description": "Given a product ID and quantity, returns the optimal combination of packs that fulfills the order with minimal excess items and minimal pack count",
+                "description": "Get the catalog of shipping cartons",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Fulfillment"
+                    "Cartons"
                 ],
-                "summary": "Calculate optimal pack fulfillment",
+                "summary": "List cartons",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Carton"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a shipping carton to the catalog used to plan shipments. Inner dimensions are in millimetres and the maximum weight of its load in grams.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cartons"
+                ],
+                "summary": "Create a carton",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Product UUID",
-                        "name": "product_id",
-                        "in": "query",
-                        "required": true
+                        "description": "Carton to create",
+                        "name": "carton",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Carton"
+                        }
                     },
                     {
-                        "type": "integer",
-                        "description": "Number of items to fulfill",
-                        "name": "quantity",
-                        "in": "query",
-                        "required": true
+                        "type": "string",
+                        "description": "Unique key making retries of the request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/service.PackFulfillmentResult"
+                            "$ref": "#/definitions/model.Carton"
                         }
                     },
                     "400": {
-                        "description": "Invalid product_id or quantity",
+                        "description": "Invalid carton",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "No packs found for product",
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "string"
                         }
@@ -63,24 +94,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/products": {
+        "/cartons/{id}": {
             "get": {
-                "description": "Get a list of all products",
+                "description": "Get a shipping carton by UUID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Cartons"
+                ],
+                "summary": "Get a carton by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Carton UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "List all products",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Product"
-                            }
+                            "$ref": "#/definitions/model.Carton"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid carton ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Carton not found",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "500": {
@@ -91,8 +140,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
-                "description": "Create a new product with a name",
+            "put": {
+                "description": "Replace a shipping carton's name, inner dimensions and maximum weight",
                 "consumes": [
                     "application/json"
                 ],
@@ -100,29 +149,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Cartons"
                 ],
-                "summary": "Create a new product",
+                "summary": "Update a carton",
                 "parameters": [
                     {
-                        "description": "Product to create",
-                        "name": "product",
+                        "type": "string",
+                        "description": "Carton UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Carton with its new dimensions",
+                        "name": "carton",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Product"
+                            "$ref": "#/definitions/model.Carton"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making retries of the request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Product"
+                            "$ref": "#/definitions/model.Carton"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid carton",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Carton not found",
                         "schema": {
                             "type": "string"
                         }
@@ -134,78 +202,84 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/products/{id}": {
-            "get": {
-                "description": "Get a product by its UUID",
-                "produces": [
-                    "application/json"
-                ],
+            },
+            "delete": {
+                "description": "Remove a shipping carton from the catalog",
                 "tags": [
-                    "Products"
+                    "Cartons"
                 ],
-                "summary": "Get a product by ID",
+                "summary": "Delete a carton",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Product UUID",
+                        "description": "Carton UUID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making retries of the request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "Carton deleted",
                         "schema": {
-                            "$ref": "#/definitions/model.Product"
+                            "type": "string"
                         }
                     },
                     "400": {
-                        "description": "Invalid product ID",
+                        "description": "Invalid carton ID",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "404": {
-                        "description": "Product not found",
+                        "description": "Carton not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a product by its UUID",
+            }
+        },
+        "/catalog/export": {
+            "get": {
+                "description": "Streams every product with its pack sizes, ordered by name, as CSV or NDJSON in the import format",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
                 "tags": [
-                    "Products"
+                    "Catalog"
                 ],
-                "summary": "Delete a product by ID",
+                "summary": "Export the catalog",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Product UUID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Output format: csv or ndjson (default ndjson, or negotiated from Accept)",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Product deleted",
+                    "200": {
+                        "description": "Catalog rows",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "400": {
-                        "description": "Invalid product ID",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
+                        "description": "Unsupported format",
                         "schema": {
                             "type": "string"
                         }
@@ -213,40 +287,81 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/{id}/packs": {
-            "get": {
-                "description": "Get all packs for a specific product",
+        "/catalog/import": {
+            "post": {
+                "description": "Upserts products and their pack sizes from CSV (columns id, sku, name, pack_sizes with sizes separated by \";\") or NDJSON ({\"sku\", \"name\", \"pack_sizes\"} per line). Rows are matched by SKU, then ID, then name. Invalid rows are reported and skipped.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Catalog"
                 ],
-                "summary": "List packs for a product",
+                "summary": "Import the catalog",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Product UUID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Input format: csv or ndjson (defaults to the Content-Type)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate and report without writing",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making retries of the request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Pack"
-                            }
+                            "$ref": "#/definitions/service.CatalogImportReport"
                         }
                     },
                     "400": {
-                        "description": "Invalid product ID",
+                        "description": "Unreadable or unsupported input, or invalid dry_run",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "string"
                         }
+                    }
+                }
+            }
+        },
+        "/customers": {
+            "get": {
+                "description": "Get all customers with their pack rules",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "List customers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Customer"
+                            }
+                        }
                     },
                     "500": {
                         "description": "Internal server error",
@@ -256,8 +371,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
-                "description": "Replace all packs for a product with a new list of sizes",
+            "post": {
+                "description": "Create a customer with optional pack rules restricting the pack sizes used for its orders. A rule without product_id applies to every product that has no rule of its own.",
                 "consumes": [
                     "application/json"
                 ],
@@ -265,42 +380,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Customers"
                 ],
-                "summary": "Update packs for a product",
+                "summary": "Create a customer",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Product UUID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Array of pack sizes",
-                        "name": "sizes",
+                        "description": "Customer to create",
+                        "name": "customer",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "integer"
-                            }
+                            "$ref": "#/definitions/model.Customer"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making retries of the request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Pack"
-                            }
+                            "$ref": "#/definitions/model.Customer"
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Invalid customer",
                         "schema": {
                             "type": "string"
                         }
@@ -313,37 +421,1866 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "model.Pack": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "string"
-                },
-                "product_id": {
-                    "type": "string"
-                },
-                "size": {
-                    "type": "integer"
-                }
-            }
         },
-        "model.Product": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                }
-            }
+        "/customers/{id}": {
+            "get": {
+                "description": "Get a customer and its pack rules by UUID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Get a customer by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Customer"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid customer ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Customer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace a customer's name and pack rules",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Update a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Customer with its new pack rules",
+                        "name": "customer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Customer"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making retries of the request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Customer"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid customer",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Customer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a customer and its pack rules",
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Delete a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making retries of the request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Customer deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid customer ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Customer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "Stream domain events as Server-Sent Events, each with the event type as its event name, the event as JSON data and its position in the stream as ID. A client reconnecting with a Last-Event-ID header (or the last_event_id query parameter) first receives the events it missed that are still buffered; a \": missed events\" comment announces that some have already left the buffer.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Events"
+                ],
+                "summary": "Stream domain events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only events about this product UUID",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Only events of these types",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received, to resume after it",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Alternative to the Last-Event-ID header",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of events",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid product ID, event type or last event ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/fulfill": {
+            "get": {
+                "description": "Given a product ID and quantity, returns the optimal combination of packs that fulfills the order with minimal excess items and minimal pack count. With customer_id, only the pack sizes the customer accepts for the product are used. mode=under ships the largest quantity not exceeding the request and reports the shortfall, and mode=exact_only fails unless the quantity can be met exactly. max_overage, max_overage_pct and max_packs are hard limits; when no combination meets them the response explains which one blocked the order. explain=true adds an Explanation with the overage, pack count, rejected runner-up plans, the pack set's GCD and largest unreachable quantity, and solver statistics. The response keeps the legacy service.PackFulfillmentResult shape (schema version 1) unless schema_version=2 requests a FulfillmentResponse, which lists the packs largest first with their pack IDs.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Fulfillment"
+                ],
+                "summary": "Calculate optimal pack fulfillment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product UUID",
+                        "name": "product_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to fulfill, from 1 to the configured fulfill_max_quantity",
+                        "name": "quantity",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Customer UUID whose pack rules apply",
+                        "name": "customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "over",
+                            "under",
+                            "exact_only"
+                        ],
+                        "type": "string",
+                        "default": "over",
+                        "description": "Fulfillment mode",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Most items that may be shipped beyond the quantity",
+                        "name": "max_overage",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Most items that may be shipped beyond the quantity, as a percentage of it",
+                        "name": "max_overage_pct",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Most packs the plan may use",
+                        "name": "max_packs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include an explanation of the chosen plan",
+                        "name": "explain",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            1,
+                            2
+                        ],
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Response schema version",
+                        "name": "schema_version",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Schema version 1; a FulfillmentResponse with schema_version=2",
+                        "schema": {
+                            "$ref": "#/definitions/service.PackFulfillmentResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid product_id, quantity, customer_id, mode, constraint, explain or schema_version",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "No packs found for product, or customer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "No plan satisfies the constraints, or the customer accepts none of the product's pack sizes",
+                        "schema": {
+                            "$ref": "#/definitions/in.InfeasibleResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, or too many concurrent fulfillments; see Retry-After",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Solver exceeded its time budget",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "post": {
+                "description": "Executes a GraphQL request against the product, pack and fulfillment schema: the queries product, products (paginated, with packs nested under each product) and fulfill(productId, quantity), and the mutations createProduct, updateProduct, deleteProduct and replacePacks. Errors are reported in the response's errors with a code extension such as NOT_FOUND, CONFLICT, BAD_USER_INPUT or INFEASIBLE. Queries nested deeper, estimated costlier or with more fulfill fields than the configured limits are rejected with QUERY_TOO_DEEP, QUERY_TOO_COMPLEX or TOO_MANY_FULFILLMENTS before running. Requests draw on the per-client rate limit of GET /fulfill. GET accepts the query, operationName and variables query parameters, for queries only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "GraphQL"
+                ],
+                "summary": "Execute a GraphQL query or mutation",
+                "parameters": [
+                    {
+                        "description": "GraphQL request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/in.GraphQLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "GraphQL response with data and errors",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed request or missing query",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "405": {
+                        "description": "Mutation sent with GET",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/plan": {
+            "post": {
+                "description": "Fulfills every line of an order like GET /fulfill, puts the packs of all lines together in the fewest cartons of the catalog like POST /shipments/plan, and groups the cartons into as few shipments as it finds within the configured shipment weight and volume limits. Cartons that do not fit a shipment alone are not used. Each product may appear in one line only; errors about a line report its 1-based number in line.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Fulfillment"
+                ],
+                "summary": "Plan the shipments of an order of several products",
+                "parameters": [
+                    {
+                        "description": "Order to plan",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/in.OrderPlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.OrderPlan"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, line, mode or constraint",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "No packs found for a product, or customer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "No plan of a line satisfies the constraints (status infeasible), or the packs cannot be shipped (status unshippable)",
+                        "schema": {
+                            "$ref": "#/definitions/in.PlanFailureResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests; see Retry-After",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Solver exceeded its time budget",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "Get a list of all products",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "List all products",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Product"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new product with a name and an optional unique SKU",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Create a new product",
+                "parameters": [
+                    {
+                        "description": "Product to create",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Product"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making retries of the request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "SKU already in use",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}": {
+            "get": {
+                "description": "Get a product by its UUID. The ETag response header carries the product's version; send it\nin If-None-Match to get 304 while the product is unchanged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Get a product by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously retrieved version",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Product"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the product"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Product unchanged",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid product ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a product's name and SKU. Send the ETag of the version being edited in If-Match to\nreject the update with 412 if the product has changed since; the version in the body is ignored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Update a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being updated",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Product fields to store",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Product"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making retries of the request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Product"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "SKU already in use",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "412": {
+                        "description": "Product changed since the If-Match version",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a product by its UUID",
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Delete a product by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making retries of the request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Product deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid product ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/fulfillment-table": {
+            "get": {
+                "description": "Reports whether the product's precomputed fulfillment table is building, ready or failed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Fulfillment"
+                ],
+                "summary": "Get the fulfillment table build status for a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.TableStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid product ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Fulfillment tables are disabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/packs": {
+            "get": {
+                "description": "Get all packs for a specific product. The ETag response header carries the version of the pack\nset; send it in If-None-Match to get 304 while the packs are unchanged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "List packs for a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously retrieved pack set",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Pack"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the pack set"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Packs unchanged",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid product ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace all packs for a product with a new list of sizes. Send the ETag of the pack set being\nedited in If-Match to reject the update with 412 if the packs have changed since.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Update packs for a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the pack set being replaced",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Array of pack sizes",
+                        "name": "sizes",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making retries of the request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Pack"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the new pack set"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, or a pack size that is not positive",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "412": {
+                        "description": "Packs changed since the If-Match version",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/packs/{packID}": {
+            "put": {
+                "description": "Set the outer dimensions (mm) and weight (g) of one of a product's packs, which shipment plans need to put the pack in cartons. Null dimensions and a zero weight mark them unknown. They are kept when the product's packs are replaced by packs of the same sizes. The pack set's version, and so its ETag, changes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Set the dimensions and weight of a pack",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pack UUID",
+                        "name": "packID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Dimensions and weight of the pack",
+                        "name": "measurements",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/in.PackMeasurements"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making retries of the request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Pack"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, or dimensions or weight not positive",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Product has no such pack",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/shipments/plan": {
+            "post": {
+                "description": "Fulfills an order like GET /fulfill and assigns the packs to the fewest cartons of the catalog, then to the smallest ones. A carton takes packs that each fit its inner dimensions in some orientation, up to its volume and maximum weight; the arrangement of the packs inside is not planned. Every pack used must have dimensions and weight.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Fulfillment"
+                ],
+                "summary": "Plan the cartons of a shipment",
+                "parameters": [
+                    {
+                        "description": "Order to plan",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/in.ShipmentPlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.ShipmentPlan"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, quantity, mode or constraint",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "No packs found for product, or customer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "No plan satisfies the constraints (status infeasible), or the packs cannot be put in cartons (status unshippable)",
+                        "schema": {
+                            "$ref": "#/definitions/in.PlanFailureResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests; see Retry-After",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Solver exceeded its time budget",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Get all webhook subscriptions, without their secrets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List webhook subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WebhookSubscription"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Subscribe a URL to domain events. An empty event_types subscribes to every event. Each delivery is posted as the JSON event with Webhook-Id, Webhook-Event, Webhook-Timestamp and Webhook-Signature headers, the signature being sha256= followed by the hex HMAC-SHA256 of the timestamp, a dot and the body, keyed with the secret. A secret is generated when none is given; it is only returned by this call. URLs whose host is or resolves to a loopback, private or link-local address are rejected unless the server allows private targets.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Subscribe a webhook",
+                "parameters": [
+                    {
+                        "description": "Subscription to create",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookSubscription"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making retries of the request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid subscription, or a URL that is not public",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "description": "Get a webhook subscription by UUID, without its secret",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get a webhook subscription by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid subscription ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Unsubscribe a webhook and drop its delivery log",
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete a webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making retries of the request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Subscription deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid subscription ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "List a subscription's deliveries, newest first, with their status, attempts, last error and next attempt. A failed delivery is retried with exponential backoff and marked dead after the configured number of attempts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get the delivery log of a webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "delivered",
+                            "dead"
+                        ],
+                        "type": "string",
+                        "description": "Only deliveries with this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of deliveries (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid subscription ID, status or limit",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries/{deliveryID}/retry": {
+            "post": {
+                "description": "Schedule a delivery, typically a dead one, for a new round of attempts starting now",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Retry a webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery UUID",
+                        "name": "deliveryID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making retries of the request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid subscription or delivery ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription or delivery not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "in.GraphQLRequest": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "{ products(limit: 10) { items { id name packs { size } } hasMore } }"
+                },
+                "variables": {
+                    "type": "object"
+                }
+            }
+        },
+        "in.InfeasibleResponse": {
+            "type": "object",
+            "properties": {
+                "constraints": {
+                    "description": "Constraints that blocked every plan",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "min_overage": {
+                    "description": "Smallest overage any plan achieves",
+                    "type": "integer"
+                },
+                "min_packs": {
+                    "description": "Fewest packs any plan uses",
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "infeasible"
+                }
+            }
+        },
+        "in.OrderPlanRequest": {
+            "type": "object",
+            "properties": {
+                "customer_id": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.OrderLine"
+                    }
+                },
+                "max_overage": {
+                    "type": "integer"
+                },
+                "max_overage_pct": {
+                    "type": "number"
+                },
+                "max_packs": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "over",
+                        "under",
+                        "exact_only"
+                    ]
+                }
+            }
+        },
+        "in.PackMeasurements": {
+            "type": "object",
+            "properties": {
+                "dimensions": {
+                    "$ref": "#/definitions/model.Dimensions"
+                },
+                "weight_g": {
+                    "type": "integer",
+                    "example": 1200
+                }
+            }
+        },
+        "in.PlanFailureResponse": {
+            "type": "object",
+            "properties": {
+                "constraints": {
+                    "description": "Constraints that blocked every plan, if infeasible",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "line": {
+                    "description": "Order line at fault, from POST /orders/plan",
+                    "type": "integer",
+                    "example": 2
+                },
+                "min_overage": {
+                    "description": "Smallest overage any plan achieves, if infeasible",
+                    "type": "integer"
+                },
+                "min_packs": {
+                    "description": "Fewest packs any plan uses, if infeasible",
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "infeasible",
+                        "unshippable"
+                    ]
+                }
+            }
+        },
+        "in.ShipmentPlanRequest": {
+            "type": "object",
+            "properties": {
+                "customer_id": {
+                    "type": "string"
+                },
+                "max_overage": {
+                    "type": "integer"
+                },
+                "max_overage_pct": {
+                    "type": "number"
+                },
+                "max_packs": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "over",
+                        "under",
+                        "exact_only"
+                    ]
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 251
+                }
+            }
+        },
+        "model.Carton": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "inner_dimensions": {
+                    "$ref": "#/definitions/model.Dimensions"
+                },
+                "max_weight_g": {
+                    "description": "heaviest load the carton takes, in grams",
+                    "type": "integer",
+                    "example": 20000
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Medium box"
+                }
+            }
+        },
+        "model.Customer": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pack_rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PackRule"
+                    }
+                }
+            }
+        },
+        "model.Dimensions": {
+            "type": "object",
+            "properties": {
+                "height_mm": {
+                    "type": "integer",
+                    "example": 150
+                },
+                "length_mm": {
+                    "type": "integer",
+                    "example": 300
+                },
+                "width_mm": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "model.Pack": {
+            "type": "object",
+            "properties": {
+                "dimensions": {
+                    "$ref": "#/definitions/model.Dimensions"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "weight_g": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.PackRule": {
+            "type": "object",
+            "properties": {
+                "allowed_sizes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "forbidden_sizes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "max_pack_size": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Product": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sku": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "body": {
+                    "description": "the event",
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "response_status": {
+                    "description": "HTTP status of the last attempt",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.WebhookSubscription": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.CatalogImportReport": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.CatalogRowResult"
+                    }
+                },
+                "unchanged": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.CatalogRowResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                },
+                "sku": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.Explanation": {
+            "type": "object",
+            "properties": {
+                "gcd": {
+                    "description": "GCD of the pack sizes; only multiples of it can be shipped exactly.",
+                    "type": "integer"
+                },
+                "largest_unreachable": {
+                    "description": "LargestUnreachable is the largest quantity no combination ships exactly (the Frobenius number). It is\nnull when the GCD is above 1, as infinitely many quantities are then unreachable, and when every\nquantity is reachable.",
+                    "type": "integer"
+                },
+                "overage": {
+                    "type": "integer"
+                },
+                "pack_count": {
+                    "type": "integer"
+                },
+                "runners_up": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.RejectedPlan"
+                    }
+                },
+                "solver": {
+                    "$ref": "#/definitions/service.SolverStats"
+                }
+            }
+        },
+        "service.OrderLine": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 751
+                }
+            }
+        },
+        "service.OrderLinePlan": {
+            "type": "object",
+            "properties": {
+                "overage": {
+                    "type": "integer",
+                    "example": 249
+                },
+                "pack_count": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "packs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ShippedPack"
+                    }
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "requested_quantity": {
+                    "type": "integer",
+                    "example": 751
+                },
+                "shortfall": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "total_items": {
+                    "type": "integer",
+                    "example": 1000
+                }
+            }
+        },
+        "service.OrderPlan": {
+            "type": "object",
+            "properties": {
+                "carton_count": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.OrderLinePlan"
+                    }
+                },
+                "pack_count": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "shipment_count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "shipments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.PlannedShipment"
+                    }
+                },
+                "total_weight_g": {
+                    "type": "integer",
+                    "example": 9400
+                }
+            }
         },
         "service.PackFulfillmentResult": {
             "type": "object",
             "properties": {
+                "explanation": {
+                    "description": "set when FulfillmentOptions.Explain is requested",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Explanation"
+                        }
+                    ]
+                },
                 "packs": {
                     "description": "pack size -\u003e count",
                     "type": "object",
@@ -351,10 +2288,210 @@ const docTemplate = `{
                         "type": "integer"
                     }
                 },
+                "shortfall": {
+                    "description": "items short of the quantity, in under mode",
+                    "type": "integer"
+                },
                 "totalItems": {
                     "type": "integer"
                 }
             }
+        },
+        "service.PlannedCarton": {
+            "type": "object",
+            "properties": {
+                "carton_id": {
+                    "type": "string"
+                },
+                "fill_ratio": {
+                    "description": "share of the carton's volume taken by the packs, to 3 decimals",
+                    "type": "number",
+                    "example": 0.625
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Medium box"
+                },
+                "packs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ShippedPack"
+                    }
+                },
+                "weight_g": {
+                    "description": "of the packs",
+                    "type": "integer",
+                    "example": 5200
+                }
+            }
+        },
+        "service.PlannedShipment": {
+            "type": "object",
+            "properties": {
+                "carton_count": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "cartons": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.PlannedCarton"
+                    }
+                },
+                "pack_count": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ShippedProduct"
+                    }
+                },
+                "volume_cm3": {
+                    "description": "inner volume of the cartons, rounded up",
+                    "type": "integer",
+                    "example": 54000
+                },
+                "weight_g": {
+                    "description": "of the packs",
+                    "type": "integer",
+                    "example": 9400
+                }
+            }
+        },
+        "service.RejectedPlan": {
+            "type": "object",
+            "properties": {
+                "pack_count": {
+                    "type": "integer"
+                },
+                "packs": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "total_items": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ShipmentPlan": {
+            "type": "object",
+            "properties": {
+                "carton_count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "cartons": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.PlannedCarton"
+                    }
+                },
+                "overage": {
+                    "type": "integer",
+                    "example": 249
+                },
+                "pack_count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "requested_quantity": {
+                    "type": "integer",
+                    "example": 251
+                },
+                "shortfall": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "total_items": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "total_weight_g": {
+                    "type": "integer",
+                    "example": 5200
+                }
+            }
+        },
+        "service.ShippedPack": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "pack_id": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 500
+                }
+            }
+        },
+        "service.ShippedProduct": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "product_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.SolverStats": {
+            "type": "object",
+            "properties": {
+                "elapsed_ms": {
+                    "type": "number"
+                },
+                "states_explored": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.TableStatus": {
+            "type": "object",
+            "properties": {
+                "build_duration_ms": {
+                    "type": "integer"
+                },
+                "built_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "max_quantity": {
+                    "type": "integer"
+                },
+                "pack_sizes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -363,7 +2500,7 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
 	Host:             "localhost:8080",
-	BasePath:         "/",
+	BasePath:         "/v1",
 	Schemes:          []string{"http"},
 	Title:            "Order Fulfillment API",
 	Description:      "REST API for product and pack management with optimal order fulfillment",
@@ -0,0 +1,178 @@
+package in
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+// CreateCartonHandler godoc
+// @Summary Create a carton
+// @Description Add a shipping carton to the catalog used to plan shipments. Inner dimensions are in millimetres and the maximum weight of its load in grams.
+// @Tags Cartons
+// @Accept json
+// @Produce json
+// @Param carton body model.Carton true "Carton to create"
+// @Param Idempotency-Key header string false "Unique key making retries of the request return the original response"
+// @Success 201 {object} model.Carton
+// @Failure 400 {string} string "Invalid carton"
+// @Failure 500 {string} string "Internal server error"
+// @Router /cartons [post]
+func CreateCartonHandler(svc *service.CartonService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var c model.Carton
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			slog.Error("Failed to decode carton", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := svc.Create(r.Context(), &c); err != nil {
+			slog.Error("Failed to create carton", "error", err)
+			writeCartonError(w, err)
+			return
+		}
+		slog.Info("Carton created", "carton", c)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(c)
+	}
+}
+
+// GetCartonHandler godoc
+// @Summary Get a carton by ID
+// @Description Get a shipping carton by UUID
+// @Tags Cartons
+// @Produce json
+// @Param id path string true "Carton UUID"
+// @Success 200 {object} model.Carton
+// @Failure 400 {string} string "Invalid carton ID"
+// @Failure 404 {string} string "Carton not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /cartons/{id} [get]
+func GetCartonHandler(svc *service.CartonService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			slog.Error("Invalid carton ID", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		c, err := svc.GetByID(r.Context(), id)
+		if err != nil {
+			slog.Error("Failed to get carton", "id", id, "error", err)
+			writeCartonError(w, err)
+			return
+		}
+		slog.Info("Carton retrieved", "carton", c)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(c)
+	}
+}
+
+// UpdateCartonHandler godoc
+// @Summary Update a carton
+// @Description Replace a shipping carton's name, inner dimensions and maximum weight
+// @Tags Cartons
+// @Accept json
+// @Produce json
+// @Param id path string true "Carton UUID"
+// @Param carton body model.Carton true "Carton with its new dimensions"
+// @Param Idempotency-Key header string false "Unique key making retries of the request return the original response"
+// @Success 200 {object} model.Carton
+// @Failure 400 {string} string "Invalid carton"
+// @Failure 404 {string} string "Carton not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /cartons/{id} [put]
+func UpdateCartonHandler(svc *service.CartonService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			slog.Error("Invalid carton ID", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var c model.Carton
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			slog.Error("Failed to decode carton", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		c.ID = id
+		if err := svc.Update(r.Context(), &c); err != nil {
+			slog.Error("Failed to update carton", "id", id, "error", err)
+			writeCartonError(w, err)
+			return
+		}
+		slog.Info("Carton updated", "carton", c)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(c)
+	}
+}
+
+// DeleteCartonHandler godoc
+// @Summary Delete a carton
+// @Description Remove a shipping carton from the catalog
+// @Tags Cartons
+// @Param id path string true "Carton UUID"
+// @Param Idempotency-Key header string false "Unique key making retries of the request return the original response"
+// @Success 204 {string} string "Carton deleted"
+// @Failure 400 {string} string "Invalid carton ID"
+// @Failure 404 {string} string "Carton not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /cartons/{id} [delete]
+func DeleteCartonHandler(svc *service.CartonService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			slog.Error("Invalid carton ID", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := svc.Delete(r.Context(), id); err != nil {
+			slog.Error("Failed to delete carton", "id", id, "error", err)
+			writeCartonError(w, err)
+			return
+		}
+		slog.Info("Carton deleted", "id", id)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// ListCartonsHandler godoc
+// @Summary List cartons
+// @Description Get the catalog of shipping cartons
+// @Tags Cartons
+// @Produce json
+// @Success 200 {array} model.Carton
+// @Failure 500 {string} string "Internal server error"
+// @Router /cartons [get]
+func ListCartonsHandler(svc *service.CartonService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cartons, err := svc.List(r.Context())
+		if err != nil {
+			slog.Error("Failed to list cartons", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		slog.Info("Cartons listed", "count", len(cartons))
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(cartons)
+	}
+}
+
+// writeCartonError maps carton service errors to status codes.
+func writeCartonError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCarton):
+		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, port.ErrCartonNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
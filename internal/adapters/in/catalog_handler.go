@@ -0,0 +1,299 @@
+package in
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+// Catalog file formats.
+const (
+	catalogFormatCSV    = "csv"
+	catalogFormatNDJSON = "ndjson"
+)
+
+// maxCatalogImportBytes bounds the size of an import request body.
+const maxCatalogImportBytes = 32 << 20
+
+// catalogCSVHeader is the column layout of exported CSV files. Imports accept the columns in any order;
+// id and sku are optional. Pack sizes are separated by semicolons, e.g. "250;500;1000".
+var catalogCSVHeader = []string{"id", "sku", "name", "pack_sizes"}
+
+// ImportCatalogHandler godoc
+// @Summary Import the catalog
+// @Description Upserts products and their pack sizes from CSV (columns id, sku, name, pack_sizes with sizes separated by ";") or NDJSON ({"sku", "name", "pack_sizes"} per line). Rows are matched by SKU, then ID, then name. Invalid rows are reported and skipped.
+// @Tags Catalog
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param format query string false "Input format: csv or ndjson (defaults to the Content-Type)"
+// @Param dry_run query bool false "Validate and report without writing"
+// @Param Idempotency-Key header string false "Unique key making retries of the request return the original response"
+// @Success 200 {object} service.CatalogImportReport
+// @Failure 400 {string} string "Unreadable or unsupported input, or invalid dry_run"
+// @Failure 500 {string} string "Internal server error"
+// @Router /catalog/import [post]
+func ImportCatalogHandler(svc *service.CatalogService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			format = catalogFormatFromMediaType(mediaType)
+		}
+		var dryRun bool
+		if v := r.URL.Query().Get("dry_run"); v != "" {
+			var err error
+			if dryRun, err = strconv.ParseBool(v); err != nil {
+				slog.Error("Invalid dry_run", "dry_run", v, "error", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+
+		body := http.MaxBytesReader(w, r.Body, maxCatalogImportBytes)
+		var rows []service.CatalogRow
+		var failed []service.CatalogRowResult
+		var err error
+		switch format {
+		case catalogFormatCSV:
+			rows, failed, err = decodeCatalogCSV(body)
+		case catalogFormatNDJSON:
+			rows, failed, err = decodeCatalogNDJSON(body)
+		default:
+			err = fmt.Errorf("unsupported format %q", format)
+		}
+		if err != nil {
+			slog.Error("Failed to read catalog import", "format", format, "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		report, err := svc.Import(r.Context(), rows, dryRun)
+		if err != nil {
+			slog.Error("Failed to import catalog", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		for _, f := range failed {
+			report.Add(f)
+		}
+		slices.SortFunc(report.Rows, func(a, b service.CatalogRowResult) int { return a.Row - b.Row })
+
+		slog.Info("Catalog imported", "dry_run", dryRun, "created", report.Created, "updated", report.Updated,
+			"unchanged", report.Unchanged, "failed", report.Failed)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+// ExportCatalogHandler godoc
+// @Summary Export the catalog
+// @Description Streams every product with its pack sizes, ordered by name, as CSV or NDJSON in the import format
+// @Tags Catalog
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "Output format: csv or ndjson (default ndjson, or negotiated from Accept)"
+// @Success 200 {string} string "Catalog rows"
+// @Failure 400 {string} string "Unsupported format"
+// @Router /catalog/export [get]
+func ExportCatalogHandler(svc *service.CatalogService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = catalogFormatFromMediaType(r.Header.Get("Accept"))
+			if format == "" {
+				format = catalogFormatNDJSON
+			}
+		}
+
+		rc := http.NewResponseController(w)
+		var write func(model.CatalogProduct) error
+		var flush func() error
+		switch format {
+		case catalogFormatCSV:
+			w.Header().Set("Content-Type", "text/csv")
+			cw := csv.NewWriter(w)
+			cw.Write(catalogCSVHeader)
+			write = func(p model.CatalogProduct) error {
+				return cw.Write([]string{p.ID.String(), p.SKU, p.Name, formatPackSizes(p.PackSizes)})
+			}
+			flush = func() error {
+				cw.Flush()
+				return cw.Error()
+			}
+		case catalogFormatNDJSON:
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			enc := json.NewEncoder(w)
+			write = func(p model.CatalogProduct) error { return enc.Encode(p) }
+			flush = func() error { return nil }
+		default:
+			slog.Error("Unsupported catalog export format", "format", format)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="catalog.%s"`, format))
+		w.WriteHeader(http.StatusOK)
+
+		count := 0
+		err := svc.ExportEach(r.Context(), func(p model.CatalogProduct) error {
+			if err := write(p); err != nil {
+				return err
+			}
+			count++
+			if count%100 == 0 {
+				if err := flush(); err != nil {
+					return err
+				}
+				rc.Flush()
+			}
+			return nil
+		})
+		if err == nil {
+			err = flush()
+		}
+		if err != nil {
+			// Headers are already sent; the client sees a truncated stream.
+			slog.Error("Failed to export catalog", "exported", count, "error", err)
+			return
+		}
+		slog.Info("Catalog exported", "format", format, "count", count)
+	}
+}
+
+// catalogFormatFromMediaType maps a Content-Type or Accept value to a catalog format, or "" if unknown.
+func catalogFormatFromMediaType(mediaType string) string {
+	switch {
+	case strings.Contains(mediaType, "text/csv"):
+		return catalogFormatCSV
+	case strings.Contains(mediaType, "ndjson"):
+		return catalogFormatNDJSON
+	}
+	return ""
+}
+
+// decodeCatalogCSV reads catalog rows from CSV with a header line. Rows that cannot be parsed are
+// returned as failed results, numbered by their line in the file.
+func decodeCatalogCSV(r io.Reader) ([]service.CatalogRow, []service.CatalogRowResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("read header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"name", "pack_sizes"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("missing %q column", required)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []service.CatalogRow
+	var failed []service.CatalogRowResult
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, err
+			}
+			failed = append(failed, service.CatalogRowResult{Row: parseErr.StartLine, Action: service.CatalogActionError, Errors: []string{parseErr.Err.Error()}})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		product := model.CatalogProduct{SKU: field(record, "sku"), Name: field(record, "name")}
+		var errs []string
+		if id := field(record, "id"); id != "" {
+			if product.ID, err = uuid.Parse(id); err != nil {
+				errs = append(errs, fmt.Sprintf("invalid id %q", id))
+			}
+		}
+		if product.PackSizes, err = parsePackSizeList(field(record, "pack_sizes")); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if len(errs) > 0 {
+			failed = append(failed, service.CatalogRowResult{Row: line, SKU: product.SKU, Name: product.Name, Action: service.CatalogActionError, Errors: errs})
+			continue
+		}
+		rows = append(rows, service.CatalogRow{Row: line, Product: product})
+	}
+	return rows, failed, nil
+}
+
+// decodeCatalogNDJSON reads one JSON catalog product per line. Blank lines are skipped.
+func decodeCatalogNDJSON(r io.Reader) ([]service.CatalogRow, []service.CatalogRowResult, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var rows []service.CatalogRow
+	var failed []service.CatalogRowResult
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var product model.CatalogProduct
+		if err := json.Unmarshal([]byte(text), &product); err != nil {
+			failed = append(failed, service.CatalogRowResult{Row: line, Action: service.CatalogActionError, Errors: []string{err.Error()}})
+			continue
+		}
+		rows = append(rows, service.CatalogRow{Row: line, Product: product})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return rows, failed, nil
+}
+
+// parsePackSizeList parses pack sizes separated by semicolons.
+func parsePackSizeList(s string) ([]int, error) {
+	var sizes []int
+	for _, field := range strings.Split(s, ";") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		size, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pack size %q", field)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+func formatPackSizes(sizes []int) string {
+	parts := make([]string, len(sizes))
+	for i, size := range sizes {
+		parts[i] = strconv.Itoa(size)
+	}
+	return strings.Join(parts, ";")
+}
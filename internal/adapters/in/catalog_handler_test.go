@@ -0,0 +1,109 @@
+package in
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+func newTestCatalogService() *service.CatalogService {
+	return &service.CatalogService{
+		Products: &service.ProductService{Repo: out.NewProductRepositoryMem()},
+		Packs:    &service.PackService{Repo: out.NewPackRepositoryMem()},
+	}
+}
+
+func TestImportCatalogHandler_CSVReportsRowErrors(t *testing.T) {
+	svc := newTestCatalogService()
+	body := "sku,name,pack_sizes\nA1,Shoes,250;500\n,Hats,x\nB2,Socks,3;5\n"
+	req := httptest.NewRequest(http.MethodPost, "/catalog/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	rec := httptest.NewRecorder()
+
+	ImportCatalogHandler(svc)(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var report service.CatalogImportReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if report.Created != 2 || report.Failed != 1 {
+		t.Fatalf("expected 2 created and 1 failed, got %+v", report)
+	}
+	if len(report.Rows) != 3 || report.Rows[1].Row != 3 || report.Rows[1].Action != service.CatalogActionError {
+		t.Errorf("expected row 3 to be reported as an error, got %+v", report.Rows)
+	}
+}
+
+func TestImportCatalogHandler_DryRunWritesNothing(t *testing.T) {
+	svc := newTestCatalogService()
+	body := `{"sku":"A1","name":"Shoes","pack_sizes":[250,500]}` + "\n"
+	req := httptest.NewRequest(http.MethodPost, "/catalog/import?format=ndjson&dry_run=true", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	ImportCatalogHandler(svc)(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	catalog, err := svc.Export(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(catalog) != 0 {
+		t.Errorf("expected an empty catalog after a dry run, got %v", catalog)
+	}
+}
+
+func TestImportCatalogHandler_InvalidDryRunWritesNothing(t *testing.T) {
+	svc := newTestCatalogService()
+	body := `{"sku":"A1","name":"Shoes","pack_sizes":[250,500]}` + "\n"
+	req := httptest.NewRequest(http.MethodPost, "/catalog/import?format=ndjson&dry_run=yes-please", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	ImportCatalogHandler(svc)(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+	catalog, err := svc.Export(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(catalog) != 0 {
+		t.Errorf("expected an empty catalog after a rejected import, got %v", catalog)
+	}
+}
+
+func TestExportCatalogHandler_RoundTripsThroughImport(t *testing.T) {
+	svc := newTestCatalogService()
+	body := "sku,name,pack_sizes\nA1,Shoes,250;500\nB2,Socks,3;5\n"
+	req := httptest.NewRequest(http.MethodPost, "/catalog/import?format=csv", strings.NewReader(body))
+	ImportCatalogHandler(svc)(httptest.NewRecorder(), req)
+
+	rec := httptest.NewRecorder()
+	ExportCatalogHandler(svc)(rec, httptest.NewRequest(http.MethodGet, "/catalog/export?format=csv", nil))
+	if got := rec.Header().Get("Content-Type"); got != "text/csv" {
+		t.Fatalf("expected text/csv, got %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/catalog/import?format=csv", rec.Body)
+	rec = httptest.NewRecorder()
+	ImportCatalogHandler(svc)(rec, req)
+
+	var report service.CatalogImportReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if report.Unchanged != 2 || report.Created+report.Updated+report.Failed != 0 {
+		t.Errorf("expected re-importing the export to change nothing, got %+v", report)
+	}
+}
@@ -0,0 +1,59 @@
+// Package cli reads the inputs shared by the command-line tools in cmd.
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+)
+
+// ParsePackSizes parses a comma-separated list of pack sizes. Whether they are valid is up to the services
+// using them.
+func ParsePackSizes(s string) ([]int, error) {
+	var sizes []int
+	for field := range strings.SplitSeq(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		size, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pack size %q", field)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// ReadCatalog reads a catalog snapshot: either a JSON array of products, as written by
+// "admin catalog export", or NDJSON with a product per line, as served by GET /catalog/export.
+func ReadCatalog(r io.Reader) ([]model.CatalogProduct, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var catalog []model.CatalogProduct
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, err
+		}
+		return catalog, nil
+	}
+	var catalog []model.CatalogProduct
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for n := 1; ; n++ {
+		var p model.CatalogProduct
+		if err := dec.Decode(&p); errors.Is(err, io.EOF) {
+			return catalog, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("product %d: %w", n, err)
+		}
+		catalog = append(catalog, p)
+	}
+}
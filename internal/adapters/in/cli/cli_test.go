@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+)
+
+func TestParsePackSizes(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []int
+		wantErr bool
+	}{
+		{in: "250,500,1000", want: []int{250, 500, 1000}},
+		{in: " 23, 31 ,53,", want: []int{23, 31, 53}},
+		{in: "", want: nil},
+		{in: "250,0", want: []int{250, 0}},
+		{in: "250,abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePackSizes(tt.in)
+		if (err != nil) != tt.wantErr || !slices.Equal(got, tt.want) {
+			t.Errorf("ParsePackSizes(%q) = %v, %v; want %v, error %t", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestReadCatalog(t *testing.T) {
+	want := []model.CatalogProduct{
+		{ID: uuid.MustParse("46585d03-5df1-4c9c-b6a0-d36b22a49cb4"), SKU: "SHOE-001", Name: "Generic Shoes", PackSizes: []int{250, 500, 1000, 2000, 5000}},
+		{ID: uuid.MustParse("1d06bb00-aa69-45dc-9816-e9379fdf4c99"), Name: "Socks", PackSizes: []int{23, 31, 53}},
+	}
+	// catalog_export.ndjson is the output of GET /catalog/export and catalog_export.json the same catalog as
+	// written by "admin catalog export".
+	for _, name := range []string{"testdata/catalog_export.ndjson", "testdata/catalog_export.json"} {
+		t.Run(name, func(t *testing.T) {
+			f, err := os.Open(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			got, err := ReadCatalog(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	if _, err := ReadCatalog(strings.NewReader(`{"name":"Shoes","pack_sizes":[250]}` + "\n{not json}\n")); err == nil || !strings.Contains(err.Error(), "product 2") {
+		t.Errorf("expected an error naming product 2, got %v", err)
+	}
+}
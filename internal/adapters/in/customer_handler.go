@@ -0,0 +1,178 @@
+package in
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+// CreateCustomerHandler godoc
+// @Summary Create a customer
+// @Description Create a customer with optional pack rules restricting the pack sizes used for its orders. A rule without product_id applies to every product that has no rule of its own.
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param customer body model.Customer true "Customer to create"
+// @Param Idempotency-Key header string false "Unique key making retries of the request return the original response"
+// @Success 201 {object} model.Customer
+// @Failure 400 {string} string "Invalid customer"
+// @Failure 500 {string} string "Internal server error"
+// @Router /customers [post]
+func CreateCustomerHandler(svc *service.CustomerService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var c model.Customer
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			slog.Error("Failed to decode customer", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := svc.Create(r.Context(), &c); err != nil {
+			slog.Error("Failed to create customer", "error", err)
+			writeCustomerError(w, err)
+			return
+		}
+		slog.Info("Customer created", "customer", c)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(c)
+	}
+}
+
+// GetCustomerHandler godoc
+// @Summary Get a customer by ID
+// @Description Get a customer and its pack rules by UUID
+// @Tags Customers
+// @Produce json
+// @Param id path string true "Customer UUID"
+// @Success 200 {object} model.Customer
+// @Failure 400 {string} string "Invalid customer ID"
+// @Failure 404 {string} string "Customer not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /customers/{id} [get]
+func GetCustomerHandler(svc *service.CustomerService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			slog.Error("Invalid customer ID", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		c, err := svc.GetByID(r.Context(), id)
+		if err != nil {
+			slog.Error("Failed to get customer", "id", id, "error", err)
+			writeCustomerError(w, err)
+			return
+		}
+		slog.Info("Customer retrieved", "customer", c)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(c)
+	}
+}
+
+// UpdateCustomerHandler godoc
+// @Summary Update a customer
+// @Description Replace a customer's name and pack rules
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer UUID"
+// @Param customer body model.Customer true "Customer with its new pack rules"
+// @Param Idempotency-Key header string false "Unique key making retries of the request return the original response"
+// @Success 200 {object} model.Customer
+// @Failure 400 {string} string "Invalid customer"
+// @Failure 404 {string} string "Customer not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /customers/{id} [put]
+func UpdateCustomerHandler(svc *service.CustomerService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			slog.Error("Invalid customer ID", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var c model.Customer
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			slog.Error("Failed to decode customer", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		c.ID = id
+		if err := svc.Update(r.Context(), &c); err != nil {
+			slog.Error("Failed to update customer", "id", id, "error", err)
+			writeCustomerError(w, err)
+			return
+		}
+		slog.Info("Customer updated", "customer", c)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(c)
+	}
+}
+
+// DeleteCustomerHandler godoc
+// @Summary Delete a customer
+// @Description Delete a customer and its pack rules
+// @Tags Customers
+// @Param id path string true "Customer UUID"
+// @Param Idempotency-Key header string false "Unique key making retries of the request return the original response"
+// @Success 204 {string} string "Customer deleted"
+// @Failure 400 {string} string "Invalid customer ID"
+// @Failure 404 {string} string "Customer not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /customers/{id} [delete]
+func DeleteCustomerHandler(svc *service.CustomerService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			slog.Error("Invalid customer ID", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := svc.Delete(r.Context(), id); err != nil {
+			slog.Error("Failed to delete customer", "id", id, "error", err)
+			writeCustomerError(w, err)
+			return
+		}
+		slog.Info("Customer deleted", "id", id)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// ListCustomersHandler godoc
+// @Summary List customers
+// @Description Get all customers with their pack rules
+// @Tags Customers
+// @Produce json
+// @Success 200 {array} model.Customer
+// @Failure 500 {string} string "Internal server error"
+// @Router /customers [get]
+func ListCustomersHandler(svc *service.CustomerService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		customers, err := svc.List(r.Context())
+		if err != nil {
+			slog.Error("Failed to list customers", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		slog.Info("Customers listed", "count", len(customers))
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(customers)
+	}
+}
+
+// writeCustomerError maps customer service errors to status codes.
+func writeCustomerError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCustomer):
+		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, port.ErrCustomerNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
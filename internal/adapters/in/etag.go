@@ -0,0 +1,48 @@
+package in
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// entityTag formats a resource version as a strong entity tag.
+func entityTag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// notModified reports whether r's If-None-Match header matches etag, comparing weakly as RFC 9110 requires.
+func notModified(r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	return matchesAny(header, func(tag string) bool {
+		return strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/")
+	})
+}
+
+// hasIfMatch reports whether r makes its update conditional on an If-Match header.
+func hasIfMatch(r *http.Request) bool {
+	return r.Header.Get("If-Match") != ""
+}
+
+// ifMatches reports whether r's If-Match header matches etag, comparing strongly as RFC 9110 requires.
+func ifMatches(r *http.Request, etag string) bool {
+	return matchesAny(r.Header.Get("If-Match"), func(tag string) bool {
+		return !strings.HasPrefix(tag, "W/") && tag == etag
+	})
+}
+
+// matchesAny reports whether header is "*" or lists an entity tag for which match is true.
+func matchesAny(header string, match func(tag string) bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for tag := range strings.SplitSeq(header, ",") {
+		if match(strings.TrimSpace(tag)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package in
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+// eventStreamHeartbeat is how often an idle event stream sends a comment, keeping proxies from closing it.
+const eventStreamHeartbeat = 15 * time.Second
+
+// EventStreamHandler godoc
+// @Summary Stream domain events
+// @Description Stream domain events as Server-Sent Events, each with the event type as its event name, the event as JSON data and its position in the stream as ID. A client reconnecting with a Last-Event-ID header (or the last_event_id query parameter) first receives the events it missed that are still buffered; a ": missed events" comment announces that some have already left the buffer.
+// @Tags Events
+// @Produce text/event-stream
+// @Param product_id query string false "Only events about this product UUID"
+// @Param type query []string false "Only events of these types" collectionFormat(csv)
+// @Param Last-Event-ID header string false "ID of the last event received, to resume after it"
+// @Param last_event_id query string false "Alternative to the Last-Event-ID header"
+// @Success 200 {string} string "Stream of events"
+// @Failure 400 {string} string "Invalid product ID, event type or last event ID"
+// @Router /events [get]
+func EventStreamHandler(stream *service.EventStream) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, after, err := parseEventStreamRequest(r)
+		if err != nil {
+			slog.Error("Invalid event stream request", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		sub, backlog, complete := stream.Subscribe(filter, after)
+		defer sub.Close()
+
+		// The stream outlives the server's write timeout.
+		rc := http.NewResponseController(w)
+		_ = rc.SetWriteDeadline(time.Time{})
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if !complete {
+			fmt.Fprint(w, ": missed events\n\n")
+		}
+		for _, e := range backlog {
+			if err := writeStreamedEvent(w, e); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			slog.Error("Failed to flush event stream", "error", err)
+			return
+		}
+		slog.Info("Event stream opened", "product_id", filter.ProductID, "types", filter.Types, "after", after, "backlog", len(backlog))
+		streamEvents(r.Context(), w, rc, sub)
+		slog.Info("Event stream closed", "product_id", filter.ProductID, "types", filter.Types)
+	}
+}
+
+// streamEvents writes the subscription's events as they come until the client goes away or the
+// subscription is closed for falling behind, in which case the client reconnects and resumes.
+func streamEvents(ctx context.Context, w http.ResponseWriter, rc *http.ResponseController, sub *service.EventSubscription) {
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if err := writeStreamedEvent(w, e); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeStreamedEvent writes e as a Server-Sent Event.
+func writeStreamedEvent(w http.ResponseWriter, e service.StreamedEvent) error {
+	data, err := json.Marshal(e.Event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Seq, e.Type, data)
+	return err
+}
+
+// parseEventStreamRequest reads the filter and the position to resume after from r.
+func parseEventStreamRequest(r *http.Request) (filter service.EventFilter, after uint64, err error) {
+	q := r.URL.Query()
+	if s := q.Get("product_id"); s != "" {
+		if filter.ProductID, err = uuid.Parse(s); err != nil {
+			return filter, 0, fmt.Errorf("product_id: %w", err)
+		}
+	}
+	for _, v := range q["type"] {
+		for _, t := range strings.Split(v, ",") {
+			if !slices.Contains(model.EventTypes, t) {
+				return filter, 0, fmt.Errorf("unknown event type %q", t)
+			}
+			filter.Types = append(filter.Types, t)
+		}
+	}
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = q.Get("last_event_id")
+	}
+	if lastID != "" {
+		if after, err = strconv.ParseUint(lastID, 10, 64); err != nil {
+			return filter, 0, fmt.Errorf("last event ID: %w", err)
+		}
+	}
+	return filter, after, nil
+}
@@ -0,0 +1,71 @@
+package in
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+func TestEventStreamHandler_ResumesAndStreams(t *testing.T) {
+	stream := service.NewEventStream(10)
+	productID := uuid.New()
+	publish := func(eventType string) {
+		event, err := model.NewEvent(eventType, productID, nil, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := stream.Publish(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	publish(model.EventProductCreated)
+	publish(model.EventPacksReplaced)
+	publish(model.EventProductUpdated)
+
+	srv := httptest.NewServer(EventStreamHandler(stream))
+	defer srv.Close()
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"?type=PacksReplaced,ProductUpdated&product_id="+productID.String(), nil)
+	req.Header.Set("Last-Event-ID", "2")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %q", ct)
+	}
+
+	publish(model.EventProductDeleted) // filtered out
+	publish(model.EventPacksReplaced)
+
+	var got []string
+	lines := bufio.NewScanner(resp.Body)
+	for len(got) < 4 && lines.Scan() {
+		if line := lines.Text(); strings.HasPrefix(line, "id: ") || strings.HasPrefix(line, "event: ") {
+			got = append(got, line)
+		}
+	}
+	want := []string{"id: 3", "event: ProductUpdated", "id: 5", "event: PacksReplaced"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestEventStreamHandler_RejectsInvalidRequests(t *testing.T) {
+	handler := EventStreamHandler(service.NewEventStream(10))
+	for _, target := range []string{"/events?product_id=nope", "/events?type=OrderShipped", "/events?last_event_id=-1"} {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", target, http.StatusBadRequest, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package in
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+// Response schema versions of GET /fulfill. Version 1 is service.PackFulfillmentResult as is, with a Packs
+// object keyed by pack size; version 2 is FulfillmentResponse.
+const (
+	FulfillmentSchemaLegacy  = 1
+	FulfillmentSchemaCurrent = 2
+)
+
+// FulfillmentResponse is the version 2 response of GET /fulfill. Packs are ordered by size, largest first.
+type FulfillmentResponse struct {
+	SchemaVersion     int                     `json:"schema_version" example:"2"`
+	ProductID         uuid.UUID               `json:"product_id"`
+	RequestedQuantity int                     `json:"requested_quantity" example:"251"`
+	TotalItems        int                     `json:"total_items" example:"500"`
+	Overage           int                     `json:"overage" example:"249"`
+	Shortfall         int                     `json:"shortfall" example:"0"`
+	PackCount         int                     `json:"pack_count" example:"1"`
+	Packs             []FulfilledPack         `json:"packs"`
+	Explanation       *FulfillmentExplanation `json:"explanation,omitempty"`
+}
+
+// FulfilledPack is one pack of a product and how many of it a plan ships.
+type FulfilledPack struct {
+	PackID   uuid.UUID `json:"pack_id"`
+	Size     int       `json:"size" example:"500"`
+	Count    int       `json:"count" example:"1"`
+	Subtotal int       `json:"subtotal" example:"500"`
+}
+
+// FulfillmentExplanation is service.Explanation with its plans' packs ordered like FulfillmentResponse's.
+type FulfillmentExplanation struct {
+	RunnersUp          []RejectedPlanResponse `json:"runners_up"`
+	GCD                int                    `json:"gcd" example:"250"`
+	LargestUnreachable *int                   `json:"largest_unreachable"`
+	Solver             service.SolverStats    `json:"solver"`
+}
+
+// RejectedPlanResponse is a plan the solver rejected, and why.
+type RejectedPlanResponse struct {
+	TotalItems int             `json:"total_items"`
+	PackCount  int             `json:"pack_count"`
+	Packs      []FulfilledPack `json:"packs"`
+	Reason     string          `json:"reason"`
+}
+
+// parseSchemaVersion reads the schema_version query parameter, defaulting to the legacy version so that
+// existing clients keep their response shape until they opt in to the current one.
+func parseSchemaVersion(v string) (int, error) {
+	if v == "" {
+		return FulfillmentSchemaLegacy, nil
+	}
+	version, err := strconv.Atoi(v)
+	if err != nil || version < FulfillmentSchemaLegacy || version > FulfillmentSchemaCurrent {
+		return 0, fmt.Errorf("unsupported schema_version %q", v)
+	}
+	return version, nil
+}
+
+// newFulfillmentResponse converts result for productID's order of quantity into the version 2 schema,
+// identifying each pack size by the product's pack of that size.
+func newFulfillmentResponse(productID uuid.UUID, quantity int, result service.PackFulfillmentResult, packs []*model.Pack) FulfillmentResponse {
+	packIDs := make(map[int]uuid.UUID, len(packs))
+	for _, p := range packs {
+		if _, ok := packIDs[p.Size]; !ok {
+			packIDs[p.Size] = p.ID
+		}
+	}
+	resp := FulfillmentResponse{
+		SchemaVersion:     FulfillmentSchemaCurrent,
+		ProductID:         productID,
+		RequestedQuantity: quantity,
+		TotalItems:        result.TotalItems,
+		Overage:           max(result.TotalItems-quantity, 0),
+		Shortfall:         result.Shortfall,
+		PackCount:         result.PackCount(),
+		Packs:             fulfilledPacks(result.Packs, packIDs),
+	}
+	if e := result.Explanation; e != nil {
+		resp.Explanation = &FulfillmentExplanation{
+			RunnersUp:          make([]RejectedPlanResponse, 0, len(e.RunnersUp)),
+			GCD:                e.GCD,
+			LargestUnreachable: e.LargestUnreachable,
+			Solver:             e.Solver,
+		}
+		for _, plan := range e.RunnersUp {
+			resp.Explanation.RunnersUp = append(resp.Explanation.RunnersUp, RejectedPlanResponse{
+				TotalItems: plan.TotalItems,
+				PackCount:  plan.PackCount,
+				Packs:      fulfilledPacks(plan.Packs, packIDs),
+				Reason:     plan.Reason,
+			})
+		}
+	}
+	return resp
+}
+
+// fulfilledPacks turns a pack size to count map into FulfilledPacks, largest size first.
+func fulfilledPacks(counts map[int]int, packIDs map[int]uuid.UUID) []FulfilledPack {
+	packs := make([]FulfilledPack, 0, len(counts))
+	for size, count := range counts {
+		packs = append(packs, FulfilledPack{PackID: packIDs[size], Size: size, Count: count, Subtotal: size * count})
+	}
+	slices.SortFunc(packs, func(a, b FulfilledPack) int { return b.Size - a.Size })
+	return packs
+}
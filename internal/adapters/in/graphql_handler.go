@@ -0,0 +1,227 @@
+package in
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Costs of the complexity limit. Every field costs 1, times the number of items its enclosing lists may
+// hold: a products page holds its limit, and a list of packs is assumed to hold graphQLPacksPerProduct.
+// fulfill runs the solver and costs graphQLFulfillCost on top.
+const (
+	graphQLPacksPerProduct = 10
+	graphQLFulfillCost     = 10
+	maxGraphQLRequestBytes = 1 << 20
+)
+
+// GraphQLLimits bound the queries GraphQLHandler executes; a zero limit is no limit.
+type GraphQLLimits struct {
+	MaxDepth        int // Deepest nesting of fields
+	MaxComplexity   int // Highest estimated cost, see graphQLPacksPerProduct
+	MaxFulfillments int // Most fulfill fields, each of which runs the solver, including aliases and fragments
+}
+
+// GraphQLRequest is a GraphQL query with its operation name and variables.
+type GraphQLRequest struct {
+	Query         string         `json:"query" example:"{ products(limit: 10) { items { id name packs { size } } hasMore } }"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty" swaggertype:"object"`
+}
+
+// GraphQLHandler godoc
+// @Summary Execute a GraphQL query or mutation
+// @Description Executes a GraphQL request against the product, pack and fulfillment schema: the queries product, products (paginated, with packs nested under each product) and fulfill(productId, quantity), and the mutations createProduct, updateProduct, deleteProduct and replacePacks. Errors are reported in the response's errors with a code extension such as NOT_FOUND, CONFLICT, BAD_USER_INPUT or INFEASIBLE. Queries nested deeper, estimated costlier or with more fulfill fields than the configured limits are rejected with QUERY_TOO_DEEP, QUERY_TOO_COMPLEX or TOO_MANY_FULFILLMENTS before running. Requests draw on the per-client rate limit of GET /fulfill. GET accepts the query, operationName and variables query parameters, for queries only.
+// @Tags GraphQL
+// @Accept json
+// @Produce json
+// @Param request body GraphQLRequest true "GraphQL request"
+// @Success 200 {object} object "GraphQL response with data and errors"
+// @Failure 400 {string} string "Malformed request or missing query"
+// @Failure 405 {string} string "Mutation sent with GET"
+// @Router /graphql [post]
+func GraphQLHandler(schema graphql.Schema, limits GraphQLLimits) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req GraphQLRequest
+		if r.Method == http.MethodGet {
+			query := r.URL.Query()
+			req.Query, req.OperationName = query.Get("query"), query.Get("operationName")
+			if v := query.Get("variables"); v != "" {
+				if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+					slog.Error("Invalid GraphQL variables", "error", err)
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+			}
+		} else if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLRequestBytes)).Decode(&req); err != nil {
+			slog.Error("Failed to decode GraphQL request", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if req.Query == "" {
+			slog.Error("Missing GraphQL query")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"})})
+		if err != nil {
+			writeGraphQLResult(w, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+			return
+		}
+		if v := graphql.ValidateDocument(&schema, doc, nil); !v.IsValid {
+			writeGraphQLResult(w, &graphql.Result{Errors: v.Errors})
+			return
+		}
+		if op := graphQLOperation(doc, req.OperationName); op != nil {
+			if r.Method == http.MethodGet && op.Operation != ast.OperationTypeQuery {
+				slog.Error("GraphQL mutation sent with GET")
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if err := checkGraphQLLimits(doc, op, req.Variables, limits); err != nil {
+				slog.Warn("GraphQL query rejected", "error", err)
+				writeGraphQLResult(w, &graphql.Result{Errors: []gqlerrors.FormattedError{
+					gqlerrors.FormatError(&gqlerrors.Error{Message: err.Error(), OriginalError: err}),
+				}})
+				return
+			}
+		}
+		result := graphql.Execute(graphql.ExecuteParams{
+			Schema:        schema,
+			AST:           doc,
+			OperationName: req.OperationName,
+			Args:          req.Variables,
+			Context:       r.Context(),
+		})
+		writeGraphQLResult(w, result)
+	}
+}
+
+func writeGraphQLResult(w http.ResponseWriter, result *graphql.Result) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// graphQLOperation returns the operation of doc named name, or its only operation if name is empty. It
+// returns nil when there is no such operation, which graphql.Execute then reports.
+func graphQLOperation(doc *ast.Document, name string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			if found != nil {
+				return nil
+			}
+			found = op
+		} else if op.Name != nil && op.Name.Value == name {
+			return op
+		}
+	}
+	return found
+}
+
+// checkGraphQLLimits estimates the depth and complexity of op. Validation has already ensured that its
+// fragments exist and do not form cycles.
+func checkGraphQLLimits(doc *ast.Document, op *ast.OperationDefinition, variables map[string]any, limits GraphQLLimits) error {
+	c := graphQLCost{fragments: map[string]*ast.FragmentDefinition{}, variables: variables}
+	for _, def := range doc.Definitions {
+		if f, ok := def.(*ast.FragmentDefinition); ok {
+			c.fragments[f.Name.Value] = f
+		}
+	}
+	depth, complexity := c.selectionSet(op.SelectionSet, 1)
+	if limits.MaxFulfillments > 0 && c.fulfillments > limits.MaxFulfillments {
+		return &graphQLError{code: "TOO_MANY_FULFILLMENTS", err: fmt.Errorf("query has %d fulfill fields, above the limit of %d", c.fulfillments, limits.MaxFulfillments)}
+	}
+	if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+		return &graphQLError{code: "QUERY_TOO_DEEP", err: fmt.Errorf("query depth %d exceeds the limit of %d", depth, limits.MaxDepth)}
+	}
+	if limits.MaxComplexity > 0 && complexity > limits.MaxComplexity {
+		return &graphQLError{code: "QUERY_TOO_COMPLEX", err: fmt.Errorf("query complexity %d exceeds the limit of %d", complexity, limits.MaxComplexity)}
+	}
+	return nil
+}
+
+type graphQLCost struct {
+	fragments    map[string]*ast.FragmentDefinition
+	variables    map[string]any
+	fulfillments int // fulfill fields seen
+}
+
+// selectionSet returns the depth and complexity of set, whose fields are at the given depth.
+func (c *graphQLCost) selectionSet(set *ast.SelectionSet, depth int) (maxDepth, complexity int) {
+	if set == nil {
+		return depth - 1, 0
+	}
+	maxDepth = depth - 1
+	for _, sel := range set.Selections {
+		var d, n int
+		switch sel := sel.(type) {
+		case *ast.Field:
+			d, n = c.field(sel, depth)
+		case *ast.InlineFragment:
+			d, n = c.selectionSet(sel.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			if f := c.fragments[sel.Name.Value]; f != nil {
+				d, n = c.selectionSet(f.SelectionSet, depth)
+			}
+		}
+		maxDepth, complexity = max(maxDepth, d), complexity+n
+	}
+	return maxDepth, complexity
+}
+
+func (c *graphQLCost) field(f *ast.Field, depth int) (maxDepth, complexity int) {
+	if strings.HasPrefix(f.Name.Value, "__") {
+		return depth, 0 // introspection, which clients and tools issue with deep but cheap queries
+	}
+	maxDepth, complexity = c.selectionSet(f.SelectionSet, depth+1)
+	maxDepth = max(maxDepth, depth)
+	switch f.Name.Value {
+	case "products":
+		complexity *= max(c.intArgument(f, "limit", defaultGraphQLPageSize), 1)
+	case "packs":
+		complexity *= graphQLPacksPerProduct
+	case "fulfill":
+		complexity += graphQLFulfillCost
+		c.fulfillments++
+	}
+	return maxDepth, complexity + 1
+}
+
+// intArgument returns the integer argument name of f, given literally or as a variable, or def if absent.
+func (c *graphQLCost) intArgument(f *ast.Field, name string, def int) int {
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != name {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil {
+				return n
+			}
+		case *ast.Variable:
+			switch n := c.variables[v.Name.Value].(type) {
+			case float64:
+				return int(n)
+			case int:
+				return n
+			}
+		}
+	}
+	return def
+}
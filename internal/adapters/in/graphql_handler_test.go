@@ -0,0 +1,173 @@
+package in
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+// graphQLTestResponse is a GraphQL response with the error fields the tests check. graphql-go returns
+// objects as maps, so their fields are encoded in alphabetical order.
+type graphQLTestResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+// newTestGraphQLHandler returns a GraphQLHandler over in-memory services.
+func newTestGraphQLHandler(t *testing.T, limits GraphQLLimits) http.Handler {
+	t.Helper()
+	packSvc := &service.PackService{Repo: out.NewPackRepositoryMem()}
+	schema, err := NewGraphQLSchema(GraphQLServices{
+		Products:    &service.ProductService{Repo: out.NewProductRepositoryMem()},
+		Packs:       packSvc,
+		Fulfillment: &service.PackFulfillmentService{Packs: packSvc},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return GraphQLHandler(schema, limits)
+}
+
+// doGraphQL posts query with variables to h and decodes the response.
+func doGraphQL(t *testing.T, h http.Handler, query string, variables map[string]any) graphQLTestResponse {
+	t.Helper()
+	body, _ := json.Marshal(GraphQLRequest{Query: query, Variables: variables})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var resp graphQLTestResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode %q: %v", rec.Body.String(), err)
+	}
+	return resp
+}
+
+// errorCode returns the code extension of the response's first error, or "" if it has none.
+func (r graphQLTestResponse) errorCode() string {
+	if len(r.Errors) == 0 {
+		return ""
+	}
+	code, _ := r.Errors[0].Extensions["code"].(string)
+	return code
+}
+
+func TestGraphQLHandler_ProductsPacksAndFulfill(t *testing.T) {
+	h := newTestGraphQLHandler(t, GraphQLLimits{})
+
+	var ids []string
+	for _, name := range []string{"Socks", "Hats", "Shoes"} {
+		resp := doGraphQL(t, h, `mutation($name: String!) { createProduct(name: $name) { id version } }`, map[string]any{"name": name})
+		if len(resp.Errors) > 0 {
+			t.Fatalf("createProduct: %+v", resp.Errors)
+		}
+		var created struct{ ID string }
+		json.Unmarshal(resp.Data["createProduct"], &created)
+		ids = append(ids, created.ID)
+	}
+	resp := doGraphQL(t, h, `mutation($id: ID!) { replacePacks(productId: $id, sizes: [250, 500, 1000]) { packs { size } packsVersion } }`, map[string]any{"id": ids[2]})
+	if got := string(resp.Data["replacePacks"]); got != `{"packs":[{"size":250},{"size":500},{"size":1000}],"packsVersion":2}` {
+		t.Fatalf("replacePacks = %s, errors %+v", got, resp.Errors)
+	}
+	resp = doGraphQL(t, h, `mutation($id: ID!) { replacePacks(productId: $id, sizes: [5], expectedVersion: 1) { id } }`, map[string]any{"id": ids[2]})
+	if resp.errorCode() != "CONFLICT" {
+		t.Errorf("stale replacePacks error = %+v, want CONFLICT", resp.Errors)
+	}
+
+	resp = doGraphQL(t, h, `{ products(offset: 1, limit: 1) { items { name packs { size } } totalCount hasMore } }`, nil)
+	if got := string(resp.Data["products"]); got != `{"hasMore":true,"items":[{"name":"Shoes","packs":[{"size":250},{"size":500},{"size":1000}]}],"totalCount":3}` {
+		t.Errorf("products = %s, errors %+v", got, resp.Errors)
+	}
+
+	resp = doGraphQL(t, h, `query($id: ID!) { fulfill(productId: $id, quantity: 251) { totalItems overage packs { size count } } }`, map[string]any{"id": ids[2]})
+	if got := string(resp.Data["fulfill"]); got != `{"overage":249,"packs":[{"count":1,"size":500}],"totalItems":500}` {
+		t.Errorf("fulfill = %s, errors %+v", got, resp.Errors)
+	}
+	resp = doGraphQL(t, h, `query($id: ID!) { fulfill(productId: $id, quantity: 251, mode: EXACT_ONLY) { totalItems } }`, map[string]any{"id": ids[2]})
+	if resp.errorCode() != "INFEASIBLE" {
+		t.Errorf("exact fulfill error = %+v, want INFEASIBLE", resp.Errors)
+	}
+
+	resp = doGraphQL(t, h, `mutation($id: ID!) { deleteProduct(id: $id) }`, map[string]any{"id": ids[0]})
+	if got := string(resp.Data["deleteProduct"]); got != "true" {
+		t.Errorf("deleteProduct = %s, errors %+v", got, resp.Errors)
+	}
+	resp = doGraphQL(t, h, `query($id: ID!) { product(id: $id) { name } }`, map[string]any{"id": ids[0]})
+	if got := string(resp.Data["product"]); got != "null" || len(resp.Errors) > 0 {
+		t.Errorf("deleted product = %s, errors %+v, want null", got, resp.Errors)
+	}
+	resp = doGraphQL(t, h, `mutation($id: ID!) { updateProduct(id: $id, name: "Caps") { name } }`, map[string]any{"id": ids[0]})
+	if resp.errorCode() != "NOT_FOUND" {
+		t.Errorf("updateProduct of deleted product error = %+v, want NOT_FOUND", resp.Errors)
+	}
+}
+
+func TestGraphQLHandler_Limits(t *testing.T) {
+	h := newTestGraphQLHandler(t, GraphQLLimits{MaxDepth: 3, MaxComplexity: 100, MaxFulfillments: 2})
+
+	// products > items > packs > size is 4 fields deep.
+	tests := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{"within limits", `{ products(limit: 5) { items { name } } }`, ""},
+		{"too deep", `{ products { items { packs { size } } } }`, "QUERY_TOO_DEEP"},
+		{"too deep through fragment", `{ products(limit: 1) { items { ...P } } } fragment P on Product { packs { size } }`, "QUERY_TOO_DEEP"},
+		{"too complex", `{ products(limit: 50) { items { id name } } }`, "QUERY_TOO_COMPLEX"},
+		{"too complex through variable", `query($n: Int) { products(limit: $n) { items { id name } } }`, "QUERY_TOO_COMPLEX"},
+		{"too many fulfillments through aliases", `{ a: fulfill(productId: "x", quantity: 1) { totalItems } b: fulfill(productId: "x", quantity: 2) { totalItems } c: fulfill(productId: "x", quantity: 3) { totalItems } }`, "TOO_MANY_FULFILLMENTS"},
+		{"too many fulfillments through fragments", `{ ...F ...F ...F } fragment F on Query { fulfill(productId: "x", quantity: 1) { totalItems } }`, "TOO_MANY_FULFILLMENTS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doGraphQL(t, h, tt.query, map[string]any{"n": 50})
+			if got := resp.errorCode(); got != tt.code {
+				t.Errorf("error code = %q (%+v), want %q", got, resp.Errors, tt.code)
+			}
+		})
+	}
+}
+
+func TestGraphQLHandler_StorageErrorsAreInternal(t *testing.T) {
+	packSvc := &service.PackService{Repo: &mockPackRepository{err: errors.New("connection refused")}}
+	schema, err := NewGraphQLSchema(GraphQLServices{
+		Products:    &service.ProductService{Repo: out.NewProductRepositoryMem()},
+		Packs:       packSvc,
+		Fulfillment: &service.PackFulfillmentService{Packs: packSvc},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := doGraphQL(t, GraphQLHandler(schema, GraphQLLimits{}), `query($id: ID!) { fulfill(productId: $id, quantity: 251) { totalItems } }`, map[string]any{"id": uuid.NewString()})
+	if got := resp.errorCode(); got != "INTERNAL" {
+		t.Errorf("error code = %q (%+v), want INTERNAL", got, resp.Errors)
+	}
+}
+
+func TestGraphQLHandler_GetRejectsMutations(t *testing.T) {
+	h := newTestGraphQLHandler(t, GraphQLLimits{})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`mutation { createProduct(name: "x") { id } }`), nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET mutation status = %d, want 405", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`{ products { totalCount } }`), nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"data":{"products":{"totalCount":0}}}`+"\n" {
+		t.Errorf("GET query = %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,410 @@
+package in
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+// Page sizes of the products query.
+const (
+	defaultGraphQLPageSize = 20
+	maxGraphQLPageSize     = 100
+)
+
+// GraphQLServices are the services behind the GraphQL schema.
+type GraphQLServices struct {
+	Products    *service.ProductService
+	Packs       *service.PackService
+	Fulfillment *service.PackFulfillmentService
+}
+
+// graphQLError is a resolver error whose code is reported in the error's extensions, so that clients can
+// tell a missing product from a version conflict without parsing messages.
+type graphQLError struct {
+	code string
+	err  error
+	ext  map[string]any
+}
+
+func (e *graphQLError) Error() string { return e.err.Error() }
+
+func (e *graphQLError) Extensions() map[string]any {
+	ext := map[string]any{"code": e.code}
+	for k, v := range e.ext {
+		ext[k] = v
+	}
+	return ext
+}
+
+// graphQLErrorOf logs err and classifies it like the REST handlers do with status codes.
+func graphQLErrorOf(msg string, err error) error {
+	slog.Error(msg, "error", err)
+	var infeasible *service.InfeasibleError
+	switch {
+	case errors.As(err, &infeasible):
+		return &graphQLError{code: "INFEASIBLE", err: err, ext: map[string]any{
+			"constraints": infeasible.Constraints,
+			"minOverage":  infeasible.MinOverage,
+			"minPacks":    infeasible.MinPacks,
+		}}
+	case errors.Is(err, port.ErrProductNotFound), errors.Is(err, port.ErrCustomerNotFound):
+		return &graphQLError{code: "NOT_FOUND", err: err}
+	case errors.Is(err, port.ErrDuplicateSKU), errors.Is(err, port.ErrVersionConflict):
+		return &graphQLError{code: "CONFLICT", err: err}
+	case errors.Is(err, service.ErrInvalidOptions), errors.Is(err, service.ErrInvalidPackSizes):
+		return &graphQLError{code: "BAD_USER_INPUT", err: err}
+	case errors.Is(err, service.ErrNoAcceptedPackSizes):
+		return &graphQLError{code: "INFEASIBLE", err: err}
+	case errors.Is(err, service.ErrSolverBusy):
+		return &graphQLError{code: "TOO_MANY_REQUESTS", err: err}
+	case errors.Is(err, service.ErrSolverBudgetExceeded):
+		return &graphQLError{code: "SOLVER_TIMEOUT", err: err}
+	default:
+		return &graphQLError{code: "INTERNAL", err: errors.New("internal error")}
+	}
+}
+
+func badUserInput(format string, args ...any) error {
+	return &graphQLError{code: "BAD_USER_INPUT", err: fmt.Errorf(format, args...)}
+}
+
+// NewGraphQLSchema returns the GraphQL schema of the catalog and fulfillment:
+//
+//	query {
+//	  product(id) { id name sku version packs { id size } packsVersion }
+//	  products(offset, limit) { items { ... } totalCount hasMore }
+//	  fulfill(productId, quantity, customerId, mode, maxOverage, maxOveragePct, maxPacks) { ... packs { packId size count subtotal } }
+//	}
+//	mutation { createProduct updateProduct deleteProduct replacePacks }
+func NewGraphQLSchema(svc GraphQLServices) (graphql.Schema, error) {
+	r := &graphQLResolvers{svc}
+
+	packType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Pack",
+		Fields: graphql.Fields{
+			"id":   &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(*model.Pack).ID.String(), nil }},
+			"size": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(*model.Pack).Size, nil }},
+		},
+	})
+	productType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Product",
+		Fields: graphql.Fields{
+			"id":      &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(*model.Product).ID.String(), nil }},
+			"name":    &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(*model.Product).Name, nil }},
+			"sku":     &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(*model.Product).SKU, nil }},
+			"version": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(*model.Product).Version, nil }},
+			"packs": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(packType))),
+				Description: "The product's packs",
+				Resolve:     r.packs,
+			},
+			"packsVersion": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "Version of the product's pack set, to pass as expectedVersion to replacePacks",
+				Resolve:     r.packsVersion,
+			},
+		},
+	})
+	productPageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ProductPage",
+		Fields: graphql.Fields{
+			"items":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(productType)))},
+			"totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"hasMore":    &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		},
+	})
+	fulfilledPackType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "FulfilledPack",
+		Fields: graphql.Fields{
+			"packId":   &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(FulfilledPack).PackID.String(), nil }},
+			"size":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(FulfilledPack).Size, nil }},
+			"count":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(FulfilledPack).Count, nil }},
+			"subtotal": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(FulfilledPack).Subtotal, nil }},
+		},
+	})
+	fulfillmentType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Fulfillment",
+		Fields: graphql.Fields{
+			"productId": &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(FulfillmentResponse).ProductID.String(), nil
+			}},
+			"requestedQuantity": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(FulfillmentResponse).RequestedQuantity, nil
+			}},
+			"totalItems": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(FulfillmentResponse).TotalItems, nil }},
+			"overage":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(FulfillmentResponse).Overage, nil }},
+			"shortfall":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(FulfillmentResponse).Shortfall, nil }},
+			"packCount":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(FulfillmentResponse).PackCount, nil }},
+			"packs": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(fulfilledPackType))),
+				Description: "Packs to ship, largest size first",
+				Resolve:     func(p graphql.ResolveParams) (any, error) { return p.Source.(FulfillmentResponse).Packs, nil },
+			},
+		},
+	})
+	modeType := graphql.NewEnum(graphql.EnumConfig{
+		Name: "FulfillmentMode",
+		Values: graphql.EnumValueConfigMap{
+			"OVER":       &graphql.EnumValueConfig{Value: service.ModeOver, Description: "Ship at least the quantity"},
+			"UNDER":      &graphql.EnumValueConfig{Value: service.ModeUnder, Description: "Ship the most items not exceeding the quantity"},
+			"EXACT_ONLY": &graphql.EnumValueConfig{Value: service.ModeExactOnly, Description: "Ship exactly the quantity or fail"},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"product": &graphql.Field{
+				Type:        productType,
+				Description: "A product by ID, or null if there is none",
+				Args:        graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve:     r.product,
+			},
+			"products": &graphql.Field{
+				Type:        graphql.NewNonNull(productPageType),
+				Description: fmt.Sprintf("A page of products ordered by name; limit is at most %d", maxGraphQLPageSize),
+				Args: graphql.FieldConfigArgument{
+					"offset": {Type: graphql.Int, DefaultValue: 0},
+					"limit":  {Type: graphql.Int, DefaultValue: defaultGraphQLPageSize},
+				},
+				Resolve: r.products,
+			},
+			"fulfill": &graphql.Field{
+				Type:        graphql.NewNonNull(fulfillmentType),
+				Description: "The optimal combination of packs for an order, like GET /v1/fulfill",
+				Args: graphql.FieldConfigArgument{
+					"productId":     {Type: graphql.NewNonNull(graphql.ID)},
+					"quantity":      {Type: graphql.NewNonNull(graphql.Int)},
+					"customerId":    {Type: graphql.ID},
+					"mode":          {Type: modeType},
+					"maxOverage":    {Type: graphql.Int},
+					"maxOveragePct": {Type: graphql.Float},
+					"maxPacks":      {Type: graphql.Int},
+				},
+				Resolve: r.fulfill,
+			},
+		},
+	})
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createProduct": &graphql.Field{
+				Type: graphql.NewNonNull(productType),
+				Args: graphql.FieldConfigArgument{
+					"name": {Type: graphql.NewNonNull(graphql.String)},
+					"sku":  {Type: graphql.String},
+				},
+				Resolve: r.createProduct,
+			},
+			"updateProduct": &graphql.Field{
+				Type:        graphql.NewNonNull(productType),
+				Description: "Replace a product's name and SKU, provided it is still at expectedVersion when given",
+				Args: graphql.FieldConfigArgument{
+					"id":              {Type: graphql.NewNonNull(graphql.ID)},
+					"name":            {Type: graphql.NewNonNull(graphql.String)},
+					"sku":             {Type: graphql.String},
+					"expectedVersion": {Type: graphql.Int},
+				},
+				Resolve: r.updateProduct,
+			},
+			"deleteProduct": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id, err := uuidArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					if err := r.svc.Products.Delete(p.Context, id); err != nil {
+						return nil, graphQLErrorOf("Failed to delete product", err)
+					}
+					slog.Info("Product deleted", "id", id)
+					return true, nil
+				},
+			},
+			"replacePacks": &graphql.Field{
+				Type:        graphql.NewNonNull(productType),
+				Description: "Replace a product's packs, provided its pack set is still at expectedVersion when given",
+				Args: graphql.FieldConfigArgument{
+					"productId":       {Type: graphql.NewNonNull(graphql.ID)},
+					"sizes":           {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.Int)))},
+					"expectedVersion": {Type: graphql.Int},
+				},
+				Resolve: r.replacePacks,
+			},
+		},
+	})
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+type graphQLResolvers struct {
+	svc GraphQLServices
+}
+
+// productPage is the source of ProductPage.
+type productPage struct {
+	Items      []*model.Product `json:"items"`
+	TotalCount int              `json:"totalCount"`
+	HasMore    bool             `json:"hasMore"`
+}
+
+func (r *graphQLResolvers) product(p graphql.ResolveParams) (any, error) {
+	id, err := uuidArg(p, "id")
+	if err != nil {
+		return nil, err
+	}
+	product, err := r.svc.Products.GetByID(p.Context, id)
+	if errors.Is(err, port.ErrProductNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, graphQLErrorOf("Failed to get product", err)
+	}
+	return product, nil
+}
+
+func (r *graphQLResolvers) products(p graphql.ResolveParams) (any, error) {
+	offset, _ := p.Args["offset"].(int)
+	limit, _ := p.Args["limit"].(int)
+	if offset < 0 || limit < 0 || limit > maxGraphQLPageSize {
+		return nil, badUserInput("offset must not be negative and limit must be between 0 and %d", maxGraphQLPageSize)
+	}
+	products, err := r.svc.Products.List(p.Context)
+	if err != nil {
+		return nil, graphQLErrorOf("Failed to list products", err)
+	}
+	// Repositories list products in no particular order; pages need a stable one.
+	slices.SortFunc(products, func(a, b *model.Product) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID.String(), b.ID.String()))
+	})
+	page := productPage{TotalCount: len(products)}
+	start := min(offset, len(products))
+	end := min(start+limit, len(products))
+	page.Items, page.HasMore = products[start:end], end < len(products)
+	return page, nil
+}
+
+func (r *graphQLResolvers) packs(p graphql.ResolveParams) (any, error) {
+	productID := p.Source.(*model.Product).ID
+	packs, err := r.svc.Packs.ListByProduct(p.Context, productID)
+	if err != nil {
+		return nil, graphQLErrorOf("Failed to list packs", err)
+	}
+	slices.SortFunc(packs, func(a, b *model.Pack) int { return a.Size - b.Size })
+	return packs, nil
+}
+
+func (r *graphQLResolvers) packsVersion(p graphql.ResolveParams) (any, error) {
+	set, err := r.svc.Packs.GetPackSet(p.Context, p.Source.(*model.Product).ID)
+	if err != nil {
+		return nil, graphQLErrorOf("Failed to get packs", err)
+	}
+	return set.Version, nil
+}
+
+func (r *graphQLResolvers) fulfill(p graphql.ResolveParams) (any, error) {
+	productID, err := uuidArg(p, "productId")
+	if err != nil {
+		return nil, err
+	}
+	quantity := p.Args["quantity"].(int)
+	opts := service.FulfillmentOptions{}
+	opts.Mode, _ = p.Args["mode"].(string)
+	if v, ok := p.Args["maxOverage"].(int); ok {
+		opts.MaxOverage = &v
+	}
+	if v, ok := p.Args["maxPacks"].(int); ok {
+		opts.MaxPacks = &v
+	}
+	if v, ok := p.Args["maxOveragePct"].(float64); ok {
+		opts.MaxOveragePct = &v
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, graphQLErrorOf("Invalid fulfillment constraints", err)
+	}
+
+	var customerID uuid.UUID
+	if _, ok := p.Args["customerId"]; ok {
+		if customerID, err = uuidArg(p, "customerId"); err != nil {
+			return nil, err
+		}
+	}
+	result, packs, err := r.svc.Fulfillment.FulfillForCustomer(p.Context, productID, quantity, customerID, opts)
+	if err != nil {
+		return nil, graphQLErrorOf("Pack fulfillment failed", err)
+	}
+	slog.Info("Pack fulfillment result", "product_id", productID, "quantity", quantity, "result", result)
+	return newFulfillmentResponse(productID, quantity, result, packs), nil
+}
+
+func (r *graphQLResolvers) createProduct(p graphql.ResolveParams) (any, error) {
+	product := &model.Product{Name: p.Args["name"].(string)}
+	product.SKU, _ = p.Args["sku"].(string)
+	if err := r.svc.Products.Create(p.Context, product); err != nil {
+		return nil, graphQLErrorOf("Failed to create product", err)
+	}
+	slog.Info("Product created", "product", product)
+	return product, nil
+}
+
+func (r *graphQLResolvers) updateProduct(p graphql.ResolveParams) (any, error) {
+	id, err := uuidArg(p, "id")
+	if err != nil {
+		return nil, err
+	}
+	product := &model.Product{ID: id, Name: p.Args["name"].(string)}
+	product.SKU, _ = p.Args["sku"].(string)
+	if v, ok := p.Args["expectedVersion"].(int); ok {
+		product.Version = int64(v)
+	}
+	if err := r.svc.Products.Update(p.Context, product); err != nil {
+		return nil, graphQLErrorOf("Failed to update product", err)
+	}
+	slog.Info("Product updated", "product", product)
+	return product, nil
+}
+
+func (r *graphQLResolvers) replacePacks(p graphql.ResolveParams) (any, error) {
+	productID, err := uuidArg(p, "productId")
+	if err != nil {
+		return nil, err
+	}
+	var sizes []int
+	for _, v := range p.Args["sizes"].([]any) {
+		sizes = append(sizes, v.(int))
+	}
+	var expected int64
+	if v, ok := p.Args["expectedVersion"].(int); ok {
+		expected = int64(v)
+	}
+	product, err := r.svc.Products.GetByID(p.Context, productID)
+	if err != nil {
+		return nil, graphQLErrorOf("Failed to get product", err)
+	}
+	set, err := r.svc.Packs.ReplacePackSet(p.Context, productID, sizes, expected)
+	if err != nil {
+		return nil, graphQLErrorOf("Failed to update packs", err)
+	}
+	slog.Info("Packs updated", "product_id", productID, "count", len(set.Packs), "version", set.Version)
+	return product, nil
+}
+
+// uuidArg parses the UUID argument name.
+func uuidArg(p graphql.ResolveParams, name string) (uuid.UUID, error) {
+	s, _ := p.Args[name].(string)
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil, badUserInput("invalid %s: %v", name, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,299 @@
+package in
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	pb "github.com/rlpaul93/order-fulfillment/api/proto/orderfulfillment/v1"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+// GRPCServer implements the OrderFulfillmentService gRPC API over the same services as the REST handlers.
+type GRPCServer struct {
+	pb.UnimplementedOrderFulfillmentServiceServer
+
+	Products    *service.ProductService
+	Packs       *service.PackService
+	Fulfillment *service.PackFulfillmentService
+}
+
+func (s *GRPCServer) CreateProduct(ctx context.Context, req *pb.CreateProductRequest) (*pb.Product, error) {
+	p := &model.Product{Name: req.GetName(), SKU: req.GetSku()}
+	if err := s.Products.Create(ctx, p); err != nil {
+		return nil, grpcError("Failed to create product", err)
+	}
+	slog.Info("Product created", "product", p)
+	return productToProto(p), nil
+}
+
+func (s *GRPCServer) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.Product, error) {
+	id, err := parseGRPCID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.Products.GetByID(ctx, id)
+	if err != nil {
+		return nil, grpcError("Failed to get product", err)
+	}
+	return productToProto(p), nil
+}
+
+func (s *GRPCServer) ListProducts(ctx context.Context, _ *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
+	products, err := s.Products.List(ctx)
+	if err != nil {
+		return nil, grpcError("Failed to list products", err)
+	}
+	resp := &pb.ListProductsResponse{Products: make([]*pb.Product, 0, len(products))}
+	for _, p := range products {
+		resp.Products = append(resp.Products, productToProto(p))
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) UpdateProduct(ctx context.Context, req *pb.UpdateProductRequest) (*pb.Product, error) {
+	id, err := parseGRPCID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	p := &model.Product{ID: id, Name: req.GetName(), SKU: req.GetSku(), Version: req.GetExpectedVersion()}
+	if err := s.Products.Update(ctx, p); err != nil {
+		return nil, grpcError("Failed to update product", err)
+	}
+	slog.Info("Product updated", "product", p)
+	return productToProto(p), nil
+}
+
+func (s *GRPCServer) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
+	id, err := parseGRPCID("id", req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Products.Delete(ctx, id); err != nil {
+		return nil, grpcError("Failed to delete product", err)
+	}
+	slog.Info("Product deleted", "id", id)
+	return &pb.DeleteProductResponse{}, nil
+}
+
+func (s *GRPCServer) GetPacks(ctx context.Context, req *pb.GetPacksRequest) (*pb.PackSet, error) {
+	productID, err := parseGRPCID("product_id", req.GetProductId())
+	if err != nil {
+		return nil, err
+	}
+	set, err := s.Packs.GetPackSet(ctx, productID)
+	if err != nil {
+		return nil, grpcError("Failed to get packs", err)
+	}
+	return packSetToProto(set), nil
+}
+
+func (s *GRPCServer) ReplacePacks(ctx context.Context, req *pb.ReplacePacksRequest) (*pb.PackSet, error) {
+	productID, err := parseGRPCID("product_id", req.GetProductId())
+	if err != nil {
+		return nil, err
+	}
+	sizes := make([]int, 0, len(req.GetSizes()))
+	for _, size := range req.GetSizes() {
+		sizes = append(sizes, int(size))
+	}
+	set, err := s.Packs.ReplacePackSet(ctx, productID, sizes, req.GetExpectedVersion())
+	if err != nil {
+		return nil, grpcError("Failed to update packs", err)
+	}
+	slog.Info("Packs updated", "product_id", productID, "count", len(set.Packs), "version", set.Version)
+	return packSetToProto(set), nil
+}
+
+func (s *GRPCServer) Fulfill(ctx context.Context, req *pb.FulfillRequest) (*pb.FulfillResponse, error) {
+	return s.fulfill(ctx, req)
+}
+
+// orderLimitKey is the context key of the function BatchFulfill calls before each order.
+type orderLimitKey struct{}
+
+// WithOrderLimit returns a copy of ctx in which BatchFulfill calls allow before each order of the stream. An
+// order allow fails is not fulfilled; its error is reported in the order's response and the stream goes on.
+func WithOrderLimit(ctx context.Context, allow func(context.Context) error) context.Context {
+	return context.WithValue(ctx, orderLimitKey{}, allow)
+}
+
+// BatchFulfill answers every order of the stream in turn; an order's failure is reported in its response.
+func (s *GRPCServer) BatchFulfill(stream pb.OrderFulfillmentService_BatchFulfillServer) error {
+	allow, _ := stream.Context().Value(orderLimitKey{}).(func(context.Context) error)
+	for n := 0; ; n++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			slog.Info("Batch fulfillment finished", "orders", n)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		resp := &pb.BatchFulfillResponse{RequestId: req.GetRequestId()}
+		var result *pb.FulfillResponse
+		if allow != nil {
+			err = allow(stream.Context())
+		}
+		if err == nil {
+			result, err = s.fulfill(stream.Context(), req)
+		}
+		if err != nil {
+			st := status.Convert(err)
+			batchErr := &pb.BatchFulfillError{Code: int32(st.Code()), Message: st.Message()}
+			for _, detail := range st.Details() {
+				if infeasible, ok := detail.(*pb.Infeasible); ok {
+					batchErr.Infeasible = infeasible
+				}
+			}
+			resp.Outcome = &pb.BatchFulfillResponse_Error{Error: batchErr}
+		} else {
+			resp.Outcome = &pb.BatchFulfillResponse_Result{Result: result}
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// fulfill calculates an order like PackFulfillmentHandler.
+func (s *GRPCServer) fulfill(ctx context.Context, req *pb.FulfillRequest) (*pb.FulfillResponse, error) {
+	productID, err := parseGRPCID("product_id", req.GetProductId())
+	if err != nil {
+		return nil, err
+	}
+	quantity := int(req.GetQuantity())
+	opts, err := fulfillmentOptionsFromProto(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	var customerID uuid.UUID
+	if req.GetCustomerId() != "" {
+		if customerID, err = parseGRPCID("customer_id", req.GetCustomerId()); err != nil {
+			return nil, err
+		}
+	}
+	result, packs, err := s.Fulfillment.FulfillForCustomer(ctx, productID, quantity, customerID, opts)
+	if err != nil {
+		return nil, grpcError("Pack fulfillment failed", err)
+	}
+	slog.Info("Pack fulfillment result", "product_id", productID, "quantity", quantity, "result", result)
+	return fulfillmentToProto(newFulfillmentResponse(productID, quantity, result, packs)), nil
+}
+
+// fulfillmentOptionsFromProto reads the mode and constraints of req.
+func fulfillmentOptionsFromProto(req *pb.FulfillRequest) (service.FulfillmentOptions, error) {
+	var opts service.FulfillmentOptions
+	switch req.GetMode() {
+	case pb.FulfillmentMode_FULFILLMENT_MODE_UNSPECIFIED, pb.FulfillmentMode_FULFILLMENT_MODE_OVER:
+		opts.Mode = service.ModeOver
+	case pb.FulfillmentMode_FULFILLMENT_MODE_UNDER:
+		opts.Mode = service.ModeUnder
+	case pb.FulfillmentMode_FULFILLMENT_MODE_EXACT_ONLY:
+		opts.Mode = service.ModeExactOnly
+	default:
+		return opts, fmt.Errorf("%w: unknown mode %v", service.ErrInvalidOptions, req.GetMode())
+	}
+	if req.MaxOverage != nil {
+		n := int(req.GetMaxOverage())
+		opts.MaxOverage = &n
+	}
+	if req.MaxPacks != nil {
+		n := int(req.GetMaxPacks())
+		opts.MaxPacks = &n
+	}
+	if req.MaxOveragePct != nil {
+		pct := req.GetMaxOveragePct()
+		opts.MaxOveragePct = &pct
+	}
+	return opts, opts.Validate()
+}
+
+// parseGRPCID parses the UUID in field, failing with INVALID_ARGUMENT.
+func parseGRPCID(field, value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid %s: %v", field, err)
+	}
+	return id, nil
+}
+
+// grpcError logs err and maps it to a gRPC status, like the REST handlers map errors to status codes.
+func grpcError(msg string, err error) error {
+	slog.Error(msg, "error", err)
+	var infeasible *service.InfeasibleError
+	switch {
+	case errors.As(err, &infeasible):
+		st, detailErr := status.New(codes.FailedPrecondition, infeasible.Error()).WithDetails(protoadapt.MessageV1Of(&pb.Infeasible{
+			Constraints: infeasible.Constraints,
+			Reason:      infeasible.Reason,
+			MinOverage:  int64(infeasible.MinOverage),
+			MinPacks:    int64(infeasible.MinPacks),
+		}))
+		if detailErr != nil {
+			return status.Error(codes.FailedPrecondition, infeasible.Error())
+		}
+		return st.Err()
+	case errors.Is(err, port.ErrProductNotFound), errors.Is(err, port.ErrCustomerNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, port.ErrDuplicateSKU):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, port.ErrVersionConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, service.ErrInvalidOptions), errors.Is(err, service.ErrInvalidPackSizes):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrNoAcceptedPackSizes):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrSolverBusy):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, service.ErrSolverBudgetExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func productToProto(p *model.Product) *pb.Product {
+	return &pb.Product{Id: p.ID.String(), Name: p.Name, Sku: p.SKU, Version: p.Version}
+}
+
+func packSetToProto(set *model.PackSet) *pb.PackSet {
+	resp := &pb.PackSet{ProductId: set.ProductID.String(), Version: set.Version, Packs: make([]*pb.Pack, 0, len(set.Packs))}
+	for _, p := range set.Packs {
+		resp.Packs = append(resp.Packs, &pb.Pack{Id: p.ID.String(), Size: int64(p.Size)})
+	}
+	return resp
+}
+
+// fulfillmentToProto converts the version 2 REST response, so that both APIs order and identify packs alike.
+func fulfillmentToProto(r FulfillmentResponse) *pb.FulfillResponse {
+	resp := &pb.FulfillResponse{
+		ProductId:         r.ProductID.String(),
+		RequestedQuantity: int64(r.RequestedQuantity),
+		TotalItems:        int64(r.TotalItems),
+		Overage:           int64(r.Overage),
+		Shortfall:         int64(r.Shortfall),
+		PackCount:         int64(r.PackCount),
+		Packs:             make([]*pb.FulfilledPack, 0, len(r.Packs)),
+	}
+	for _, p := range r.Packs {
+		resp.Packs = append(resp.Packs, &pb.FulfilledPack{
+			PackId:   p.PackID.String(),
+			Size:     int64(p.Size),
+			Count:    int64(p.Count),
+			Subtotal: int64(p.Subtotal),
+		})
+	}
+	return resp
+}
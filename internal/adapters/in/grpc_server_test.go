@@ -0,0 +1,167 @@
+package in
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/rlpaul93/order-fulfillment/api/proto/orderfulfillment/v1"
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+// newTestGRPCClient serves a GRPCServer over in-memory services and returns a client connected to it.
+func newTestGRPCClient(t *testing.T) pb.OrderFulfillmentServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	packSvc := &service.PackService{Repo: out.NewPackRepositoryMem()}
+	pb.RegisterOrderFulfillmentServiceServer(srv, &GRPCServer{
+		Products:    &service.ProductService{Repo: out.NewProductRepositoryMem()},
+		Packs:       packSvc,
+		Fulfillment: &service.PackFulfillmentService{Packs: packSvc},
+	})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewOrderFulfillmentServiceClient(conn)
+}
+
+// newTestGRPCProduct creates a product with the given pack sizes.
+func newTestGRPCProduct(t *testing.T, client pb.OrderFulfillmentServiceClient, sizes ...int64) *pb.Product {
+	t.Helper()
+	ctx := context.Background()
+	p, err := client.CreateProduct(ctx, &pb.CreateProductRequest{Name: "Shoes", Sku: "SH-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ReplacePacks(ctx, &pb.ReplacePacksRequest{ProductId: p.GetId(), Sizes: sizes}); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestGRPCServer_ProductsAndPacks(t *testing.T) {
+	ctx := context.Background()
+	client := newTestGRPCClient(t)
+	p := newTestGRPCProduct(t, client, 250, 500)
+
+	updated, err := client.UpdateProduct(ctx, &pb.UpdateProductRequest{Id: p.GetId(), Name: "Boots", ExpectedVersion: p.GetVersion()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.GetName() != "Boots" || updated.GetVersion() != p.GetVersion()+1 {
+		t.Errorf("expected Boots at version %d, got %v", p.GetVersion()+1, updated)
+	}
+	_, err = client.UpdateProduct(ctx, &pb.UpdateProductRequest{Id: p.GetId(), Name: "Clogs", ExpectedVersion: p.GetVersion()})
+	if status.Code(err) != codes.Aborted {
+		t.Errorf("expected ABORTED for a stale version, got %v", err)
+	}
+
+	set, err := client.GetPacks(ctx, &pb.GetPacksRequest{ProductId: p.GetId()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.GetPacks()) != 2 || set.GetVersion() != 2 {
+		t.Errorf("expected 2 packs at version 2, got %v", set)
+	}
+
+	if _, err := client.DeleteProduct(ctx, &pb.DeleteProductRequest{Id: p.GetId()}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetProduct(ctx, &pb.GetProductRequest{Id: p.GetId()}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NOT_FOUND for a deleted product, got %v", err)
+	}
+	if _, err := client.GetProduct(ctx, &pb.GetProductRequest{Id: "nope"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected INVALID_ARGUMENT for a malformed ID, got %v", err)
+	}
+}
+
+func TestGRPCServer_Fulfill(t *testing.T) {
+	ctx := context.Background()
+	client := newTestGRPCClient(t)
+	p := newTestGRPCProduct(t, client, 250, 500, 1000)
+
+	resp, err := client.Fulfill(ctx, &pb.FulfillRequest{ProductId: p.GetId(), Quantity: 251})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetTotalItems() != 500 || resp.GetOverage() != 249 || len(resp.GetPacks()) != 1 || resp.GetPacks()[0].GetSize() != 500 {
+		t.Errorf("expected one pack of 500, got %v", resp)
+	}
+
+	_, err = client.Fulfill(ctx, &pb.FulfillRequest{ProductId: p.GetId(), Quantity: 251, MaxOverage: proto.Int64(10)})
+	st := status.Convert(err)
+	if st.Code() != codes.FailedPrecondition || len(st.Details()) != 1 {
+		t.Fatalf("expected FAILED_PRECONDITION with an Infeasible detail, got %v", err)
+	}
+	if infeasible, ok := st.Details()[0].(*pb.Infeasible); !ok || infeasible.GetMinOverage() != 249 {
+		t.Errorf("expected a minimal overage of 249, got %v", st.Details()[0])
+	}
+}
+
+func TestGRPCServer_BatchFulfill(t *testing.T) {
+	client := newTestGRPCClient(t)
+	p := newTestGRPCProduct(t, client, 3, 5)
+
+	stream, err := client.BatchFulfill(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	orders := []*pb.FulfillRequest{
+		{RequestId: "a", ProductId: p.GetId(), Quantity: 8},
+		{RequestId: "b", ProductId: p.GetId(), Quantity: 7, Mode: pb.FulfillmentMode_FULFILLMENT_MODE_EXACT_ONLY},
+		{RequestId: "c", ProductId: "nope", Quantity: 1},
+		{RequestId: "d", ProductId: p.GetId(), Quantity: 11},
+	}
+	for _, order := range orders {
+		if err := stream.Send(order); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []*pb.BatchFulfillResponse
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, resp)
+	}
+	if len(got) != len(orders) {
+		t.Fatalf("expected %d responses, got %d", len(orders), len(got))
+	}
+	if got[0].GetRequestId() != "a" || got[0].GetResult().GetTotalItems() != 8 {
+		t.Errorf("expected order a to ship 8 items, got %v", got[0])
+	}
+	if e := got[1].GetError(); codes.Code(e.GetCode()) != codes.FailedPrecondition || e.GetInfeasible() == nil {
+		t.Errorf("expected order b to be infeasible, got %v", got[1])
+	}
+	if e := got[2].GetError(); codes.Code(e.GetCode()) != codes.InvalidArgument {
+		t.Errorf("expected order c to be invalid, got %v", got[2])
+	}
+	if got[3].GetRequestId() != "d" || got[3].GetResult().GetTotalItems() != 11 {
+		t.Errorf("expected order d to ship 11 items, got %v", got[3])
+	}
+}
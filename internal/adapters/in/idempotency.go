@@ -0,0 +1,176 @@
+package in
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header.
+const maxIdempotencyKeyLength = 255
+
+// maxIdempotentBodyBytes bounds the body of a request with an Idempotency-Key, which is read in full to
+// fingerprint it: the largest body any route accepts, a catalog import.
+const maxIdempotentBodyBytes = maxCatalogImportBytes
+
+// replayedHeaders are the response headers stored with an idempotent response and sent again on replay.
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// IdempotencyMiddleware makes POST, PUT, PATCH and DELETE requests carrying an Idempotency-Key header safe to
+// retry. The first request with a key is processed and its response stored; retries with the same method,
+// URL and body get the stored response, marked with an Idempotent-Replayed header, without reaching next.
+// Keys are scoped to the client, as identified by clientOf, and to the method and path, so that a client never
+// gets the response to another client's request. Reusing a key for a different request is rejected with 422
+// and retrying a request that is still being processed with 409. Transient failures (see transientStatus) and
+// panics are not stored, so that retries process the request again.
+func IdempotencyMiddleware(svc *service.IdempotencyService, clientOf func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			if key == "" || !mutating(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				slog.Error("Idempotency key too long", "length", len(key))
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodyBytes))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				slog.Error("Request body too large", "limit", tooLarge.Limit)
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+			if err != nil {
+				slog.Error("Failed to read request body", "error", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			scoped := scopedKey(clientOf(r), r, key)
+			record, err := svc.Begin(r.Context(), scoped, requestFingerprint(r, body))
+			switch {
+			case errors.Is(err, service.ErrIdempotencyKeyReused):
+				slog.Info("Idempotency key reused", "key", key, "path", r.URL.Path)
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			case errors.Is(err, service.ErrIdempotencyKeyInProgress):
+				slog.Info("Idempotent request in progress", "key", key, "path", r.URL.Path)
+				w.WriteHeader(http.StatusConflict)
+				return
+			case err != nil:
+				slog.Error("Failed to claim idempotency key", "key", key, "error", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			case record != nil:
+				slog.Info("Replaying idempotent response", "key", key, "status", record.StatusCode)
+				for name, values := range record.Header {
+					w.Header()[name] = values
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(record.StatusCode)
+				w.Write(record.Body)
+				return
+			}
+
+			// The response is stored even if the client went away, since that is when it will retry.
+			ctx := context.WithoutCancel(r.Context())
+			defer func() {
+				if p := recover(); p != nil {
+					if err := svc.Release(ctx, scoped); err != nil {
+						slog.Error("Failed to release idempotency key", "key", key, "error", err)
+					}
+					panic(p)
+				}
+			}()
+			rec := &responseCapture{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if transientStatus(rec.status) {
+				err = svc.Release(ctx, scoped)
+			} else {
+				header := make(map[string][]string)
+				for _, name := range replayedHeaders {
+					if values := rec.Header().Values(name); len(values) > 0 {
+						header[name] = values
+					}
+				}
+				err = svc.Complete(ctx, scoped, rec.status, header, rec.body.Bytes())
+			}
+			if err != nil {
+				slog.Error("Failed to store idempotent response", "key", key, "error", err)
+			}
+		})
+	}
+}
+
+// mutating reports whether requests with method change state.
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// transientStatus reports whether a response with status may not be the answer to a retry of the request:
+// a server error, a rate limit, or a conflict that may have cleared by then.
+func transientStatus(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooEarly, http.StatusTooManyRequests:
+		return true
+	}
+	return status >= http.StatusInternalServerError
+}
+
+// scopedKey returns the stored form of the idempotency key a client sent with r, hashed as it contains the
+// client's identity.
+func scopedKey(client string, r *http.Request, key string) string {
+	h := sha256.New()
+	io.WriteString(h, client+"\n"+r.Method+" "+r.URL.Path+"\n"+key)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// requestFingerprint identifies a request by its method, URL and body.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseCapture passes a response through while keeping a copy of its status and body.
+type responseCapture struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (c *responseCapture) WriteHeader(status int) {
+	if !c.wroteHeader {
+		c.status, c.wroteHeader = status, true
+	}
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *responseCapture) Write(b []byte) (int, error) {
+	c.wroteHeader = true
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (c *responseCapture) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
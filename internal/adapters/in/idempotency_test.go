@@ -0,0 +1,142 @@
+package in
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+// testClient identifies the client of a request by its X-Client header.
+func testClient(r *http.Request) string {
+	return r.Header.Get("X-Client")
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	products := &service.ProductService{Repo: out.NewProductRepositoryMem()}
+	idempotency := &service.IdempotencyService{Repo: out.NewIdempotencyRepositoryMem(), Window: time.Hour}
+	handler := IdempotencyMiddleware(idempotency, testClient)(CreateProductHandler(products))
+	post := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := post("k1", `{"name":"Shoes"}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", first.Code)
+	}
+	retry := post("k1", `{"name":"Shoes"}`)
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("expected the original response to be replayed, got %d %s", retry.Code, retry.Body)
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("expected the replay to be marked with Idempotent-Replayed")
+	}
+	if rec := post("k1", `{"name":"Hats"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status 422 for a key reused with another body, got %d", rec.Code)
+	}
+	post("", `{"name":"Socks"}`)
+
+	list, _ := products.List(t.Context())
+	if len(list) != 2 {
+		t.Errorf("expected 2 products to be created, got %d", len(list))
+	}
+}
+
+func TestIdempotencyMiddleware_TransientFailuresAreNotStored(t *testing.T) {
+	idempotency := &service.IdempotencyService{Repo: out.NewIdempotencyRepositoryMem(), Window: time.Hour}
+	statuses := []int{http.StatusTooManyRequests, http.StatusConflict, http.StatusServiceUnavailable, http.StatusCreated}
+	var calls int
+	handler := IdempotencyMiddleware(idempotency, testClient)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(statuses[calls])
+		calls++
+	}))
+
+	for _, want := range append(statuses, http.StatusCreated) {
+		req := httptest.NewRequest(http.MethodPost, "/shipments/plan", strings.NewReader(`{}`))
+		req.Header.Set("Idempotency-Key", "k1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("expected status %d, got %d", want, rec.Code)
+		}
+	}
+	if calls != len(statuses) {
+		t.Errorf("expected the request to be processed %d times, got %d", len(statuses), calls)
+	}
+}
+
+func TestIdempotencyMiddleware_KeysAreScopedToClients(t *testing.T) {
+	products := &service.ProductService{Repo: out.NewProductRepositoryMem()}
+	idempotency := &service.IdempotencyService{Repo: out.NewIdempotencyRepositoryMem(), Window: time.Hour}
+	handler := IdempotencyMiddleware(idempotency, testClient)(CreateProductHandler(products))
+
+	for _, client := range []string{"erp", "shop"} {
+		req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{"name":"Shoes"}`))
+		req.Header.Set("Idempotency-Key", "k1")
+		req.Header.Set("X-Client", client)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated || rec.Header().Get("Idempotent-Replayed") != "" {
+			t.Errorf("%s: expected the request to be processed, got %d %v", client, rec.Code, rec.Header())
+		}
+	}
+	if list, _ := products.List(t.Context()); len(list) != 2 {
+		t.Errorf("expected a product per client, got %d", len(list))
+	}
+}
+
+func TestIdempotencyMiddleware_BodyTooLarge(t *testing.T) {
+	idempotency := &service.IdempotencyService{Repo: out.NewIdempotencyRepositoryMem(), Window: time.Hour}
+	handler := IdempotencyMiddleware(idempotency, testClient)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("handler reached with an oversized body")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/catalog/import", strings.NewReader(strings.Repeat("x", maxIdempotentBodyBytes+1)))
+	req.Header.Set("Idempotency-Key", "k1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status 413, got %d", rec.Code)
+	}
+}
+
+func TestIdempotencyMiddleware_PanicReleasesKey(t *testing.T) {
+	idempotency := &service.IdempotencyService{Repo: out.NewIdempotencyRepositoryMem(), Window: time.Hour}
+	panics := true
+	handler := IdempotencyMiddleware(idempotency, testClient)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if panics {
+			panic("boom")
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	post := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{}`))
+		req.Header.Set("Idempotency-Key", "k1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected the panic to propagate")
+			}
+		}()
+		post()
+	}()
+	panics = false
+	if rec := post(); rec.Code != http.StatusCreated {
+		t.Errorf("expected the retry to be processed, got %d", rec.Code)
+	}
+}
@@ -2,26 +2,47 @@ package in
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
 )
 
+// InfeasibleResponse explains why no pack combination satisfies the requested constraints.
+type InfeasibleResponse struct {
+	Status string `json:"status" example:"infeasible"`
+	service.InfeasibleError
+}
+
 // PackFulfillmentHandler godoc
 // @Summary Calculate optimal pack fulfillment
-// @Description Given a product ID and quantity, returns the optimal combination of packs that fulfills the order with minimal excess items and minimal pack count
+// @Description Given a product ID and quantity, returns the optimal combination of packs that fulfills the order with minimal excess items and minimal pack count. With customer_id, only the pack sizes the customer accepts for the product are used. mode=under ships the largest quantity not exceeding the request and reports the shortfall, and mode=exact_only fails unless the quantity can be met exactly. max_overage, max_overage_pct and max_packs are hard limits; when no combination meets them the response explains which one blocked the order. explain=true adds an Explanation with the overage, pack count, rejected runner-up plans, the pack set's GCD and largest unreachable quantity, and solver statistics. The response keeps the legacy service.PackFulfillmentResult shape (schema version 1) unless schema_version=2 requests a FulfillmentResponse, which lists the packs largest first with their pack IDs.
 // @Tags Fulfillment
 // @Produce json
 // @Param product_id query string true "Product UUID"
-// @Param quantity query int true "Number of items to fulfill"
-// @Success 200 {object} service.PackFulfillmentResult
-// @Failure 400 {string} string "Invalid product_id or quantity"
-// @Failure 404 {string} string "No packs found for product"
+// @Param quantity query int true "Number of items to fulfill, from 1 to the configured fulfill_max_quantity"
+// @Param customer_id query string false "Customer UUID whose pack rules apply"
+// @Param mode query string false "Fulfillment mode" Enums(over, under, exact_only) default(over)
+// @Param max_overage query int false "Most items that may be shipped beyond the quantity"
+// @Param max_overage_pct query number false "Most items that may be shipped beyond the quantity, as a percentage of it"
+// @Param max_packs query int false "Most packs the plan may use"
+// @Param explain query bool false "Include an explanation of the chosen plan"
+// @Param schema_version query int false "Response schema version" Enums(1, 2) default(1)
+// @Success 200 {object} service.PackFulfillmentResult "Schema version 1; a FulfillmentResponse with schema_version=2"
+// @Failure 400 {string} string "Invalid product_id, quantity, customer_id, mode, constraint, explain or schema_version"
+// @Failure 404 {string} string "No packs found for product, or customer not found"
+// @Failure 422 {object} InfeasibleResponse "No plan satisfies the constraints, or the customer accepts none of the product's pack sizes"
+// @Failure 429 {string} string "Too many requests, or too many concurrent fulfillments; see Retry-After"
+// @Failure 500 {string} string "Internal error"
+// @Failure 503 {string} string "Solver exceeded its time budget"
 // @Router /fulfill [get]
-func PackFulfillmentHandler(svc *service.PackFulfillmentService, packSvc *service.PackService) http.HandlerFunc {
+func PackFulfillmentHandler(svc *service.PackFulfillmentService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		productIDStr := r.URL.Query().Get("product_id")
 		quantityStr := r.URL.Query().Get("quantity")
@@ -38,19 +59,128 @@ func PackFulfillmentHandler(svc *service.PackFulfillmentService, packSvc *servic
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
-		packs, err := packSvc.ListByProduct(productID)
-		if err != nil || len(packs) == 0 {
-			slog.Error("No packs found for product", "product_id", productIDStr)
-			w.WriteHeader(http.StatusNotFound)
+		opts, err := parseFulfillmentOptions(r.URL.Query())
+		if err != nil {
+			slog.Error("Invalid fulfillment constraints", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		schemaVersion, err := parseSchemaVersion(r.URL.Query().Get("schema_version"))
+		if err != nil {
+			slog.Error("Invalid schema_version", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var customerID uuid.UUID
+		if customerIDStr := r.URL.Query().Get("customer_id"); customerIDStr != "" {
+			if customerID, err = uuid.Parse(customerIDStr); err != nil {
+				slog.Error("Invalid customer_id", "customer_id", customerIDStr, "error", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		result, packs, err := svc.FulfillForCustomer(r.Context(), productID, quantity, customerID, opts)
+		var infeasible *service.InfeasibleError
+		if errors.As(err, &infeasible) {
+			slog.Info("Pack fulfillment infeasible", "product_id", productIDStr, "quantity", quantity, "constraints", infeasible.Constraints)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(InfeasibleResponse{Status: "infeasible", InfeasibleError: *infeasible})
 			return
 		}
-		var sizes []int
-		for _, p := range packs {
-			sizes = append(sizes, p.Size)
+		if err != nil {
+			slog.Error("Pack fulfillment failed", "product_id", productIDStr, "quantity", quantity, "customer_id", customerID, "error", err)
+			writeFulfillmentError(w, err)
+			return
 		}
-		result := svc.FulfillOrder(quantity, sizes)
 		slog.Info("Pack fulfillment result", "result", result)
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(result)
+		if schemaVersion == FulfillmentSchemaLegacy {
+			json.NewEncoder(w).Encode(result)
+			return
+		}
+		json.NewEncoder(w).Encode(newFulfillmentResponse(productID, quantity, result, packs))
+	}
+}
+
+// writeFulfillmentError writes the status of a fulfillment error other than an infeasible order, asking the
+// client to retry in a second when the solvers are busy.
+func writeFulfillmentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidOptions), errors.Is(err, service.ErrInvalidPackSizes):
+		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, port.ErrProductNotFound), errors.Is(err, port.ErrCustomerNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, service.ErrNoAcceptedPackSizes):
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	case errors.Is(err, service.ErrSolverBusy):
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	case errors.Is(err, service.ErrSolverBudgetExceeded):
+		w.WriteHeader(http.StatusServiceUnavailable)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// parseFulfillmentOptions reads the optional fulfillment mode, constraints and explain flag from query parameters.
+func parseFulfillmentOptions(query url.Values) (service.FulfillmentOptions, error) {
+	opts := service.FulfillmentOptions{Mode: query.Get("mode")}
+	for name, dst := range map[string]**int{
+		service.ConstraintMaxOverage: &opts.MaxOverage,
+		service.ConstraintMaxPacks:   &opts.MaxPacks,
+	} {
+		if v := query.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return opts, fmt.Errorf("%s: %w", name, err)
+			}
+			*dst = &n
+		}
+	}
+	if v := query.Get("explain"); v != "" {
+		explain, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("explain: %w", err)
+		}
+		opts.Explain = explain
+	}
+	if v := query.Get(service.ConstraintMaxOveragePct); v != "" {
+		pct, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return opts, fmt.Errorf("%s: %w", service.ConstraintMaxOveragePct, err)
+		}
+		opts.MaxOveragePct = &pct
+	}
+	return opts, opts.Validate()
+}
+
+// FulfillmentTableStatusHandler godoc
+// @Summary Get the fulfillment table build status for a product
+// @Description Reports whether the product's precomputed fulfillment table is building, ready or failed
+// @Tags Fulfillment
+// @Produce json
+// @Param id path string true "Product UUID"
+// @Success 200 {object} service.TableStatus
+// @Failure 400 {string} string "Invalid product ID"
+// @Failure 404 {string} string "Fulfillment tables are disabled"
+// @Router /products/{id}/fulfillment-table [get]
+func FulfillmentTableStatusHandler(svc *service.PackFulfillmentService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		productID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			slog.Error("Invalid product ID", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if svc.Tables == nil {
+			slog.Error("Fulfillment tables are disabled")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		status := svc.Tables.Status(productID)
+		slog.Info("Fulfillment table status retrieved", "product_id", productID, "status", status.Status)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(status)
 	}
 }
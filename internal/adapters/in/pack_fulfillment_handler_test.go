@@ -1,12 +1,16 @@
 package in
 
 import (
+	"context"
 	"encoding/json"
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
 )
@@ -17,30 +21,38 @@ type mockPackRepository struct {
 	err   error
 }
 
-func (m *mockPackRepository) Create(pack *model.Pack) error {
+func (m *mockPackRepository) Create(ctx context.Context, pack *model.Pack) error {
 	return m.err
 }
 
-func (m *mockPackRepository) GetByID(id uuid.UUID) (*model.Pack, error) {
+func (m *mockPackRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Pack, error) {
 	return nil, m.err
 }
 
-func (m *mockPackRepository) Update(pack *model.Pack) error {
+func (m *mockPackRepository) Update(ctx context.Context, pack *model.Pack) error {
 	return m.err
 }
 
-func (m *mockPackRepository) Delete(id uuid.UUID) error {
+func (m *mockPackRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return m.err
 }
 
-func (m *mockPackRepository) DeleteByProduct(productID uuid.UUID) error {
+func (m *mockPackRepository) DeleteByProduct(ctx context.Context, productID uuid.UUID) error {
 	return m.err
 }
 
-func (m *mockPackRepository) ListByProduct(productID uuid.UUID) ([]*model.Pack, error) {
+func (m *mockPackRepository) ListByProduct(ctx context.Context, productID uuid.UUID) ([]*model.Pack, error) {
 	return m.packs, m.err
 }
 
+func (m *mockPackRepository) GetPackSet(ctx context.Context, productID uuid.UUID) (*model.PackSet, error) {
+	return &model.PackSet{ProductID: productID, Version: 1, Packs: m.packs}, m.err
+}
+
+func (m *mockPackRepository) ReplacePackSet(ctx context.Context, productID uuid.UUID, sizes []int, expectedVersion int64) (_, _ *model.PackSet, err error) {
+	return nil, nil, m.err
+}
+
 func TestPackFulfillmentHandler_Success(t *testing.T) {
 	productID := uuid.New()
 	mockRepo := &mockPackRepository{
@@ -51,9 +63,9 @@ func TestPackFulfillmentHandler_Success(t *testing.T) {
 		},
 	}
 	packSvc := &service.PackService{Repo: mockRepo}
-	fulfillSvc := &service.PackFulfillmentService{}
+	fulfillSvc := &service.PackFulfillmentService{Packs: packSvc}
 
-	handler := PackFulfillmentHandler(fulfillSvc, packSvc)
+	handler := PackFulfillmentHandler(fulfillSvc)
 
 	req := httptest.NewRequest(http.MethodGet, "/fulfill?product_id="+productID.String()+"&quantity=251", nil)
 	rec := httptest.NewRecorder()
@@ -76,9 +88,9 @@ func TestPackFulfillmentHandler_Success(t *testing.T) {
 
 func TestPackFulfillmentHandler_InvalidProductID(t *testing.T) {
 	packSvc := &service.PackService{Repo: &mockPackRepository{}}
-	fulfillSvc := &service.PackFulfillmentService{}
+	fulfillSvc := &service.PackFulfillmentService{Packs: packSvc}
 
-	handler := PackFulfillmentHandler(fulfillSvc, packSvc)
+	handler := PackFulfillmentHandler(fulfillSvc)
 
 	req := httptest.NewRequest(http.MethodGet, "/fulfill?product_id=invalid&quantity=100", nil)
 	rec := httptest.NewRecorder()
@@ -93,9 +105,9 @@ func TestPackFulfillmentHandler_InvalidProductID(t *testing.T) {
 func TestPackFulfillmentHandler_InvalidQuantity(t *testing.T) {
 	productID := uuid.New()
 	packSvc := &service.PackService{Repo: &mockPackRepository{}}
-	fulfillSvc := &service.PackFulfillmentService{}
+	fulfillSvc := &service.PackFulfillmentService{Packs: packSvc}
 
-	handler := PackFulfillmentHandler(fulfillSvc, packSvc)
+	handler := PackFulfillmentHandler(fulfillSvc)
 
 	req := httptest.NewRequest(http.MethodGet, "/fulfill?product_id="+productID.String()+"&quantity=abc", nil)
 	rec := httptest.NewRecorder()
@@ -107,15 +119,37 @@ func TestPackFulfillmentHandler_InvalidQuantity(t *testing.T) {
 	}
 }
 
+func TestPackFulfillmentHandler_QuantityOutOfRange(t *testing.T) {
+	productID := uuid.New()
+	mockRepo := &mockPackRepository{
+		packs: []*model.Pack{{ID: uuid.New(), ProductID: productID, Size: 250}},
+	}
+	fulfillSvc := &service.PackFulfillmentService{Packs: &service.PackService{Repo: mockRepo}}
+	fulfillSvc.Configure(service.FulfillmentSettings{MaxQuantity: 1000})
+
+	handler := PackFulfillmentHandler(fulfillSvc)
+
+	for _, quantity := range []string{"0", "-250", "1001"} {
+		req := httptest.NewRequest(http.MethodGet, "/fulfill?product_id="+productID.String()+"&quantity="+quantity, nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("quantity %s: expected status %d, got %d", quantity, http.StatusBadRequest, rec.Code)
+		}
+	}
+}
+
 func TestPackFulfillmentHandler_NoPacksFound(t *testing.T) {
 	productID := uuid.New()
 	mockRepo := &mockPackRepository{
 		packs: []*model.Pack{},
 	}
 	packSvc := &service.PackService{Repo: mockRepo}
-	fulfillSvc := &service.PackFulfillmentService{}
+	fulfillSvc := &service.PackFulfillmentService{Packs: packSvc}
 
-	handler := PackFulfillmentHandler(fulfillSvc, packSvc)
+	handler := PackFulfillmentHandler(fulfillSvc)
 
 	req := httptest.NewRequest(http.MethodGet, "/fulfill?product_id="+productID.String()+"&quantity=100", nil)
 	rec := httptest.NewRecorder()
@@ -136,9 +170,9 @@ func TestPackFulfillmentHandler_ExactPackSize(t *testing.T) {
 		},
 	}
 	packSvc := &service.PackService{Repo: mockRepo}
-	fulfillSvc := &service.PackFulfillmentService{}
+	fulfillSvc := &service.PackFulfillmentService{Packs: packSvc}
 
-	handler := PackFulfillmentHandler(fulfillSvc, packSvc)
+	handler := PackFulfillmentHandler(fulfillSvc)
 
 	req := httptest.NewRequest(http.MethodGet, "/fulfill?product_id="+productID.String()+"&quantity=500", nil)
 	rec := httptest.NewRecorder()
@@ -158,3 +192,156 @@ func TestPackFulfillmentHandler_ExactPackSize(t *testing.T) {
 		t.Errorf("expected TotalItems = 500, got %d", result.TotalItems)
 	}
 }
+
+func TestPackFulfillmentHandler_CustomerPackRules(t *testing.T) {
+	productID := uuid.New()
+	mockRepo := &mockPackRepository{
+		packs: []*model.Pack{
+			{ID: uuid.New(), ProductID: productID, Size: 250},
+			{ID: uuid.New(), ProductID: productID, Size: 500},
+			{ID: uuid.New(), ProductID: productID, Size: 1000},
+		},
+	}
+	packSvc := &service.PackService{Repo: mockRepo}
+	customerSvc := &service.CustomerService{Repo: out.NewCustomerRepositoryMem()}
+	fulfillSvc := &service.PackFulfillmentService{Packs: packSvc, Customers: customerSvc}
+
+	restricted := &model.Customer{Name: "Retailer", PackRules: []model.PackRule{
+		{ProductID: productID, ForbiddenSizes: []int{250}},
+	}}
+	if err := customerSvc.Create(context.Background(), restricted); err != nil {
+		t.Fatal(err)
+	}
+	excluded := &model.Customer{Name: "Small shop", PackRules: []model.PackRule{{MaxPackSize: 100}}}
+	if err := customerSvc.Create(context.Background(), excluded); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := PackFulfillmentHandler(fulfillSvc)
+
+	tests := []struct {
+		name       string
+		customerID string
+		wantStatus int
+		wantPacks  map[int]int
+	}{
+		{name: "no customer", wantStatus: http.StatusOK, wantPacks: map[int]int{250: 1}},
+		{name: "forbidden size", customerID: restricted.ID.String(), wantStatus: http.StatusOK, wantPacks: map[int]int{500: 1}},
+		{name: "no usable size", customerID: excluded.ID.String(), wantStatus: http.StatusUnprocessableEntity},
+		{name: "unknown customer", customerID: uuid.NewString(), wantStatus: http.StatusNotFound},
+		{name: "invalid customer", customerID: "invalid", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := "/fulfill?product_id=" + productID.String() + "&quantity=200"
+			if tt.customerID != "" {
+				target += "&customer_id=" + tt.customerID
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantPacks == nil {
+				return
+			}
+			var result service.PackFulfillmentResult
+			if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if !maps.Equal(result.Packs, tt.wantPacks) {
+				t.Errorf("expected packs %v, got %v", tt.wantPacks, result.Packs)
+			}
+		})
+	}
+}
+
+func TestPackFulfillmentHandler_Constraints(t *testing.T) {
+	productID := uuid.New()
+	mockRepo := &mockPackRepository{
+		packs: []*model.Pack{
+			{ID: uuid.New(), ProductID: productID, Size: 250},
+			{ID: uuid.New(), ProductID: productID, Size: 500},
+		},
+	}
+	packSvc := &service.PackService{Repo: mockRepo}
+	fulfillSvc := &service.PackFulfillmentService{Packs: packSvc}
+
+	handler := PackFulfillmentHandler(fulfillSvc)
+
+	target := "/fulfill?product_id=" + productID.String() + "&quantity=251"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target+"&max_overage_pct=10", nil))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+	var infeasible InfeasibleResponse
+	if err := json.NewDecoder(rec.Body).Decode(&infeasible); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if infeasible.Status != "infeasible" || infeasible.MinOverage != 249 || len(infeasible.Constraints) != 1 || infeasible.Constraints[0] != service.ConstraintMaxOveragePct {
+		t.Errorf("unexpected infeasible response %+v", infeasible)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target+"&max_packs=-1", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for a negative limit, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestPackFulfillmentHandler_SchemaVersions(t *testing.T) {
+	productID := uuid.New()
+	small := &model.Pack{ID: uuid.New(), ProductID: productID, Size: 250}
+	large := &model.Pack{ID: uuid.New(), ProductID: productID, Size: 1000}
+	packSvc := &service.PackService{Repo: &mockPackRepository{packs: []*model.Pack{small, large}}}
+	fulfillSvc := &service.PackFulfillmentService{Packs: packSvc}
+
+	handler := PackFulfillmentHandler(fulfillSvc)
+	target := "/fulfill?product_id=" + productID.String() + "&quantity=1200"
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target+"&schema_version=2", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	var current FulfillmentResponse
+	if err := json.NewDecoder(rec.Body).Decode(&current); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := FulfillmentResponse{
+		SchemaVersion:     FulfillmentSchemaCurrent,
+		ProductID:         productID,
+		RequestedQuantity: 1200,
+		TotalItems:        1250,
+		Overage:           50,
+		PackCount:         2,
+		Packs: []FulfilledPack{
+			{PackID: large.ID, Size: 1000, Count: 1, Subtotal: 1000},
+			{PackID: small.ID, Size: 250, Count: 1, Subtotal: 250},
+		},
+	}
+	if !reflect.DeepEqual(current, want) {
+		t.Errorf("got %+v, want %+v", current, want)
+	}
+
+	// Without schema_version, clients keep getting the legacy shape.
+	for _, query := range []string{"", "&schema_version=1"} {
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target+query, nil))
+		var legacy service.PackFulfillmentResult
+		if err := json.NewDecoder(rec.Body).Decode(&legacy); err != nil {
+			t.Fatalf("failed to decode legacy response: %v", err)
+		}
+		if legacy.TotalItems != 1250 || !maps.Equal(legacy.Packs, map[int]int{1000: 1, 250: 1}) {
+			t.Errorf("%q: unexpected legacy response %+v", query, legacy)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target+"&schema_version=3", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an unknown schema version, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
@@ -2,21 +2,35 @@ package in
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 
 	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
 )
 
+// PackMeasurements are the outer dimensions and weight of a full pack.
+type PackMeasurements struct {
+	Dimensions *model.Dimensions `json:"dimensions"`
+	WeightG    int               `json:"weight_g" example:"1200"`
+}
+
 // ListPacksForProductHandler godoc
 // @Summary List packs for a product
-// @Description Get all packs for a specific product
+// @Description Get all packs for a specific product. The ETag response header carries the version of the pack
+// @Description set; send it in If-None-Match to get 304 while the packs are unchanged.
 // @Tags Products
 // @Produce json
 // @Param id path string true "Product UUID"
+// @Param If-None-Match header string false "ETag of a previously retrieved pack set"
 // @Success 200 {array} model.Pack
+// @Header 200 {string} ETag "Version of the pack set"
+// @Success 304 {string} string "Packs unchanged"
 // @Failure 400 {string} string "Invalid product ID"
+// @Failure 404 {string} string "Product not found"
 // @Failure 500 {string} string "Internal server error"
 // @Router /products/{id}/packs [get]
 func ListPacksForProductHandler(svc *service.PackService) http.HandlerFunc {
@@ -28,28 +42,39 @@ func ListPacksForProductHandler(svc *service.PackService) http.HandlerFunc {
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
-		packs, err := svc.ListByProduct(productID)
+		set, err := svc.GetPackSet(r.Context(), productID)
 		if err != nil {
-			slog.Error("Failed to list packs", "error", err)
-			w.WriteHeader(http.StatusInternalServerError)
+			writeProductError(w, "Failed to list packs", productID, err)
+			return
+		}
+		etag := entityTag(set.Version)
+		w.Header().Set("ETag", etag)
+		if notModified(r, etag) {
+			w.WriteHeader(http.StatusNotModified)
 			return
 		}
-		slog.Info("Packs listed", "product_id", productID, "count", len(packs))
+		slog.Info("Packs listed", "product_id", productID, "count", len(set.Packs))
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(packs)
+		json.NewEncoder(w).Encode(set.Packs)
 	}
 }
 
 // UpdatePacksForProductHandler godoc
 // @Summary Update packs for a product
-// @Description Replace all packs for a product with a new list of sizes
+// @Description Replace all packs for a product with a new list of sizes. Send the ETag of the pack set being
+// @Description edited in If-Match to reject the update with 412 if the packs have changed since.
 // @Tags Products
 // @Accept json
 // @Produce json
 // @Param id path string true "Product UUID"
+// @Param If-Match header string false "ETag of the pack set being replaced"
 // @Param sizes body []int true "Array of pack sizes"
+// @Param Idempotency-Key header string false "Unique key making retries of the request return the original response"
 // @Success 200 {array} model.Pack
-// @Failure 400 {string} string "Invalid request"
+// @Header 200 {string} ETag "Version of the new pack set"
+// @Failure 400 {string} string "Invalid request, or a pack size that is not positive"
+// @Failure 404 {string} string "Product not found"
+// @Failure 412 {string} string "Packs changed since the If-Match version"
 // @Failure 500 {string} string "Internal server error"
 // @Router /products/{id}/packs [put]
 func UpdatePacksForProductHandler(svc *service.PackService) http.HandlerFunc {
@@ -67,14 +92,82 @@ func UpdatePacksForProductHandler(svc *service.PackService) http.HandlerFunc {
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
-		packs, err := svc.ReplaceByProduct(productID, sizes)
+		var expected int64
+		if hasIfMatch(r) {
+			current, err := svc.GetPackSet(r.Context(), productID)
+			if err != nil {
+				writeProductError(w, "Failed to get packs", productID, err)
+				return
+			}
+			if !ifMatches(r, entityTag(current.Version)) {
+				slog.Info("Packs update precondition failed", "product_id", productID, "version", current.Version)
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			expected = current.Version
+		}
+		set, err := svc.ReplacePackSet(r.Context(), productID, sizes, expected)
+		if err != nil {
+			writeProductError(w, "Failed to update packs", productID, err)
+			return
+		}
+		slog.Info("Packs updated", "product_id", productID, "count", len(set.Packs), "version", set.Version)
+		w.Header().Set("ETag", entityTag(set.Version))
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(set.Packs)
+	}
+}
+
+// UpdatePackMeasurementsHandler godoc
+// @Summary Set the dimensions and weight of a pack
+// @Description Set the outer dimensions (mm) and weight (g) of one of a product's packs, which shipment plans need to put the pack in cartons. Null dimensions and a zero weight mark them unknown. They are kept when the product's packs are replaced by packs of the same sizes. The pack set's version, and so its ETag, changes.
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product UUID"
+// @Param packID path string true "Pack UUID"
+// @Param measurements body PackMeasurements true "Dimensions and weight of the pack"
+// @Param Idempotency-Key header string false "Unique key making retries of the request return the original response"
+// @Success 200 {object} model.Pack
+// @Failure 400 {string} string "Invalid request, or dimensions or weight not positive"
+// @Failure 404 {string} string "Product has no such pack"
+// @Failure 500 {string} string "Internal server error"
+// @Router /products/{id}/packs/{packID} [put]
+func UpdatePackMeasurementsHandler(svc *service.PackService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		productID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			slog.Error("Invalid product ID", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		packID, err := uuid.Parse(r.PathValue("packID"))
+		if err != nil {
+			slog.Error("Invalid pack ID", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var m PackMeasurements
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			slog.Error("Failed to decode pack measurements", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		pack, err := svc.UpdateMeasurements(r.Context(), productID, packID, m.Dimensions, m.WeightG)
 		if err != nil {
-			slog.Error("Failed to update packs", "error", err)
-			w.WriteHeader(http.StatusInternalServerError)
+			slog.Error("Failed to update pack measurements", "product_id", productID, "pack_id", packID, "error", err)
+			switch {
+			case errors.Is(err, service.ErrInvalidMeasurements):
+				w.WriteHeader(http.StatusBadRequest)
+			case errors.Is(err, port.ErrPackNotFound):
+				w.WriteHeader(http.StatusNotFound)
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
 			return
 		}
-		slog.Info("Packs updated", "product_id", productID, "count", len(packs))
+		slog.Info("Pack measurements updated", "product_id", productID, "pack_id", packID, "size", pack.Size, "weight_g", pack.WeightG)
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(packs)
+		json.NewEncoder(w).Encode(pack)
 	}
 }
@@ -2,23 +2,27 @@ package in
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 
 	"github.com/google/uuid"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
 )
 
 // CreateProductHandler godoc
 // @Summary Create a new product
-// @Description Create a new product with a name
+// @Description Create a new product with a name and an optional unique SKU
 // @Tags Products
 // @Accept json
 // @Produce json
 // @Param product body model.Product true "Product to create"
+// @Param Idempotency-Key header string false "Unique key making retries of the request return the original response"
 // @Success 201 {object} model.Product
 // @Failure 400 {string} string "Invalid request body"
+// @Failure 409 {string} string "SKU already in use"
 // @Failure 500 {string} string "Internal server error"
 // @Router /products [post]
 func CreateProductHandler(svc *service.ProductService) http.HandlerFunc {
@@ -29,8 +33,12 @@ func CreateProductHandler(svc *service.ProductService) http.HandlerFunc {
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
-		if err := svc.Create(&p); err != nil {
+		if err := svc.Create(r.Context(), &p); err != nil {
 			slog.Error("Failed to create product", "error", err)
+			if errors.Is(err, port.ErrDuplicateSKU) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
@@ -42,11 +50,15 @@ func CreateProductHandler(svc *service.ProductService) http.HandlerFunc {
 
 // GetProductHandler godoc
 // @Summary Get a product by ID
-// @Description Get a product by its UUID
+// @Description Get a product by its UUID. The ETag response header carries the product's version; send it
+// @Description in If-None-Match to get 304 while the product is unchanged.
 // @Tags Products
 // @Produce json
 // @Param id path string true "Product UUID"
+// @Param If-None-Match header string false "ETag of a previously retrieved version"
 // @Success 200 {object} model.Product
+// @Header 200 {string} ETag "Version of the product"
+// @Success 304 {string} string "Product unchanged"
 // @Failure 400 {string} string "Invalid product ID"
 // @Failure 404 {string} string "Product not found"
 // @Router /products/{id} [get]
@@ -59,23 +71,105 @@ func GetProductHandler(svc *service.ProductService) http.HandlerFunc {
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
-		p, err := svc.GetByID(id)
+		p, err := svc.GetByID(r.Context(), id)
 		if err != nil {
 			slog.Error("Product not found", "id", id, "error", err)
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
+		etag := entityTag(p.Version)
+		w.Header().Set("ETag", etag)
+		if notModified(r, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
 		slog.Info("Product retrieved", "product", p)
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(p)
 	}
 }
 
+// UpdateProductHandler godoc
+// @Summary Update a product
+// @Description Update a product's name and SKU. Send the ETag of the version being edited in If-Match to
+// @Description reject the update with 412 if the product has changed since; the version in the body is ignored.
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product UUID"
+// @Param If-Match header string false "ETag of the version being updated"
+// @Param product body model.Product true "Product fields to store"
+// @Param Idempotency-Key header string false "Unique key making retries of the request return the original response"
+// @Success 200 {object} model.Product
+// @Header 200 {string} ETag "Version of the updated product"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Product not found"
+// @Failure 409 {string} string "SKU already in use"
+// @Failure 412 {string} string "Product changed since the If-Match version"
+// @Failure 500 {string} string "Internal server error"
+// @Router /products/{id} [put]
+func UpdateProductHandler(svc *service.ProductService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			slog.Error("Invalid product ID", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var p model.Product
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			slog.Error("Failed to decode product", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		p.ID, p.Version = id, 0
+		if hasIfMatch(r) {
+			current, err := svc.GetByID(r.Context(), id)
+			if err != nil {
+				writeProductError(w, "Failed to get product", id, err)
+				return
+			}
+			if !ifMatches(r, entityTag(current.Version)) {
+				slog.Info("Product update precondition failed", "id", id, "version", current.Version)
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			p.Version = current.Version
+		}
+		if err := svc.Update(r.Context(), &p); err != nil {
+			writeProductError(w, "Failed to update product", id, err)
+			return
+		}
+		slog.Info("Product updated", "product", p)
+		w.Header().Set("ETag", entityTag(p.Version))
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(p)
+	}
+}
+
+// writeProductError logs err and writes the status it maps to.
+func writeProductError(w http.ResponseWriter, msg string, id uuid.UUID, err error) {
+	slog.Error(msg, "id", id, "error", err)
+	switch {
+	case errors.Is(err, port.ErrProductNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, port.ErrDuplicateSKU):
+		w.WriteHeader(http.StatusConflict)
+	case errors.Is(err, service.ErrInvalidPackSizes):
+		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, port.ErrVersionConflict):
+		w.WriteHeader(http.StatusPreconditionFailed)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
 // DeleteProductHandler godoc
 // @Summary Delete a product by ID
 // @Description Delete a product by its UUID
 // @Tags Products
 // @Param id path string true "Product UUID"
+// @Param Idempotency-Key header string false "Unique key making retries of the request return the original response"
 // @Success 204 {string} string "Product deleted"
 // @Failure 400 {string} string "Invalid product ID"
 // @Failure 500 {string} string "Internal server error"
@@ -89,7 +183,7 @@ func DeleteProductHandler(svc *service.ProductService) http.HandlerFunc {
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
-		if err := svc.Delete(id); err != nil {
+		if err := svc.Delete(r.Context(), id); err != nil {
 			slog.Error("Failed to delete product", "id", id, "error", err)
 			w.WriteHeader(http.StatusInternalServerError)
 			return
@@ -109,7 +203,7 @@ func DeleteProductHandler(svc *service.ProductService) http.HandlerFunc {
 // @Router /products [get]
 func ListProductsHandler(svc *service.ProductService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		products, err := svc.List()
+		products, err := svc.List(r.Context())
 		if err != nil {
 			slog.Error("Failed to list products", "error", err)
 			w.WriteHeader(http.StatusInternalServerError)
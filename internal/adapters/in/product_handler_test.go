@@ -0,0 +1,124 @@
+package in
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+func newVersionedRequest(method, id, body string, header map[string]string) *http.Request {
+	req := httptest.NewRequest(method, "/products/"+id, strings.NewReader(body))
+	req.SetPathValue("id", id)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+func TestProductHandlers_ETags(t *testing.T) {
+	svc := &service.ProductService{Repo: out.NewProductRepositoryMem()}
+	p := &model.Product{Name: "Shoes"}
+	if err := svc.Create(context.Background(), p); err != nil {
+		t.Fatal(err)
+	}
+	id := p.ID.String()
+
+	rec := httptest.NewRecorder()
+	GetProductHandler(svc)(rec, newVersionedRequest(http.MethodGet, id, "", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"1"` {
+		t.Fatalf(`expected 200 with ETag "1", got %d with %q`, rec.Code, rec.Header().Get("ETag"))
+	}
+
+	rec = httptest.NewRecorder()
+	GetProductHandler(svc)(rec, newVersionedRequest(http.MethodGet, id, "", map[string]string{"If-None-Match": `W/"1"`}))
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("expected an empty 304 for a matching If-None-Match, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	UpdateProductHandler(svc)(rec, newVersionedRequest(http.MethodPut, id, `{"name":"Boots"}`, map[string]string{"If-Match": `"1"`}))
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"2"` {
+		t.Fatalf(`expected 200 with ETag "2", got %d with %q`, rec.Code, rec.Header().Get("ETag"))
+	}
+
+	rec = httptest.NewRecorder()
+	UpdateProductHandler(svc)(rec, newVersionedRequest(http.MethodPut, id, `{"name":"Sandals"}`, map[string]string{"If-Match": `"1"`}))
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("expected 412 for a stale If-Match, got %d", rec.Code)
+	}
+	if got, _ := svc.GetByID(context.Background(), p.ID); got.Name != "Boots" {
+		t.Errorf("expected the stale update to be rejected, got name %q", got.Name)
+	}
+
+	rec = httptest.NewRecorder()
+	UpdateProductHandler(svc)(rec, newVersionedRequest(http.MethodPut, id, `{"name":"Sandals"}`, nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"3"` {
+		t.Errorf(`expected an unconditional update to succeed with ETag "3", got %d with %q`, rec.Code, rec.Header().Get("ETag"))
+	}
+}
+
+func TestPackHandlers_ETags(t *testing.T) {
+	svc := &service.PackService{Repo: out.NewPackRepositoryMem()}
+	id := "7b1f5d7e-0c1a-4a43-9d3c-2f9b8a1f6e01"
+	put := func(body string, header map[string]string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := newVersionedRequest(http.MethodPut, id, body, header)
+		UpdatePacksForProductHandler(svc)(rec, req)
+		return rec
+	}
+
+	rec := put(`[250,500]`, map[string]string{"If-Match": `"1"`})
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"2"` {
+		t.Fatalf(`expected 200 with ETag "2", got %d with %q`, rec.Code, rec.Header().Get("ETag"))
+	}
+
+	// A second admin still editing version 1 must not overwrite the first one's packs.
+	if rec := put(`[1000]`, map[string]string{"If-Match": `"1"`}); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("expected 412 for a stale If-Match, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	ListPacksForProductHandler(svc)(rec, newVersionedRequest(http.MethodGet, id, "", nil))
+	var packs []model.Pack
+	if err := json.NewDecoder(rec.Body).Decode(&packs); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if rec.Header().Get("ETag") != `"2"` || len(packs) != 2 {
+		t.Errorf(`expected the 2 packs of version "2", got %d packs with ETag %q`, len(packs), rec.Header().Get("ETag"))
+	}
+
+	rec = httptest.NewRecorder()
+	ListPacksForProductHandler(svc)(rec, newVersionedRequest(http.MethodGet, id, "", map[string]string{"If-None-Match": `"1", "2"`}))
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("expected 304 when If-None-Match lists the current ETag, got %d", rec.Code)
+	}
+}
+
+func TestUpdatePacksForProductHandler_RejectsNonPositiveSizes(t *testing.T) {
+	svc := &service.PackService{Repo: out.NewPackRepositoryMem()}
+	id := "7b1f5d7e-0c1a-4a43-9d3c-2f9b8a1f6e01"
+
+	for _, body := range []string{`[0]`, `[250,-5]`} {
+		rec := httptest.NewRecorder()
+		UpdatePacksForProductHandler(svc)(rec, newVersionedRequest(http.MethodPut, id, body, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, rec.Code)
+		}
+	}
+	rec := httptest.NewRecorder()
+	ListPacksForProductHandler(svc)(rec, newVersionedRequest(http.MethodGet, id, "", nil))
+	var packs []model.Pack
+	if err := json.NewDecoder(rec.Body).Decode(&packs); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(packs) != 0 {
+		t.Errorf("expected no packs to be stored, got %d", len(packs))
+	}
+}
@@ -0,0 +1,183 @@
+package in
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+// ShipmentPlanRequest is an order to plan a shipment for. The mode and constraints are those of GET /fulfill.
+type ShipmentPlanRequest struct {
+	ProductID     uuid.UUID `json:"product_id"`
+	Quantity      int       `json:"quantity" example:"251"`
+	CustomerID    uuid.UUID `json:"customer_id,omitzero"`
+	Mode          string    `json:"mode,omitempty" enums:"over,under,exact_only"`
+	MaxOverage    *int      `json:"max_overage,omitempty"`
+	MaxOveragePct *float64  `json:"max_overage_pct,omitempty"`
+	MaxPacks      *int      `json:"max_packs,omitempty"`
+}
+
+// OrderPlanRequest is an order of several products to plan shipments for. The mode and constraints, those of
+// GET /fulfill, apply to every line.
+type OrderPlanRequest struct {
+	Lines         []service.OrderLine `json:"lines"`
+	CustomerID    uuid.UUID           `json:"customer_id,omitzero"`
+	Mode          string              `json:"mode,omitempty" enums:"over,under,exact_only"`
+	MaxOverage    *int                `json:"max_overage,omitempty"`
+	MaxOveragePct *float64            `json:"max_overage_pct,omitempty"`
+	MaxPacks      *int                `json:"max_packs,omitempty"`
+}
+
+// UnshippableResponse explains why the packs of an order cannot be put in cartons.
+type UnshippableResponse struct {
+	Status string `json:"status" example:"unshippable"`
+	Reason string `json:"reason"`
+	Line   int    `json:"line,omitempty" example:"2"` // order line at fault, if any
+}
+
+// OrderInfeasibleResponse is the InfeasibleResponse of the order line that cannot be fulfilled.
+type OrderInfeasibleResponse struct {
+	Line int `json:"line" example:"2"`
+	InfeasibleResponse
+}
+
+// PlanFailureResponse documents the 422 responses of the planning routes, which Swagger allows one schema
+// for: an InfeasibleResponse (an OrderInfeasibleResponse for orders) or an UnshippableResponse, told apart
+// by Status.
+type PlanFailureResponse struct {
+	Status      string   `json:"status" enums:"infeasible,unshippable"`
+	Reason      string   `json:"reason"`
+	Constraints []string `json:"constraints,omitempty"`      // Constraints that blocked every plan, if infeasible
+	MinOverage  int      `json:"min_overage,omitempty"`      // Smallest overage any plan achieves, if infeasible
+	MinPacks    int      `json:"min_packs,omitempty"`        // Fewest packs any plan uses, if infeasible
+	Line        int      `json:"line,omitempty" example:"2"` // Order line at fault, from POST /orders/plan
+}
+
+// PlanShipmentHandler godoc
+// @Summary Plan the cartons of a shipment
+// @Description Fulfills an order like GET /fulfill and assigns the packs to the fewest cartons of the catalog, then to the smallest ones. A carton takes packs that each fit its inner dimensions in some orientation, up to its volume and maximum weight; the arrangement of the packs inside is not planned. Every pack used must have dimensions and weight.
+// @Tags Fulfillment
+// @Accept json
+// @Produce json
+// @Param order body ShipmentPlanRequest true "Order to plan"
+// @Success 200 {object} service.ShipmentPlan
+// @Failure 400 {string} string "Invalid request, quantity, mode or constraint"
+// @Failure 404 {string} string "No packs found for product, or customer not found"
+// @Failure 422 {object} PlanFailureResponse "No plan satisfies the constraints (status infeasible), or the packs cannot be put in cartons (status unshippable)"
+// @Failure 429 {string} string "Too many requests; see Retry-After"
+// @Failure 503 {string} string "Solver exceeded its time budget"
+// @Router /shipments/plan [post]
+func PlanShipmentHandler(svc *service.ShipmentService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req ShipmentPlanRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.Error("Failed to decode shipment plan request", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		opts := service.FulfillmentOptions{
+			Mode:          req.Mode,
+			MaxOverage:    req.MaxOverage,
+			MaxOveragePct: req.MaxOveragePct,
+			MaxPacks:      req.MaxPacks,
+		}
+		if err := opts.Validate(); err != nil {
+			slog.Error("Invalid fulfillment constraints", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		plan, err := svc.Plan(r.Context(), req.ProductID, req.Quantity, req.CustomerID, opts)
+		var infeasible *service.InfeasibleError
+		switch {
+		case err == nil:
+		case errors.As(err, &infeasible):
+			slog.Info("Shipment infeasible", "product_id", req.ProductID, "quantity", req.Quantity, "constraints", infeasible.Constraints)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(InfeasibleResponse{Status: "infeasible", InfeasibleError: *infeasible})
+			return
+		case errors.Is(err, service.ErrUnshippable):
+			slog.Info("Shipment unshippable", "product_id", req.ProductID, "quantity", req.Quantity, "error", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(UnshippableResponse{Status: "unshippable", Reason: err.Error()})
+			return
+		default:
+			slog.Error("Failed to plan shipment", "product_id", req.ProductID, "quantity", req.Quantity, "error", err)
+			writeFulfillmentError(w, err)
+			return
+		}
+		slog.Info("Shipment planned", "product_id", req.ProductID, "quantity", req.Quantity, "cartons", plan.CartonCount)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(plan)
+	}
+}
+
+// PlanOrderHandler godoc
+// @Summary Plan the shipments of an order of several products
+// @Description Fulfills every line of an order like GET /fulfill, puts the packs of all lines together in the fewest cartons of the catalog like POST /shipments/plan, and groups the cartons into as few shipments as it finds within the configured shipment weight and volume limits. Cartons that do not fit a shipment alone are not used. Each product may appear in one line only; errors about a line report its 1-based number in line.
+// @Tags Fulfillment
+// @Accept json
+// @Produce json
+// @Param order body OrderPlanRequest true "Order to plan"
+// @Success 200 {object} service.OrderPlan
+// @Failure 400 {string} string "Invalid request, line, mode or constraint"
+// @Failure 404 {string} string "No packs found for a product, or customer not found"
+// @Failure 422 {object} PlanFailureResponse "No plan of a line satisfies the constraints (status infeasible), or the packs cannot be shipped (status unshippable)"
+// @Failure 429 {string} string "Too many requests; see Retry-After"
+// @Failure 503 {string} string "Solver exceeded its time budget"
+// @Router /orders/plan [post]
+func PlanOrderHandler(svc *service.ShipmentService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req OrderPlanRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.Error("Failed to decode order plan request", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		opts := service.FulfillmentOptions{
+			Mode:          req.Mode,
+			MaxOverage:    req.MaxOverage,
+			MaxOveragePct: req.MaxOveragePct,
+			MaxPacks:      req.MaxPacks,
+		}
+		if err := opts.Validate(); err != nil {
+			slog.Error("Invalid fulfillment constraints", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		plan, err := svc.PlanOrder(r.Context(), req.Lines, req.CustomerID, opts)
+		var lineErr *service.OrderLineError
+		var line int
+		if errors.As(err, &lineErr) {
+			line = lineErr.Line
+		}
+		var infeasible *service.InfeasibleError
+		switch {
+		case err == nil:
+		case errors.As(err, &infeasible):
+			slog.Info("Order infeasible", "line", line, "constraints", infeasible.Constraints)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(OrderInfeasibleResponse{Line: line, InfeasibleResponse: InfeasibleResponse{Status: "infeasible", InfeasibleError: *infeasible}})
+			return
+		case errors.Is(err, service.ErrUnshippable):
+			slog.Info("Order unshippable", "line", line, "error", err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(UnshippableResponse{Status: "unshippable", Reason: err.Error(), Line: line})
+			return
+		default:
+			slog.Error("Failed to plan order", "lines", len(req.Lines), "error", err)
+			writeFulfillmentError(w, err)
+			return
+		}
+		slog.Info("Order planned", "lines", len(req.Lines), "shipments", plan.ShipmentCount, "cartons", plan.CartonCount)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(plan)
+	}
+}
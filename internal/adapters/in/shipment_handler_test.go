@@ -0,0 +1,32 @@
+package in
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+func TestPlanFailureResponse_DocumentsEveryFailure(t *testing.T) {
+	infeasible := InfeasibleResponse{Status: "infeasible", InfeasibleError: service.InfeasibleError{
+		Constraints: []string{"max_packs"}, Reason: "needs 3 packs", MinOverage: 0, MinPacks: 3,
+	}}
+	responses := []any{
+		infeasible,
+		OrderInfeasibleResponse{Line: 2, InfeasibleResponse: infeasible},
+		UnshippableResponse{Status: "unshippable", Reason: "pack 5000 fits no carton", Line: 2},
+	}
+	for _, resp := range responses {
+		body, err := json.Marshal(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.DisallowUnknownFields()
+		var doc PlanFailureResponse
+		if err := dec.Decode(&doc); err != nil {
+			t.Errorf("%T is not covered by PlanFailureResponse: %v", resp, err)
+		}
+	}
+}
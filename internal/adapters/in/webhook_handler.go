@@ -0,0 +1,231 @@
+package in
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+// defaultDeliveryLogLimit bounds the delivery log returned when no limit is given.
+const defaultDeliveryLogLimit = 100
+
+// CreateWebhookHandler godoc
+// @Summary Subscribe a webhook
+// @Description Subscribe a URL to domain events. An empty event_types subscribes to every event. Each delivery is posted as the JSON event with Webhook-Id, Webhook-Event, Webhook-Timestamp and Webhook-Signature headers, the signature being sha256= followed by the hex HMAC-SHA256 of the timestamp, a dot and the body, keyed with the secret. A secret is generated when none is given; it is only returned by this call. URLs whose host is or resolves to a loopback, private or link-local address are rejected unless the server allows private targets.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body model.WebhookSubscription true "Subscription to create"
+// @Param Idempotency-Key header string false "Unique key making retries of the request return the original response"
+// @Success 201 {object} model.WebhookSubscription
+// @Failure 400 {string} string "Invalid subscription, or a URL that is not public"
+// @Failure 500 {string} string "Internal server error"
+// @Router /webhooks [post]
+func CreateWebhookHandler(svc *service.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var sub model.WebhookSubscription
+		if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+			slog.Error("Failed to decode webhook subscription", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := svc.Create(r.Context(), &sub); err != nil {
+			slog.Error("Failed to create webhook subscription", "error", err)
+			writeWebhookError(w, err)
+			return
+		}
+		slog.Info("Webhook subscription created", "id", sub.ID, "url", sub.URL, "event_types", sub.EventTypes)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(sub)
+	}
+}
+
+// GetWebhookHandler godoc
+// @Summary Get a webhook subscription by ID
+// @Description Get a webhook subscription by UUID, without its secret
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Subscription UUID"
+// @Success 200 {object} model.WebhookSubscription
+// @Failure 400 {string} string "Invalid subscription ID"
+// @Failure 404 {string} string "Subscription not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /webhooks/{id} [get]
+func GetWebhookHandler(svc *service.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			slog.Error("Invalid webhook subscription ID", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		sub, err := svc.GetByID(r.Context(), id)
+		if err != nil {
+			slog.Error("Failed to get webhook subscription", "id", id, "error", err)
+			writeWebhookError(w, err)
+			return
+		}
+		sub.Secret = ""
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(sub)
+	}
+}
+
+// DeleteWebhookHandler godoc
+// @Summary Delete a webhook subscription
+// @Description Unsubscribe a webhook and drop its delivery log
+// @Tags Webhooks
+// @Param id path string true "Subscription UUID"
+// @Param Idempotency-Key header string false "Unique key making retries of the request return the original response"
+// @Success 204 {string} string "Subscription deleted"
+// @Failure 400 {string} string "Invalid subscription ID"
+// @Failure 404 {string} string "Subscription not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /webhooks/{id} [delete]
+func DeleteWebhookHandler(svc *service.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			slog.Error("Invalid webhook subscription ID", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := svc.Delete(r.Context(), id); err != nil {
+			slog.Error("Failed to delete webhook subscription", "id", id, "error", err)
+			writeWebhookError(w, err)
+			return
+		}
+		slog.Info("Webhook subscription deleted", "id", id)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// ListWebhooksHandler godoc
+// @Summary List webhook subscriptions
+// @Description Get all webhook subscriptions, without their secrets
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {array} model.WebhookSubscription
+// @Failure 500 {string} string "Internal server error"
+// @Router /webhooks [get]
+func ListWebhooksHandler(svc *service.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		subs, err := svc.List(r.Context())
+		if err != nil {
+			slog.Error("Failed to list webhook subscriptions", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		for _, sub := range subs {
+			sub.Secret = ""
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(subs)
+	}
+}
+
+// ListWebhookDeliveriesHandler godoc
+// @Summary Get the delivery log of a webhook subscription
+// @Description List a subscription's deliveries, newest first, with their status, attempts, last error and next attempt. A failed delivery is retried with exponential backoff and marked dead after the configured number of attempts.
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Subscription UUID"
+// @Param status query string false "Only deliveries with this status" Enums(pending, delivered, dead)
+// @Param limit query int false "Maximum number of deliveries (default 100)"
+// @Success 200 {array} model.WebhookDelivery
+// @Failure 400 {string} string "Invalid subscription ID, status or limit"
+// @Failure 404 {string} string "Subscription not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /webhooks/{id}/deliveries [get]
+func ListWebhookDeliveriesHandler(svc *service.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			slog.Error("Invalid webhook subscription ID", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		status := r.URL.Query().Get("status")
+		switch status {
+		case "", model.DeliveryPending, model.DeliveryDelivered, model.DeliveryDead:
+		default:
+			slog.Error("Invalid webhook delivery status", "status", status)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		limit := defaultDeliveryLogLimit
+		if s := r.URL.Query().Get("limit"); s != "" {
+			if limit, err = strconv.Atoi(s); err != nil || limit <= 0 {
+				slog.Error("Invalid webhook delivery limit", "limit", s)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		deliveries, err := svc.Deliveries(r.Context(), id, status, limit)
+		if err != nil {
+			slog.Error("Failed to list webhook deliveries", "id", id, "error", err)
+			writeWebhookError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(deliveries)
+	}
+}
+
+// RetryWebhookDeliveryHandler godoc
+// @Summary Retry a webhook delivery
+// @Description Schedule a delivery, typically a dead one, for a new round of attempts starting now
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Subscription UUID"
+// @Param deliveryID path string true "Delivery UUID"
+// @Param Idempotency-Key header string false "Unique key making retries of the request return the original response"
+// @Success 202 {object} model.WebhookDelivery
+// @Failure 400 {string} string "Invalid subscription or delivery ID"
+// @Failure 404 {string} string "Subscription or delivery not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /webhooks/{id}/deliveries/{deliveryID}/retry [post]
+func RetryWebhookDeliveryHandler(svc *service.WebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			slog.Error("Invalid webhook subscription ID", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		deliveryID, err := uuid.Parse(r.PathValue("deliveryID"))
+		if err != nil {
+			slog.Error("Invalid webhook delivery ID", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		d, err := svc.Redeliver(r.Context(), id, deliveryID)
+		if err != nil {
+			slog.Error("Failed to retry webhook delivery", "id", id, "delivery_id", deliveryID, "error", err)
+			writeWebhookError(w, err)
+			return
+		}
+		slog.Info("Webhook delivery rescheduled", "id", id, "delivery_id", deliveryID)
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(d)
+	}
+}
+
+// writeWebhookError maps webhook service errors to status codes.
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidWebhook):
+		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, port.ErrWebhookNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,72 @@
+package out
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+// CartonRepositoryMem is an in-memory implementation of CartonRepository. Cartons are copied on the way in
+// and out so callers cannot modify stored cartons.
+type CartonRepositoryMem struct {
+	mu      sync.RWMutex
+	cartons map[uuid.UUID]model.Carton
+}
+
+// NewCartonRepositoryMem creates a new in-memory carton repository.
+func NewCartonRepositoryMem() *CartonRepositoryMem {
+	return &CartonRepositoryMem{
+		cartons: make(map[uuid.UUID]model.Carton),
+	}
+}
+
+func (r *CartonRepositoryMem) Create(_ context.Context, carton *model.Carton) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	carton.ID = uuid.New()
+	r.cartons[carton.ID] = *carton
+	return nil
+}
+
+func (r *CartonRepositoryMem) GetByID(_ context.Context, id uuid.UUID) (*model.Carton, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.cartons[id]
+	if !ok {
+		return nil, port.ErrCartonNotFound
+	}
+	return &c, nil
+}
+
+func (r *CartonRepositoryMem) Update(_ context.Context, carton *model.Carton) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.cartons[carton.ID]; !ok {
+		return port.ErrCartonNotFound
+	}
+	r.cartons[carton.ID] = *carton
+	return nil
+}
+
+func (r *CartonRepositoryMem) Delete(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.cartons[id]; !ok {
+		return port.ErrCartonNotFound
+	}
+	delete(r.cartons, id)
+	return nil
+}
+
+func (r *CartonRepositoryMem) List(_ context.Context) ([]*model.Carton, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cartons := make([]*model.Carton, 0, len(r.cartons))
+	for _, c := range r.cartons {
+		cartons = append(cartons, &c)
+	}
+	return cartons, nil
+}
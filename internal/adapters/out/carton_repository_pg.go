@@ -0,0 +1,97 @@
+package out
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+// cartonColumns are the columns scanned by scanCarton.
+const cartonColumns = "id, name, inner_length_mm, inner_width_mm, inner_height_mm, max_weight_g"
+
+type CartonRepositoryPg struct {
+	DB      *sql.DB
+	Metrics port.QueryMetrics
+}
+
+func (r *CartonRepositoryPg) Create(ctx context.Context, carton *model.Carton) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "cartons", "create")
+	defer end(&err)
+	return conn(ctx, r.DB).QueryRowContext(ctx, `INSERT INTO cartons(name, inner_length_mm, inner_width_mm, inner_height_mm, max_weight_g)
+		VALUES($1, $2, $3, $4, $5) RETURNING id`,
+		carton.Name, carton.Inner.LengthMM, carton.Inner.WidthMM, carton.Inner.HeightMM, carton.MaxWeightG).Scan(&carton.ID)
+}
+
+func (r *CartonRepositoryPg) GetByID(ctx context.Context, id uuid.UUID) (_ *model.Carton, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "cartons", "get_by_id")
+	defer end(&err)
+	c, err := scanCarton(conn(ctx, r.DB).QueryRowContext(ctx, "SELECT "+cartonColumns+" FROM cartons WHERE id=$1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, port.ErrCartonNotFound
+	}
+	return c, err
+}
+
+func (r *CartonRepositoryPg) Update(ctx context.Context, carton *model.Carton) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "cartons", "update")
+	defer end(&err)
+	res, err := conn(ctx, r.DB).ExecContext(ctx, `UPDATE cartons SET name=$1, inner_length_mm=$2, inner_width_mm=$3, inner_height_mm=$4,
+		max_weight_g=$5 WHERE id=$6`,
+		carton.Name, carton.Inner.LengthMM, carton.Inner.WidthMM, carton.Inner.HeightMM, carton.MaxWeightG, carton.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return port.ErrCartonNotFound
+	}
+	return nil
+}
+
+func (r *CartonRepositoryPg) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "cartons", "delete")
+	defer end(&err)
+	res, err := conn(ctx, r.DB).ExecContext(ctx, "DELETE FROM cartons WHERE id=$1", id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return port.ErrCartonNotFound
+	}
+	return nil
+}
+
+func (r *CartonRepositoryPg) List(ctx context.Context) (_ []*model.Carton, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "cartons", "list")
+	defer end(&err)
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, "SELECT "+cartonColumns+" FROM cartons")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var cartons []*model.Carton
+	for rows.Next() {
+		c, err := scanCarton(rows)
+		if err != nil {
+			return nil, err
+		}
+		cartons = append(cartons, c)
+	}
+	return cartons, rows.Err()
+}
+
+// scanCarton scans a row of cartonColumns.
+func scanCarton(row interface{ Scan(dest ...any) error }) (*model.Carton, error) {
+	c := &model.Carton{}
+	if err := row.Scan(&c.ID, &c.Name, &c.Inner.LengthMM, &c.Inner.WidthMM, &c.Inner.HeightMM, &c.MaxWeightG); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,84 @@
+package out
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+// CustomerRepositoryMem is an in-memory implementation of CustomerRepository. Customers are copied on the
+// way in and out so callers cannot modify stored pack rules.
+type CustomerRepositoryMem struct {
+	mu        sync.RWMutex
+	customers map[uuid.UUID]*model.Customer
+}
+
+// NewCustomerRepositoryMem creates a new in-memory customer repository.
+func NewCustomerRepositoryMem() *CustomerRepositoryMem {
+	return &CustomerRepositoryMem{
+		customers: make(map[uuid.UUID]*model.Customer),
+	}
+}
+
+func (r *CustomerRepositoryMem) Create(_ context.Context, customer *model.Customer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	customer.ID = uuid.New()
+	r.customers[customer.ID] = copyCustomer(customer)
+	return nil
+}
+
+func (r *CustomerRepositoryMem) GetByID(_ context.Context, id uuid.UUID) (*model.Customer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.customers[id]
+	if !ok {
+		return nil, port.ErrCustomerNotFound
+	}
+	return copyCustomer(c), nil
+}
+
+func (r *CustomerRepositoryMem) Update(_ context.Context, customer *model.Customer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.customers[customer.ID]; !ok {
+		return port.ErrCustomerNotFound
+	}
+	r.customers[customer.ID] = copyCustomer(customer)
+	return nil
+}
+
+func (r *CustomerRepositoryMem) Delete(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.customers[id]; !ok {
+		return port.ErrCustomerNotFound
+	}
+	delete(r.customers, id)
+	return nil
+}
+
+func (r *CustomerRepositoryMem) List(_ context.Context) ([]*model.Customer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	customers := make([]*model.Customer, 0, len(r.customers))
+	for _, c := range r.customers {
+		customers = append(customers, copyCustomer(c))
+	}
+	return customers, nil
+}
+
+func copyCustomer(c *model.Customer) *model.Customer {
+	cp := *c
+	cp.PackRules = make([]model.PackRule, len(c.PackRules))
+	for i, rule := range c.PackRules {
+		rule.AllowedSizes = slices.Clone(rule.AllowedSizes)
+		rule.ForbiddenSizes = slices.Clone(rule.ForbiddenSizes)
+		cp.PackRules[i] = rule
+	}
+	return &cp
+}
@@ -0,0 +1,168 @@
+package out
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+type CustomerRepositoryPg struct {
+	DB      *sql.DB
+	Metrics port.QueryMetrics
+}
+
+func (r *CustomerRepositoryPg) Create(ctx context.Context, customer *model.Customer) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "customers", "create")
+	defer end(&err)
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, "INSERT INTO customers(name) VALUES($1) RETURNING id", customer.Name).Scan(&customer.ID); err != nil {
+			return err
+		}
+		return insertPackRules(ctx, tx, customer)
+	})
+}
+
+func (r *CustomerRepositoryPg) GetByID(ctx context.Context, id uuid.UUID) (_ *model.Customer, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "customers", "get_by_id")
+	defer end(&err)
+	c := &model.Customer{}
+	row := conn(ctx, r.DB).QueryRowContext(ctx, "SELECT id, name FROM customers WHERE id=$1", id)
+	if err := row.Scan(&c.ID, &c.Name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, port.ErrCustomerNotFound
+		}
+		return nil, err
+	}
+	rules, err := r.listPackRules(ctx, "WHERE customer_id=$1", id)
+	if err != nil {
+		return nil, err
+	}
+	c.PackRules = append([]model.PackRule{}, rules[c.ID]...)
+	return c, nil
+}
+
+func (r *CustomerRepositoryPg) Update(ctx context.Context, customer *model.Customer) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "customers", "update")
+	defer end(&err)
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, "UPDATE customers SET name=$1 WHERE id=$2", customer.Name, customer.ID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return port.ErrCustomerNotFound
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM customer_pack_rules WHERE customer_id=$1", customer.ID); err != nil {
+			return err
+		}
+		return insertPackRules(ctx, tx, customer)
+	})
+}
+
+func (r *CustomerRepositoryPg) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "customers", "delete")
+	defer end(&err)
+	res, err := conn(ctx, r.DB).ExecContext(ctx, "DELETE FROM customers WHERE id=$1", id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return port.ErrCustomerNotFound
+	}
+	return nil
+}
+
+func (r *CustomerRepositoryPg) List(ctx context.Context) (_ []*model.Customer, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "customers", "list")
+	defer end(&err)
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, "SELECT id, name FROM customers")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var customers []*model.Customer
+	for rows.Next() {
+		c := &model.Customer{}
+		if err := rows.Scan(&c.ID, &c.Name); err != nil {
+			return nil, err
+		}
+		customers = append(customers, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rules, err := r.listPackRules(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range customers {
+		c.PackRules = append([]model.PackRule{}, rules[c.ID]...)
+	}
+	return customers, nil
+}
+
+// listPackRules returns the pack rules matching where, grouped by customer.
+func (r *CustomerRepositoryPg) listPackRules(ctx context.Context, where string, args ...any) (map[uuid.UUID][]model.PackRule, error) {
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, `SELECT customer_id, product_id, allowed_sizes, forbidden_sizes, max_pack_size
+		FROM customer_pack_rules `+where+` ORDER BY product_id NULLS LAST`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	rules := map[uuid.UUID][]model.PackRule{}
+	for rows.Next() {
+		var customerID uuid.UUID
+		var productID uuid.NullUUID
+		var allowed, forbidden pq.Int64Array
+		var rule model.PackRule
+		if err := rows.Scan(&customerID, &productID, &allowed, &forbidden, &rule.MaxPackSize); err != nil {
+			return nil, err
+		}
+		rule.ProductID = productID.UUID
+		rule.AllowedSizes = fromInt64s(allowed)
+		rule.ForbiddenSizes = fromInt64s(forbidden)
+		rules[customerID] = append(rules[customerID], rule)
+	}
+	return rules, rows.Err()
+}
+
+func insertPackRules(ctx context.Context, tx *sql.Tx, customer *model.Customer) error {
+	for _, rule := range customer.PackRules {
+		productID := uuid.NullUUID{UUID: rule.ProductID, Valid: rule.ProductID != uuid.Nil}
+		_, err := tx.ExecContext(ctx, `INSERT INTO customer_pack_rules(customer_id, product_id, allowed_sizes, forbidden_sizes, max_pack_size)
+			VALUES($1, $2, $3, $4, $5)`,
+			customer.ID, productID, toInt64s(rule.AllowedSizes), toInt64s(rule.ForbiddenSizes), rule.MaxPackSize)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toInt64s(sizes []int) pq.Int64Array {
+	out := make(pq.Int64Array, len(sizes))
+	for i, size := range sizes {
+		out[i] = int64(size)
+	}
+	return out
+}
+
+func fromInt64s(values pq.Int64Array) []int {
+	if len(values) == 0 {
+		return nil
+	}
+	sizes := make([]int, len(values))
+	for i, v := range values {
+		sizes[i] = int(v)
+	}
+	return sizes
+}
@@ -0,0 +1,66 @@
+package out
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+)
+
+// IdempotencyRepositoryMem is an in-memory implementation of IdempotencyRepository.
+type IdempotencyRepositoryMem struct {
+	mu      sync.Mutex
+	records map[string]*model.IdempotencyRecord
+}
+
+// NewIdempotencyRepositoryMem creates a new in-memory idempotency repository.
+func NewIdempotencyRepositoryMem() *IdempotencyRepositoryMem {
+	return &IdempotencyRepositoryMem{
+		records: make(map[string]*model.IdempotencyRecord),
+	}
+}
+
+func (r *IdempotencyRepositoryMem) Reserve(_ context.Context, record *model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.records[record.Key]; ok && existing.ExpiresAt.After(record.CreatedAt) {
+		copied := *existing
+		return &copied, nil
+	}
+	stored := *record
+	r.records[record.Key] = &stored
+	return nil, nil
+}
+
+func (r *IdempotencyRepositoryMem) Complete(_ context.Context, record *model.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.records[record.Key]; ok {
+		stored.StatusCode = record.StatusCode
+		stored.Header = maps.Clone(record.Header)
+		stored.Body = record.Body
+	}
+	return nil
+}
+
+func (r *IdempotencyRepositoryMem) Release(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.records, key)
+	return nil
+}
+
+func (r *IdempotencyRepositoryMem) DeleteExpired(_ context.Context, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for key, record := range r.records {
+		if !record.ExpiresAt.After(now) {
+			delete(r.records, key)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,82 @@
+package out
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+type IdempotencyRepositoryPg struct {
+	DB      *sql.DB
+	Metrics port.QueryMetrics
+}
+
+func (r *IdempotencyRepositoryPg) Reserve(ctx context.Context, record *model.IdempotencyRecord) (_ *model.IdempotencyRecord, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "idempotency_keys", "reserve")
+	defer end(&err)
+	// A record that disappears between the insert and the select was released or purged, so try again.
+	for range 3 {
+		var key string
+		err := r.DB.QueryRowContext(ctx, `INSERT INTO idempotency_keys(key, fingerprint, created_at, expires_at) VALUES($1, $2, $3, $4)
+			ON CONFLICT (key) DO UPDATE SET fingerprint=EXCLUDED.fingerprint, status_code=0, header='{}', body=NULL,
+				created_at=EXCLUDED.created_at, expires_at=EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+			RETURNING key`, record.Key, record.Fingerprint, record.CreatedAt, record.ExpiresAt).Scan(&key)
+		if err == nil {
+			return nil, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		existing := &model.IdempotencyRecord{}
+		var header []byte
+		err = r.DB.QueryRowContext(ctx, `SELECT key, fingerprint, status_code, header, body, created_at, expires_at
+			FROM idempotency_keys WHERE key=$1`, record.Key).Scan(
+			&existing.Key, &existing.Fingerprint, &existing.StatusCode, &header, &existing.Body, &existing.CreatedAt, &existing.ExpiresAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(header, &existing.Header); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}
+	return nil, errors.New("idempotency key changed concurrently")
+}
+
+func (r *IdempotencyRepositoryPg) Complete(ctx context.Context, record *model.IdempotencyRecord) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "idempotency_keys", "complete")
+	defer end(&err)
+	header, err := json.Marshal(record.Header)
+	if err != nil {
+		return err
+	}
+	_, err = r.DB.ExecContext(ctx, "UPDATE idempotency_keys SET status_code=$1, header=$2, body=$3 WHERE key=$4",
+		record.StatusCode, header, record.Body, record.Key)
+	return err
+}
+
+func (r *IdempotencyRepositoryPg) Release(ctx context.Context, key string) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "idempotency_keys", "release")
+	defer end(&err)
+	_, err = r.DB.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE key=$1", key)
+	return err
+}
+
+func (r *IdempotencyRepositoryPg) DeleteExpired(ctx context.Context, now time.Time) (_ int64, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "idempotency_keys", "delete_expired")
+	defer end(&err)
+	res, err := r.DB.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= $1", now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
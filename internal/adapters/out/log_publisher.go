@@ -0,0 +1,22 @@
+package out
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+)
+
+// LogPublisher publishes events to the debug log, which is mostly useful in development.
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(ctx context.Context, event *model.Event) error {
+	slog.DebugContext(ctx, "Event published", "id", event.ID, "type", event.Type, "product_id", event.ProductID,
+		"payload", string(event.Payload))
+	return nil
+}
+
+// Wants reports whether the debug log is enabled.
+func (LogPublisher) Wants(ctx context.Context, _ string) bool {
+	return slog.Default().Enabled(ctx, slog.LevelDebug)
+}
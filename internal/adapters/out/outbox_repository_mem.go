@@ -0,0 +1,84 @@
+package out
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+)
+
+// OutboxRepositoryMem is an in-memory implementation of OutboxRepository: a queue of pending events, from
+// which published events are dropped.
+type OutboxRepositoryMem struct {
+	mu      sync.Mutex
+	pending []*outboxEntry
+}
+
+type outboxEntry struct {
+	event        *model.Event
+	publishedTo  []string
+	claimedUntil time.Time
+	attempts     int
+	lastError    string
+}
+
+// NewOutboxRepositoryMem creates a new in-memory outbox.
+func NewOutboxRepositoryMem() *OutboxRepositoryMem {
+	return &OutboxRepositoryMem{}
+}
+
+func (r *OutboxRepositoryMem) Append(_ context.Context, events ...*model.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range events {
+		r.pending = append(r.pending, &outboxEntry{event: e})
+	}
+	return nil
+}
+
+func (r *OutboxRepositoryMem) Claim(_ context.Context, limit int, until time.Time) ([]*model.OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	var events []*model.OutboxEvent
+	for _, entry := range r.pending {
+		if len(events) == limit {
+			break
+		}
+		if entry.claimedUntil.After(now) {
+			continue
+		}
+		entry.claimedUntil = until
+		events = append(events, &model.OutboxEvent{Event: entry.event, PublishedTo: slices.Clone(entry.publishedTo)})
+	}
+	return events, nil
+}
+
+func (r *OutboxRepositoryMem) MarkPublished(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, entry := range r.pending {
+		if entry.event.ID == id {
+			r.pending = append(r.pending[:i], r.pending[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (r *OutboxRepositoryMem) RecordFailure(_ context.Context, id uuid.UUID, publishedTo []string, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, entry := range r.pending {
+		if entry.event.ID == id {
+			entry.attempts++
+			entry.publishedTo = slices.Clone(publishedTo)
+			entry.lastError = reason
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package out
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+type OutboxRepositoryPg struct {
+	DB      *sql.DB
+	Metrics port.QueryMetrics
+}
+
+func (r *OutboxRepositoryPg) Append(ctx context.Context, events ...*model.Event) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "outbox", "append")
+	defer end(&err)
+	for _, e := range events {
+		_, err := conn(ctx, r.DB).ExecContext(ctx, "INSERT INTO outbox(id, type, product_id, occurred_at, payload) VALUES($1, $2, $3, $4, $5)",
+			e.ID, e.Type, e.ProductID, e.OccurredAt, []byte(e.Payload))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *OutboxRepositoryPg) Claim(ctx context.Context, limit int, until time.Time) (_ []*model.OutboxEvent, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "outbox", "claim")
+	defer end(&err)
+	// SKIP LOCKED lets several relays claim disjoint batches.
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, `WITH claimed AS (
+			UPDATE outbox SET claimed_until=$2 WHERE seq IN (
+				SELECT seq FROM outbox WHERE (claimed_until IS NULL OR claimed_until < now())
+				ORDER BY seq LIMIT $1 FOR UPDATE SKIP LOCKED)
+			RETURNING seq, id, type, product_id, occurred_at, payload, published_to)
+		SELECT id, type, product_id, occurred_at, payload, published_to FROM claimed ORDER BY seq`, limit, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var events []*model.OutboxEvent
+	for rows.Next() {
+		e := &model.OutboxEvent{Event: &model.Event{}}
+		if err := rows.Scan(&e.ID, &e.Type, &e.ProductID, &e.OccurredAt, (*[]byte)(&e.Payload), pq.Array(&e.PublishedTo)); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (r *OutboxRepositoryPg) MarkPublished(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "outbox", "mark_published")
+	defer end(&err)
+	_, err = conn(ctx, r.DB).ExecContext(ctx, "DELETE FROM outbox WHERE id=$1", id)
+	return err
+}
+
+func (r *OutboxRepositoryPg) RecordFailure(ctx context.Context, id uuid.UUID, publishedTo []string, reason string) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "outbox", "record_failure")
+	defer end(&err)
+	_, err = conn(ctx, r.DB).ExecContext(ctx, "UPDATE outbox SET attempts=attempts+1, last_error=$1, published_to=$2 WHERE id=$3",
+		reason, pq.Array(publishedTo), id)
+	return err
+}
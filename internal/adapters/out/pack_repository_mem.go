@@ -1,29 +1,31 @@
 package out
 
 import (
-	"errors"
+	"context"
 	"sync"
 
 	"github.com/google/uuid"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
 )
 
-var ErrPackNotFound = errors.New("pack not found")
-
-// PackRepositoryMem is an in-memory implementation of PackRepository.
+// PackRepositoryMem is an in-memory implementation of PackRepository. It does not know which products
+// exist, so every product ID has a pack set, empty and at version 1 until packs are added.
 type PackRepositoryMem struct {
-	mu    sync.RWMutex
-	packs map[uuid.UUID]*model.Pack
+	mu       sync.RWMutex
+	packs    map[uuid.UUID]*model.Pack
+	versions map[uuid.UUID]int64 // pack set versions above 1, by product ID
 }
 
 // NewPackRepositoryMem creates a new in-memory pack repository.
 func NewPackRepositoryMem() *PackRepositoryMem {
 	return &PackRepositoryMem{
-		packs: make(map[uuid.UUID]*model.Pack),
+		packs:    make(map[uuid.UUID]*model.Pack),
+		versions: make(map[uuid.UUID]int64),
 	}
 }
 
-func (r *PackRepositoryMem) Create(pack *model.Pack) error {
+func (r *PackRepositoryMem) Create(_ context.Context, pack *model.Pack) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	pack.ID = uuid.New()
@@ -31,37 +33,38 @@ func (r *PackRepositoryMem) Create(pack *model.Pack) error {
 	return nil
 }
 
-func (r *PackRepositoryMem) GetByID(id uuid.UUID) (*model.Pack, error) {
+func (r *PackRepositoryMem) GetByID(_ context.Context, id uuid.UUID) (*model.Pack, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	p, ok := r.packs[id]
 	if !ok {
-		return nil, ErrPackNotFound
+		return nil, port.ErrPackNotFound
 	}
 	return p, nil
 }
 
-func (r *PackRepositoryMem) Update(pack *model.Pack) error {
+func (r *PackRepositoryMem) Update(_ context.Context, pack *model.Pack) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if _, ok := r.packs[pack.ID]; !ok {
-		return ErrPackNotFound
+		return port.ErrPackNotFound
 	}
 	r.packs[pack.ID] = pack
+	r.versions[pack.ProductID] = r.packSet(pack.ProductID).Version + 1
 	return nil
 }
 
-func (r *PackRepositoryMem) Delete(id uuid.UUID) error {
+func (r *PackRepositoryMem) Delete(_ context.Context, id uuid.UUID) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if _, ok := r.packs[id]; !ok {
-		return ErrPackNotFound
+		return port.ErrPackNotFound
 	}
 	delete(r.packs, id)
 	return nil
 }
 
-func (r *PackRepositoryMem) DeleteByProduct(productID uuid.UUID) error {
+func (r *PackRepositoryMem) DeleteByProduct(_ context.Context, productID uuid.UUID) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	for id, pack := range r.packs {
@@ -72,14 +75,59 @@ func (r *PackRepositoryMem) DeleteByProduct(productID uuid.UUID) error {
 	return nil
 }
 
-func (r *PackRepositoryMem) ListByProduct(productID uuid.UUID) ([]*model.Pack, error) {
+func (r *PackRepositoryMem) ListByProduct(_ context.Context, productID uuid.UUID) ([]*model.Pack, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.listByProduct(productID), nil
+}
+
+func (r *PackRepositoryMem) GetPackSet(_ context.Context, productID uuid.UUID) (*model.PackSet, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
+	return r.packSet(productID), nil
+}
+
+func (r *PackRepositoryMem) ReplacePackSet(_ context.Context, productID uuid.UUID, sizes []int, expectedVersion int64) (_, _ *model.PackSet, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old := r.packSet(productID)
+	if expectedVersion != 0 && expectedVersion != old.Version {
+		return nil, nil, port.ErrVersionConflict
+	}
+	measured := map[int]*model.Pack{}
+	for _, pack := range old.Packs {
+		delete(r.packs, pack.ID)
+		measured[pack.Size] = pack
+	}
+	replaced := &model.PackSet{ProductID: productID, Version: old.Version + 1}
+	for _, size := range sizes {
+		pack := &model.Pack{ID: uuid.New(), ProductID: productID, Size: size}
+		if m := measured[size]; m != nil {
+			pack.Dimensions, pack.WeightG = m.Dimensions, m.WeightG
+		}
+		r.packs[pack.ID] = pack
+		replaced.Packs = append(replaced.Packs, pack)
+	}
+	r.versions[productID] = replaced.Version
+	return old, replaced, nil
+}
+
+// listByProduct returns productID's packs. Callers must hold r.mu.
+func (r *PackRepositoryMem) listByProduct(productID uuid.UUID) []*model.Pack {
 	var packs []*model.Pack
 	for _, pack := range r.packs {
 		if pack.ProductID == productID {
 			packs = append(packs, pack)
 		}
 	}
-	return packs, nil
+	return packs
+}
+
+// packSet returns productID's pack set. Callers must hold r.mu.
+func (r *PackRepositoryMem) packSet(productID uuid.UUID) *model.PackSet {
+	version, ok := r.versions[productID]
+	if !ok {
+		version = 1
+	}
+	return &model.PackSet{ProductID: productID, Version: version, Packs: r.listByProduct(productID)}
 }
@@ -1,57 +1,209 @@
 package out
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 
 	"github.com/google/uuid"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
 )
 
+// packColumns are the columns scanned by scanPack.
+const packColumns = "id, product_id, size, length_mm, width_mm, height_mm, weight_g"
+
 type PackRepositoryPg struct {
-	DB *sql.DB
+	DB      *sql.DB
+	Metrics port.QueryMetrics
 }
 
-func (r *PackRepositoryPg) Create(pack *model.Pack) error {
-	return r.DB.QueryRow("INSERT INTO packs(product_id, size) VALUES($1, $2) RETURNING id", pack.ProductID, pack.Size).Scan(&pack.ID)
+func (r *PackRepositoryPg) Create(ctx context.Context, pack *model.Pack) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "packs", "create")
+	defer end(&err)
+	length, width, height, weight := packMeasurements(pack)
+	return conn(ctx, r.DB).QueryRowContext(ctx, `INSERT INTO packs(product_id, size, length_mm, width_mm, height_mm, weight_g)
+		VALUES($1, $2, $3, $4, $5, $6) RETURNING id`, pack.ProductID, pack.Size, length, width, height, weight).Scan(&pack.ID)
 }
 
-func (r *PackRepositoryPg) GetByID(id uuid.UUID) (*model.Pack, error) {
-	p := &model.Pack{}
-	row := r.DB.QueryRow("SELECT id, product_id, size FROM packs WHERE id=$1", id)
-	if err := row.Scan(&p.ID, &p.ProductID, &p.Size); err != nil {
-		return nil, err
+func (r *PackRepositoryPg) GetByID(ctx context.Context, id uuid.UUID) (_ *model.Pack, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "packs", "get_by_id")
+	defer end(&err)
+	p, err := scanPack(conn(ctx, r.DB).QueryRowContext(ctx, "SELECT "+packColumns+" FROM packs WHERE id=$1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, port.ErrPackNotFound
 	}
-	return p, nil
+	return p, err
 }
 
-func (r *PackRepositoryPg) Update(pack *model.Pack) error {
-	_, err := r.DB.Exec("UPDATE packs SET product_id=$1, size=$2 WHERE id=$3", pack.ProductID, pack.Size, pack.ID)
-	return err
+func (r *PackRepositoryPg) Update(ctx context.Context, pack *model.Pack) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "packs", "update")
+	defer end(&err)
+	length, width, height, weight := packMeasurements(pack)
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `UPDATE packs SET product_id=$1, size=$2, length_mm=$3, width_mm=$4, height_mm=$5, weight_g=$6
+			WHERE id=$7`, pack.ProductID, pack.Size, length, width, height, weight, pack.ID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return port.ErrPackNotFound
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE products SET packs_version=packs_version+1 WHERE id=$1", pack.ProductID)
+		return err
+	})
 }
 
-func (r *PackRepositoryPg) Delete(id uuid.UUID) error {
-	_, err := r.DB.Exec("DELETE FROM packs WHERE id=$1", id)
+func (r *PackRepositoryPg) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "packs", "delete")
+	defer end(&err)
+	_, err = conn(ctx, r.DB).ExecContext(ctx, "DELETE FROM packs WHERE id=$1", id)
 	return err
 }
 
-func (r *PackRepositoryPg) DeleteByProduct(productID uuid.UUID) error {
-	_, err := r.DB.Exec("DELETE FROM packs WHERE product_id=$1", productID)
+func (r *PackRepositoryPg) DeleteByProduct(ctx context.Context, productID uuid.UUID) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "packs", "delete_by_product")
+	defer end(&err)
+	_, err = conn(ctx, r.DB).ExecContext(ctx, "DELETE FROM packs WHERE product_id=$1", productID)
 	return err
 }
 
-func (r *PackRepositoryPg) ListByProduct(productID uuid.UUID) ([]*model.Pack, error) {
-	rows, err := r.DB.Query("SELECT id, product_id, size FROM packs WHERE product_id=$1", productID)
+func (r *PackRepositoryPg) ListByProduct(ctx context.Context, productID uuid.UUID) (_ []*model.Pack, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "packs", "list_by_product")
+	defer end(&err)
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, "SELECT "+packColumns+" FROM packs WHERE product_id=$1", productID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var packs []*model.Pack
 	for rows.Next() {
-		p := &model.Pack{}
-		if err := rows.Scan(&p.ID, &p.ProductID, &p.Size); err != nil {
+		p, err := scanPack(rows)
+		if err != nil {
 			return nil, err
 		}
 		packs = append(packs, p)
 	}
 	return packs, nil
 }
+
+func (r *PackRepositoryPg) GetPackSet(ctx context.Context, productID uuid.UUID) (_ *model.PackSet, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "packs", "get_pack_set")
+	defer end(&err)
+	// A single statement reads the version and the packs from the same snapshot.
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, `SELECT p.packs_version, k.id, k.size, k.length_mm, k.width_mm, k.height_mm, k.weight_g
+		FROM products p LEFT JOIN packs k ON k.product_id = p.id WHERE p.id=$1`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var set *model.PackSet
+	for rows.Next() {
+		var version int64
+		var id uuid.NullUUID
+		var size, length, width, height, weight sql.NullInt64
+		if err := rows.Scan(&version, &id, &size, &length, &width, &height, &weight); err != nil {
+			return nil, err
+		}
+		if set == nil {
+			set = &model.PackSet{ProductID: productID, Version: version}
+		}
+		if id.Valid {
+			p := &model.Pack{ID: id.UUID, ProductID: productID, Size: int(size.Int64)}
+			setPackMeasurements(p, length, width, height, weight)
+			set.Packs = append(set.Packs, p)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return nil, port.ErrProductNotFound
+	}
+	return set, nil
+}
+
+func (r *PackRepositoryPg) ReplacePackSet(ctx context.Context, productID uuid.UUID, sizes []int, expectedVersion int64) (old, replaced *model.PackSet, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "packs", "replace_pack_set")
+	defer end(&err)
+	old = &model.PackSet{ProductID: productID}
+	replaced = &model.PackSet{ProductID: productID}
+	err = inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Bumping the version first locks the product row, serializing concurrent replacements.
+		row := tx.QueryRowContext(ctx, `UPDATE products SET packs_version=packs_version+1
+			WHERE id=$1 AND ($2=0 OR packs_version=$2) RETURNING packs_version`, productID, expectedVersion)
+		if err := row.Scan(&replaced.Version); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return missingOrConflict(ctx, tx, productID)
+			}
+			return err
+		}
+		old.Version = replaced.Version - 1
+		rows, err := tx.QueryContext(ctx, "DELETE FROM packs WHERE product_id=$1 RETURNING "+packColumns, productID)
+		if err != nil {
+			return err
+		}
+		measured := map[int]*model.Pack{}
+		for rows.Next() {
+			p, err := scanPack(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			old.Packs = append(old.Packs, p)
+			measured[p.Size] = p
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		for _, size := range sizes {
+			p := &model.Pack{ProductID: productID, Size: size}
+			if m := measured[size]; m != nil {
+				p.Dimensions, p.WeightG = m.Dimensions, m.WeightG
+			}
+			length, width, height, weight := packMeasurements(p)
+			if err := tx.QueryRowContext(ctx, `INSERT INTO packs(product_id, size, length_mm, width_mm, height_mm, weight_g)
+				VALUES($1, $2, $3, $4, $5, $6) RETURNING id`, productID, size, length, width, height, weight).Scan(&p.ID); err != nil {
+				return err
+			}
+			replaced.Packs = append(replaced.Packs, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return old, replaced, nil
+}
+
+// scanPack scans a row of packColumns.
+func scanPack(row interface{ Scan(dest ...any) error }) (*model.Pack, error) {
+	p := &model.Pack{}
+	var length, width, height, weight sql.NullInt64
+	if err := row.Scan(&p.ID, &p.ProductID, &p.Size, &length, &width, &height, &weight); err != nil {
+		return nil, err
+	}
+	setPackMeasurements(p, length, width, height, weight)
+	return p, nil
+}
+
+// packMeasurements returns the measurement columns of pack, NULL where unknown.
+func packMeasurements(pack *model.Pack) (length, width, height, weight sql.NullInt64) {
+	if d := pack.Dimensions; d != nil {
+		length = sql.NullInt64{Int64: int64(d.LengthMM), Valid: true}
+		width = sql.NullInt64{Int64: int64(d.WidthMM), Valid: true}
+		height = sql.NullInt64{Int64: int64(d.HeightMM), Valid: true}
+	}
+	weight = sql.NullInt64{Int64: int64(pack.WeightG), Valid: pack.WeightG > 0}
+	return length, width, height, weight
+}
+
+func setPackMeasurements(p *model.Pack, length, width, height, weight sql.NullInt64) {
+	if length.Valid && width.Valid && height.Valid {
+		p.Dimensions = &model.Dimensions{LengthMM: int(length.Int64), WidthMM: int(width.Int64), HeightMM: int(height.Int64)}
+	}
+	p.WeightG = int(weight.Int64)
+}
@@ -1,14 +1,16 @@
 package out
 
 import (
-	"errors"
+	"context"
 	"sync"
 
 	"github.com/google/uuid"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
 )
 
-var ErrProductNotFound = errors.New("product not found")
+// ErrProductNotFound is port.ErrProductNotFound, kept for callers that refer to it here.
+var ErrProductNotFound = port.ErrProductNotFound
 
 // ProductRepositoryMem is an in-memory implementation of ProductRepository.
 type ProductRepositoryMem struct {
@@ -23,15 +25,19 @@ func NewProductRepositoryMem() *ProductRepositoryMem {
 	}
 }
 
-func (r *ProductRepositoryMem) Create(product *model.Product) error {
+func (r *ProductRepositoryMem) Create(_ context.Context, product *model.Product) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if r.skuTaken(product.SKU, uuid.Nil) {
+		return port.ErrDuplicateSKU
+	}
 	product.ID = uuid.New()
+	product.Version = 1
 	r.products[product.ID] = product
 	return nil
 }
 
-func (r *ProductRepositoryMem) GetByID(id uuid.UUID) (*model.Product, error) {
+func (r *ProductRepositoryMem) GetByID(_ context.Context, id uuid.UUID) (*model.Product, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	p, ok := r.products[id]
@@ -41,17 +47,25 @@ func (r *ProductRepositoryMem) GetByID(id uuid.UUID) (*model.Product, error) {
 	return p, nil
 }
 
-func (r *ProductRepositoryMem) Update(product *model.Product) error {
+func (r *ProductRepositoryMem) Update(_ context.Context, product *model.Product) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if _, ok := r.products[product.ID]; !ok {
+	stored, ok := r.products[product.ID]
+	if !ok {
 		return ErrProductNotFound
 	}
+	if product.Version != 0 && product.Version != stored.Version {
+		return port.ErrVersionConflict
+	}
+	if r.skuTaken(product.SKU, product.ID) {
+		return port.ErrDuplicateSKU
+	}
+	product.Version = stored.Version + 1
 	r.products[product.ID] = product
 	return nil
 }
 
-func (r *ProductRepositoryMem) Delete(id uuid.UUID) error {
+func (r *ProductRepositoryMem) Delete(_ context.Context, id uuid.UUID) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if _, ok := r.products[id]; !ok {
@@ -61,7 +75,7 @@ func (r *ProductRepositoryMem) Delete(id uuid.UUID) error {
 	return nil
 }
 
-func (r *ProductRepositoryMem) List() ([]*model.Product, error) {
+func (r *ProductRepositoryMem) List(_ context.Context) ([]*model.Product, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	products := make([]*model.Product, 0, len(r.products))
@@ -70,3 +84,16 @@ func (r *ProductRepositoryMem) List() ([]*model.Product, error) {
 	}
 	return products, nil
 }
+
+// skuTaken reports whether sku is used by a product other than id. Callers must hold r.mu.
+func (r *ProductRepositoryMem) skuTaken(sku string, id uuid.UUID) bool {
+	if sku == "" {
+		return false
+	}
+	for _, p := range r.products {
+		if p.SKU == sku && p.ID != id {
+			return true
+		}
+	}
+	return false
+}
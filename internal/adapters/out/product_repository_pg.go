@@ -1,41 +1,67 @@
 package out
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
 )
 
 type ProductRepositoryPg struct {
-	DB *sql.DB
+	DB      *sql.DB
+	Metrics port.QueryMetrics
 }
 
-func (r *ProductRepositoryPg) Create(product *model.Product) error {
-	return r.DB.QueryRow("INSERT INTO products(name) VALUES($1) RETURNING id", product.Name).Scan(&product.ID)
+func (r *ProductRepositoryPg) Create(ctx context.Context, product *model.Product) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "products", "create")
+	defer end(&err)
+	err = conn(ctx, r.DB).QueryRowContext(ctx, "INSERT INTO products(name, sku) VALUES($1, NULLIF($2, '')) RETURNING id, version", product.Name, product.SKU).Scan(&product.ID, &product.Version)
+	return translateProductError(err)
 }
 
-func (r *ProductRepositoryPg) GetByID(id uuid.UUID) (*model.Product, error) {
+func (r *ProductRepositoryPg) GetByID(ctx context.Context, id uuid.UUID) (_ *model.Product, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "products", "get_by_id")
+	defer end(&err)
 	p := &model.Product{}
-	row := r.DB.QueryRow("SELECT id, name FROM products WHERE id=$1", id)
-	if err := row.Scan(&p.ID, &p.Name); err != nil {
+	row := conn(ctx, r.DB).QueryRowContext(ctx, "SELECT id, name, COALESCE(sku, ''), version FROM products WHERE id=$1", id)
+	if err := row.Scan(&p.ID, &p.Name, &p.SKU, &p.Version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, port.ErrProductNotFound
+		}
 		return nil, err
 	}
 	return p, nil
 }
 
-func (r *ProductRepositoryPg) Update(product *model.Product) error {
-	_, err := r.DB.Exec("UPDATE products SET name=$1 WHERE id=$2", product.Name, product.ID)
-	return err
+func (r *ProductRepositoryPg) Update(ctx context.Context, product *model.Product) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "products", "update")
+	defer end(&err)
+	row := conn(ctx, r.DB).QueryRowContext(ctx, `UPDATE products SET name=$1, sku=NULLIF($2, ''), version=version+1
+		WHERE id=$3 AND ($4=0 OR version=$4) RETURNING version`, product.Name, product.SKU, product.ID, product.Version)
+	if err := row.Scan(&product.Version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return missingOrConflict(ctx, conn(ctx, r.DB), product.ID)
+		}
+		return translateProductError(err)
+	}
+	return nil
 }
 
-func (r *ProductRepositoryPg) Delete(id uuid.UUID) error {
-	_, err := r.DB.Exec("DELETE FROM products WHERE id=$1", id)
+func (r *ProductRepositoryPg) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "products", "delete")
+	defer end(&err)
+	_, err = conn(ctx, r.DB).ExecContext(ctx, "DELETE FROM products WHERE id=$1", id)
 	return err
 }
 
-func (r *ProductRepositoryPg) List() ([]*model.Product, error) {
-	rows, err := r.DB.Query("SELECT id, name FROM products")
+func (r *ProductRepositoryPg) List(ctx context.Context) (_ []*model.Product, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "products", "list")
+	defer end(&err)
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, "SELECT id, name, COALESCE(sku, ''), version FROM products")
 	if err != nil {
 		return nil, err
 	}
@@ -43,10 +69,32 @@ func (r *ProductRepositoryPg) List() ([]*model.Product, error) {
 	var products []*model.Product
 	for rows.Next() {
 		p := &model.Product{}
-		if err := rows.Scan(&p.ID, &p.Name); err != nil {
+		if err := rows.Scan(&p.ID, &p.Name, &p.SKU, &p.Version); err != nil {
 			return nil, err
 		}
 		products = append(products, p)
 	}
 	return products, nil
 }
+
+// translateProductError maps unique violations on the SKU index to port.ErrDuplicateSKU.
+func translateProductError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "products_sku_key" {
+		return port.ErrDuplicateSKU
+	}
+	return err
+}
+
+// missingOrConflict explains why a versioned update of product id matched no row: either the product does
+// not exist, or its version moved on.
+func missingOrConflict(ctx context.Context, q querier, id uuid.UUID) error {
+	var exists bool
+	if err := q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM products WHERE id=$1)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return port.ErrProductNotFound
+	}
+	return port.ErrVersionConflict
+}
@@ -0,0 +1,36 @@
+package out
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+var tracer = otel.Tracer("github.com/rlpaul93/order-fulfillment/internal/adapters/out")
+
+// startQuery starts a span for a repository query. The returned function ends the span,
+// recording *errp on it, and reports the query latency if metrics are configured.
+func startQuery(ctx context.Context, m port.QueryMetrics, repository, operation string) (context.Context, func(errp *error)) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, repository+"."+operation)
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.collection.name", repository),
+		attribute.String("db.operation.name", operation),
+	)
+	return ctx, func(errp *error) {
+		if errp != nil && *errp != nil {
+			span.RecordError(*errp)
+			span.SetStatus(codes.Error, (*errp).Error())
+		}
+		span.End()
+		if m != nil {
+			m.ObserveQuery(repository, operation, time.Since(start))
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package out
+
+import (
+	"context"
+	"database/sql"
+)
+
+// txKey is the context key of the *sql.Tx started by TransactorPg.
+type txKey struct{}
+
+// querier is satisfied by *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn returns the transaction carried by ctx, so that Pg repositories take part in it, or db outside of one.
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// inTx runs fn in the transaction carried by ctx or, outside of one, in a new transaction that is committed
+// if fn succeeds.
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(tx)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// TransactorPg runs functions in a PostgreSQL transaction shared by the Pg repositories they call.
+type TransactorPg struct {
+	DB *sql.DB
+}
+
+func (t *TransactorPg) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return inTx(ctx, t.DB, func(tx *sql.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// TransactorMem runs functions directly, as the in-memory repositories have no transactions.
+type TransactorMem struct{}
+
+func (TransactorMem) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -0,0 +1,148 @@
+package out
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+// WebhookRepositoryMem is an in-memory implementation of WebhookRepository.
+type WebhookRepositoryMem struct {
+	mu         sync.RWMutex
+	subs       map[uuid.UUID]*model.WebhookSubscription
+	deliveries map[uuid.UUID]*model.WebhookDelivery
+}
+
+// NewWebhookRepositoryMem creates a new in-memory webhook repository.
+func NewWebhookRepositoryMem() *WebhookRepositoryMem {
+	return &WebhookRepositoryMem{
+		subs:       make(map[uuid.UUID]*model.WebhookSubscription),
+		deliveries: make(map[uuid.UUID]*model.WebhookDelivery),
+	}
+}
+
+func (r *WebhookRepositoryMem) CreateSubscription(_ context.Context, sub *model.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sub.ID = uuid.New()
+	stored := *sub
+	r.subs[sub.ID] = &stored
+	return nil
+}
+
+func (r *WebhookRepositoryMem) GetSubscription(_ context.Context, id uuid.UUID) (*model.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sub, ok := r.subs[id]
+	if !ok {
+		return nil, port.ErrWebhookNotFound
+	}
+	copied := *sub
+	return &copied, nil
+}
+
+func (r *WebhookRepositoryMem) DeleteSubscription(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.subs[id]; !ok {
+		return port.ErrWebhookNotFound
+	}
+	delete(r.subs, id)
+	for deliveryID, d := range r.deliveries {
+		if d.SubscriptionID == id {
+			delete(r.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+func (r *WebhookRepositoryMem) ListSubscriptions(_ context.Context) ([]*model.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	subs := make([]*model.WebhookSubscription, 0, len(r.subs))
+	for _, sub := range r.subs {
+		copied := *sub
+		subs = append(subs, &copied)
+	}
+	slices.SortFunc(subs, func(a, b *model.WebhookSubscription) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return subs, nil
+}
+
+func (r *WebhookRepositoryMem) CreateDeliveries(_ context.Context, deliveries ...*model.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range deliveries {
+		stored := *d
+		r.deliveries[d.ID] = &stored
+	}
+	return nil
+}
+
+func (r *WebhookRepositoryMem) GetDelivery(_ context.Context, id uuid.UUID) (*model.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.deliveries[id]
+	if !ok {
+		return nil, port.ErrWebhookNotFound
+	}
+	copied := *d
+	return &copied, nil
+}
+
+func (r *WebhookRepositoryMem) ClaimDueDeliveries(_ context.Context, now time.Time, limit int, until time.Time) ([]*model.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var due []*model.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.Status == model.DeliveryPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	slices.SortFunc(due, func(a, b *model.WebhookDelivery) int {
+		return cmp.Or(a.NextAttemptAt.Compare(b.NextAttemptAt), a.CreatedAt.Compare(b.CreatedAt))
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	claimed := make([]*model.WebhookDelivery, len(due))
+	for i, d := range due {
+		d.NextAttemptAt = until
+		copied := *d
+		claimed[i] = &copied
+	}
+	return claimed, nil
+}
+
+func (r *WebhookRepositoryMem) UpdateDelivery(_ context.Context, delivery *model.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.deliveries[delivery.ID]; !ok {
+		return port.ErrWebhookNotFound
+	}
+	stored := *delivery
+	r.deliveries[delivery.ID] = &stored
+	return nil
+}
+
+func (r *WebhookRepositoryMem) ListDeliveries(_ context.Context, subscriptionID uuid.UUID, status string, limit int) ([]*model.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	deliveries := []*model.WebhookDelivery{}
+	for _, d := range r.deliveries {
+		if d.SubscriptionID == subscriptionID && (status == "" || d.Status == status) {
+			copied := *d
+			deliveries = append(deliveries, &copied)
+		}
+	}
+	slices.SortFunc(deliveries, func(a, b *model.WebhookDelivery) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
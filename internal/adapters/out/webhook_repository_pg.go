@@ -0,0 +1,172 @@
+package out
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+type WebhookRepositoryPg struct {
+	DB      *sql.DB
+	Metrics port.QueryMetrics
+}
+
+const deliveryColumns = `id, subscription_id, event_id, event_type, body, status, attempts, COALESCE(last_error, ''),
+	COALESCE(response_status, 0), next_attempt_at, created_at, updated_at`
+
+func (r *WebhookRepositoryPg) CreateSubscription(ctx context.Context, sub *model.WebhookSubscription) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "webhook_subscriptions", "create")
+	defer end(&err)
+	return conn(ctx, r.DB).QueryRowContext(ctx, "INSERT INTO webhook_subscriptions(url, event_types, secret, created_at) VALUES($1, $2, $3, $4) RETURNING id",
+		sub.URL, pq.Array(sub.EventTypes), sub.Secret, sub.CreatedAt).Scan(&sub.ID)
+}
+
+func (r *WebhookRepositoryPg) GetSubscription(ctx context.Context, id uuid.UUID) (_ *model.WebhookSubscription, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "webhook_subscriptions", "get_by_id")
+	defer end(&err)
+	sub := &model.WebhookSubscription{}
+	row := conn(ctx, r.DB).QueryRowContext(ctx, "SELECT id, url, event_types, secret, created_at FROM webhook_subscriptions WHERE id=$1", id)
+	if err := row.Scan(&sub.ID, &sub.URL, pq.Array(&sub.EventTypes), &sub.Secret, &sub.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, port.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (r *WebhookRepositoryPg) DeleteSubscription(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "webhook_subscriptions", "delete")
+	defer end(&err)
+	res, err := conn(ctx, r.DB).ExecContext(ctx, "DELETE FROM webhook_subscriptions WHERE id=$1", id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return port.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepositoryPg) ListSubscriptions(ctx context.Context) (_ []*model.WebhookSubscription, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "webhook_subscriptions", "list")
+	defer end(&err)
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, "SELECT id, url, event_types, secret, created_at FROM webhook_subscriptions ORDER BY created_at")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	subs := []*model.WebhookSubscription{}
+	for rows.Next() {
+		sub := &model.WebhookSubscription{}
+		if err := rows.Scan(&sub.ID, &sub.URL, pq.Array(&sub.EventTypes), &sub.Secret, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+func (r *WebhookRepositoryPg) CreateDeliveries(ctx context.Context, deliveries ...*model.WebhookDelivery) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "webhook_deliveries", "create")
+	defer end(&err)
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		for _, d := range deliveries {
+			_, err := tx.ExecContext(ctx, `INSERT INTO webhook_deliveries(id, subscription_id, event_id, event_type, body, status, next_attempt_at, created_at, updated_at)
+				VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+				d.ID, d.SubscriptionID, d.EventID, d.EventType, []byte(d.Body), d.Status, d.NextAttemptAt, d.CreatedAt, d.UpdatedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *WebhookRepositoryPg) GetDelivery(ctx context.Context, id uuid.UUID) (_ *model.WebhookDelivery, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "webhook_deliveries", "get_by_id")
+	defer end(&err)
+	d, err := scanDelivery(conn(ctx, r.DB).QueryRowContext(ctx, "SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE id=$1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, port.ErrWebhookNotFound
+	}
+	return d, err
+}
+
+func (r *WebhookRepositoryPg) ClaimDueDeliveries(ctx context.Context, now time.Time, limit int, until time.Time) (_ []*model.WebhookDelivery, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "webhook_deliveries", "claim_due")
+	defer end(&err)
+	// Postponing the next attempt claims the deliveries; SKIP LOCKED lets several dispatchers claim disjoint batches.
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, `WITH claimed AS (
+			UPDATE webhook_deliveries SET next_attempt_at=$3 WHERE id IN (
+				SELECT id FROM webhook_deliveries WHERE status='pending' AND next_attempt_at <= $1
+				ORDER BY next_attempt_at, created_at LIMIT $2 FOR UPDATE SKIP LOCKED)
+			RETURNING *)
+		SELECT `+deliveryColumns+` FROM claimed ORDER BY created_at`, now, limit, until)
+	if err != nil {
+		return nil, err
+	}
+	return scanDeliveries(rows)
+}
+
+func (r *WebhookRepositoryPg) UpdateDelivery(ctx context.Context, d *model.WebhookDelivery) (err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "webhook_deliveries", "update")
+	defer end(&err)
+	res, err := conn(ctx, r.DB).ExecContext(ctx, `UPDATE webhook_deliveries SET status=$1, attempts=$2, last_error=NULLIF($3, ''),
+		response_status=NULLIF($4, 0), next_attempt_at=$5, updated_at=$6 WHERE id=$7`,
+		d.Status, d.Attempts, d.LastError, d.ResponseStatus, d.NextAttemptAt, d.UpdatedAt, d.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return port.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepositoryPg) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, status string, limit int) (_ []*model.WebhookDelivery, err error) {
+	ctx, end := startQuery(ctx, r.Metrics, "webhook_deliveries", "list")
+	defer end(&err)
+	rows, err := conn(ctx, r.DB).QueryContext(ctx, "SELECT "+deliveryColumns+` FROM webhook_deliveries
+		WHERE subscription_id=$1 AND ($2='' OR status=$2) ORDER BY created_at DESC LIMIT NULLIF($3, 0)`,
+		subscriptionID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanDeliveries(rows)
+}
+
+// scanDelivery reads a delivery selected with deliveryColumns.
+func scanDelivery(row interface{ Scan(...any) error }) (*model.WebhookDelivery, error) {
+	d := &model.WebhookDelivery{}
+	err := row.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, (*[]byte)(&d.Body), &d.Status, &d.Attempts,
+		&d.LastError, &d.ResponseStatus, &d.NextAttemptAt, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// scanDeliveries reads and closes rows of deliveries selected with deliveryColumns.
+func scanDeliveries(rows *sql.Rows) ([]*model.WebhookDelivery, error) {
+	defer rows.Close()
+	deliveries := []*model.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
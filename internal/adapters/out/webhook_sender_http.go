@@ -0,0 +1,77 @@
+package out
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+)
+
+// WebhookSenderHTTP posts webhooks with Client, or http.DefaultClient when it is nil. The client's
+// timeout bounds each attempt.
+type WebhookSenderHTTP struct {
+	Client *http.Client
+}
+
+// NewWebhookClient returns a client for WebhookSenderHTTP whose attempts are bounded by timeout (0 means
+// unlimited). It does not follow redirects, which are failed deliveries, nor use a proxy. Unless
+// allowPrivate is set, it refuses to connect to addresses model.PublicWebhookTarget rejects; the check is
+// made on the address dialed, so a host re-resolving to an internal address after its subscription was
+// accepted is refused too.
+func NewWebhookClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = dialPublicOnly
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// dialPublicOnly is a net.Dialer Control function refusing connections to non-public webhook targets.
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("webhook target %s: %w", address, err)
+	}
+	if !model.PublicWebhookTarget(addrPort.Addr()) {
+		return fmt.Errorf("webhook target %s is not a public address", address)
+	}
+	return nil
+}
+
+func (s WebhookSenderHTTP) Send(ctx context.Context, url string, header map[string]string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a little of the body so the connection can be reused; receivers only need to answer 2xx.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Dimensions are the length, width and height of a box in millimetres.
+type Dimensions struct {
+	LengthMM int `json:"length_mm" example:"300"`
+	WidthMM  int `json:"width_mm" example:"200"`
+	HeightMM int `json:"height_mm" example:"150"`
+}
+
+// Volume returns the volume in cubic millimetres.
+func (d Dimensions) Volume() int64 {
+	return int64(d.LengthMM) * int64(d.WidthMM) * int64(d.HeightMM)
+}
+
+// FitsIn reports whether a box of dimensions d fits inside inner in some orientation.
+func (d Dimensions) FitsIn(inner Dimensions) bool {
+	a := []int{d.LengthMM, d.WidthMM, d.HeightMM}
+	b := []int{inner.LengthMM, inner.WidthMM, inner.HeightMM}
+	slices.Sort(a)
+	slices.Sort(b)
+	return a[0] <= b[0] && a[1] <= b[1] && a[2] <= b[2]
+}
+
+// Carton is a shipping box that packs are put in.
+type Carton struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name" example:"Medium box"`
+	Inner      Dimensions `json:"inner_dimensions"`
+	MaxWeightG int        `json:"max_weight_g" example:"20000"` // heaviest load the carton takes, in grams
+}
+
+// Holds reports whether the carton takes a pack with the given dimensions and weight on its own.
+func (c *Carton) Holds(dims Dimensions, weightG int) bool {
+	return dims.FitsIn(c.Inner) && weightG <= c.MaxWeightG
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Customer is a buyer whose orders may be restricted to some of a product's pack sizes.
+type Customer struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	PackRules []PackRule `json:"pack_rules"`
+}
+
+// PackRule restricts the pack sizes a customer accepts. A rule without a product ID applies to every
+// product that has no rule of its own. A pack size is usable if it is listed in AllowedSizes (when not
+// empty), is not listed in ForbiddenSizes and does not exceed MaxPackSize (when positive).
+type PackRule struct {
+	ProductID      uuid.UUID `json:"product_id,omitzero"`
+	AllowedSizes   []int     `json:"allowed_sizes,omitempty"`
+	ForbiddenSizes []int     `json:"forbidden_sizes,omitempty"`
+	MaxPackSize    int       `json:"max_pack_size,omitempty"`
+}
+
+// RuleFor returns the rule governing productID, or nil if the customer accepts every pack size.
+func (c *Customer) RuleFor(productID uuid.UUID) *PackRule {
+	var fallback *PackRule
+	for i := range c.PackRules {
+		switch c.PackRules[i].ProductID {
+		case productID:
+			return &c.PackRules[i]
+		case uuid.Nil:
+			fallback = &c.PackRules[i]
+		}
+	}
+	return fallback
+}
+
+// PackSizesFor returns the subset of sizes the customer accepts for productID.
+func (c *Customer) PackSizesFor(productID uuid.UUID, sizes []int) []int {
+	rule := c.RuleFor(productID)
+	if rule == nil {
+		return sizes
+	}
+	return rule.Filter(sizes)
+}
+
+// Filter returns the sizes permitted by the rule, in their original order.
+func (r *PackRule) Filter(sizes []int) []int {
+	filtered := []int{}
+	for _, size := range sizes {
+		if len(r.AllowedSizes) > 0 && !slices.Contains(r.AllowedSizes, size) {
+			continue
+		}
+		if slices.Contains(r.ForbiddenSizes, size) {
+			continue
+		}
+		if r.MaxPackSize > 0 && size > r.MaxPackSize {
+			continue
+		}
+		filtered = append(filtered, size)
+	}
+	return filtered
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Domain event types.
+const (
+	EventProductCreated      = "ProductCreated"
+	EventProductUpdated      = "ProductUpdated"
+	EventProductDeleted      = "ProductDeleted"
+	EventPacksReplaced       = "PacksReplaced"
+	EventFulfillmentComputed = "FulfillmentComputed"
+)
+
+// EventTypes lists every domain event type.
+var EventTypes = []string{
+	EventProductCreated,
+	EventProductUpdated,
+	EventProductDeleted,
+	EventPacksReplaced,
+	EventFulfillmentComputed,
+}
+
+// Event is a domain event: something that happened to a product, described by a JSON payload whose shape
+// depends on the event type.
+type Event struct {
+	ID         uuid.UUID       `json:"id"`
+	Type       string          `json:"type"`
+	ProductID  uuid.UUID       `json:"product_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// OutboxEvent is an event waiting in the outbox, with the names of the publishers that have already taken it.
+type OutboxEvent struct {
+	*Event
+	PublishedTo []string
+}
+
+// NewEvent returns an event of type eventType about productID, with payload encoded as JSON.
+func NewEvent(eventType string, productID uuid.UUID, payload any, occurredAt time.Time) (*Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{ID: uuid.New(), Type: eventType, ProductID: productID, OccurredAt: occurredAt, Payload: data}, nil
+}
+
+// ProductDeletedPayload is the payload of EventProductDeleted. ProductCreated and ProductUpdated carry the
+// Product itself.
+type ProductDeletedPayload struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// PacksReplacedPayload is the payload of EventPacksReplaced.
+type PacksReplacedPayload struct {
+	Version  int64 `json:"version"`
+	OldSizes []int `json:"old_sizes"`
+	NewSizes []int `json:"new_sizes"`
+}
+
+// FulfillmentComputedPayload is the payload of EventFulfillmentComputed.
+type FulfillmentComputedPayload struct {
+	Quantity   int         `json:"quantity"`
+	TotalItems int         `json:"total_items"`
+	Shortfall  int         `json:"shortfall,omitempty"`
+	Packs      map[int]int `json:"packs"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// IdempotencyRecord is the outcome of a request made with an idempotency key, kept so that retries of the
+// request get the original response instead of repeating its effects. StatusCode is 0 while the original
+// request is still being processed.
+type IdempotencyRecord struct {
+	Key         string
+	Fingerprint string // Identifies the request the key was first used with
+	StatusCode  int
+	Header      map[string][]string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// Completed reports whether the record holds the response of its request.
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
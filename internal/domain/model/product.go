@@ -2,15 +2,43 @@ package model
 
 import "github.com/google/uuid"
 
-// Product represents a product with customizable packs.
+// Product represents a product with customizable packs. Version starts at 1 and is incremented by every
+// update; it does not change when the product's packs are replaced, which PackSet versions separately.
 type Product struct {
-	ID   uuid.UUID `json:"id"`
-	Name string    `json:"name"`
+	ID      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	SKU     string    `json:"sku,omitempty"`
+	Version int64     `json:"version"`
 }
 
-// Pack represents a pack size for a product.
+// Pack represents a pack size for a product. Dimensions and WeightG, the outer size and the weight of a full
+// pack, are needed to plan shipments; they are kept when the product's packs are replaced by packs of the
+// same sizes.
 type Pack struct {
+	ID         uuid.UUID   `json:"id"`
+	ProductID  uuid.UUID   `json:"product_id"`
+	Size       int         `json:"size"`
+	Dimensions *Dimensions `json:"dimensions,omitempty"`
+	WeightG    int         `json:"weight_g,omitempty"`
+}
+
+// Measured reports whether the pack's dimensions and weight are known.
+func (p *Pack) Measured() bool {
+	return p.Dimensions != nil && p.WeightG > 0
+}
+
+// PackSet is a product's packs together with the version of the set, which starts at 1 and is incremented
+// every time the packs are replaced.
+type PackSet struct {
+	ProductID uuid.UUID
+	Version   int64
+	Packs     []*Pack
+}
+
+// CatalogProduct is a product together with its pack sizes, as stored in catalog snapshots.
+type CatalogProduct struct {
 	ID        uuid.UUID `json:"id"`
-	ProductID uuid.UUID `json:"product_id"`
-	Size      int       `json:"size"`
+	SKU       string    `json:"sku,omitempty"`
+	Name      string    `json:"name"`
+	PackSizes []int     `json:"pack_sizes"`
 }
@@ -0,0 +1,18 @@
+package port
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FulfillmentMetrics records observations about fulfillment computations.
+type FulfillmentMetrics interface {
+	ObserveSolve(duration time.Duration, searchSpace int)
+	ObserveResult(productID uuid.UUID, overage, packCount int)
+}
+
+// QueryMetrics records the latency of database queries.
+type QueryMetrics interface {
+	ObserveQuery(repository, operation string, duration time.Duration)
+}
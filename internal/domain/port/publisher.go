@@ -0,0 +1,21 @@
+package port
+
+import (
+	"context"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+)
+
+// EventPublisher delivers domain events to a downstream system. Delivery is at least once: an event that a
+// publisher failed to take is published to it again, so consumers should deduplicate by event ID.
+type EventPublisher interface {
+	Publish(ctx context.Context, event *model.Event) error
+}
+
+// SelectivePublisher is an EventPublisher that can tell whether anyone downstream wants events of a type.
+// Events that are not tied to a change, such as FulfillmentComputed, are only recorded if some publisher
+// wants them; a publisher that is not selective wants every event.
+type SelectivePublisher interface {
+	EventPublisher
+	Wants(ctx context.Context, eventType string) bool
+}
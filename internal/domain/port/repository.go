@@ -1,25 +1,128 @@
 package port
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
 )
 
 // ProductRepository defines CRUD operations for products.
 type ProductRepository interface {
-	Create(product *model.Product) error
-	GetByID(id uuid.UUID) (*model.Product, error)
-	Update(product *model.Product) error
-	Delete(id uuid.UUID) error
-	List() ([]*model.Product, error)
+	Create(ctx context.Context, product *model.Product) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Product, error)
+	// Update stores product and sets its Version to the new version. If product.Version is not zero, the
+	// update only succeeds if it is still the stored version, and fails with ErrVersionConflict otherwise.
+	Update(ctx context.Context, product *model.Product) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context) ([]*model.Product, error)
 }
 
 // PackRepository defines CRUD operations for packs.
 type PackRepository interface {
-	Create(pack *model.Pack) error
-	GetByID(id uuid.UUID) (*model.Pack, error)
-	Update(pack *model.Pack) error
-	Delete(id uuid.UUID) error
-	DeleteByProduct(productID uuid.UUID) error
-	ListByProduct(productID uuid.UUID) ([]*model.Pack, error)
+	Create(ctx context.Context, pack *model.Pack) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Pack, error)
+	// Update stores pack and increments the version of its product's pack set.
+	Update(ctx context.Context, pack *model.Pack) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByProduct(ctx context.Context, productID uuid.UUID) error
+	ListByProduct(ctx context.Context, productID uuid.UUID) ([]*model.Pack, error)
+	// GetPackSet returns a product's packs and the version of the set, read together.
+	GetPackSet(ctx context.Context, productID uuid.UUID) (*model.PackSet, error)
+	// ReplacePackSet atomically replaces a product's packs with packs of the given sizes, returning the
+	// previous set and the new one. A new pack takes the dimensions and weight of the old pack of its size. If expectedVersion is not zero, the replacement only succeeds if it is
+	// still the set's version, and fails with ErrVersionConflict otherwise.
+	ReplacePackSet(ctx context.Context, productID uuid.UUID, sizes []int, expectedVersion int64) (old, replaced *model.PackSet, err error)
+}
+
+// CustomerRepository defines CRUD operations for customers and their pack rules.
+type CustomerRepository interface {
+	Create(ctx context.Context, customer *model.Customer) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Customer, error)
+	Update(ctx context.Context, customer *model.Customer) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context) ([]*model.Customer, error)
 }
+
+// CartonRepository defines CRUD operations for the catalog of shipping cartons.
+type CartonRepository interface {
+	Create(ctx context.Context, carton *model.Carton) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Carton, error)
+	Update(ctx context.Context, carton *model.Carton) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context) ([]*model.Carton, error)
+}
+
+// IdempotencyRepository stores the responses of requests made with idempotency keys.
+type IdempotencyRepository interface {
+	// Reserve stores record, which has no response yet, unless an unexpired record with the same key
+	// exists; that record is returned instead. Expired records are replaced.
+	Reserve(ctx context.Context, record *model.IdempotencyRecord) (existing *model.IdempotencyRecord, err error)
+	// Complete stores the response of a reserved record.
+	Complete(ctx context.Context, record *model.IdempotencyRecord) error
+	// Release deletes the record with key, so that its request can be made again.
+	Release(ctx context.Context, key string) error
+	// DeleteExpired deletes the records that expired by now and returns how many it deleted.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// OutboxRepository stores domain events until they are published.
+type OutboxRepository interface {
+	// Append adds events to the outbox, within the transaction carried by ctx if there is one.
+	Append(ctx context.Context, events ...*model.Event) error
+	// Claim returns up to limit unpublished events, oldest first, that are not claimed by someone else, and
+	// claims them until the given time.
+	Claim(ctx context.Context, limit int, until time.Time) ([]*model.OutboxEvent, error)
+	// MarkPublished removes an event that every publisher took from the outbox.
+	MarkPublished(ctx context.Context, id uuid.UUID) error
+	// RecordFailure notes a failed attempt to publish an event, which stays pending, and the publishers that
+	// have taken it so far.
+	RecordFailure(ctx context.Context, id uuid.UUID, publishedTo []string, reason string) error
+}
+
+// WebhookRepository stores webhook subscriptions and the log of their deliveries.
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *model.WebhookSubscription) error
+	GetSubscription(ctx context.Context, id uuid.UUID) (*model.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+	ListSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error)
+
+	CreateDeliveries(ctx context.Context, deliveries ...*model.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error)
+	// ClaimDueDeliveries returns up to limit pending deliveries due by now, soonest first, and postpones
+	// their next attempt to until so that they are not claimed again while being sent.
+	ClaimDueDeliveries(ctx context.Context, now time.Time, limit int, until time.Time) ([]*model.WebhookDelivery, error)
+	// UpdateDelivery stores the status, attempts, error, response status and next attempt of a delivery.
+	UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+	// ListDeliveries returns a subscription's deliveries, newest first, optionally only those with status.
+	ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, status string, limit int) ([]*model.WebhookDelivery, error)
+}
+
+// Transactor runs functions in a transaction that the repositories called with the context it passes take
+// part in, so that their changes are committed together or not at all.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// ErrProductNotFound is returned by ProductRepository and PackRepository when no product has the requested ID.
+var ErrProductNotFound = errors.New("product not found")
+
+// ErrPackNotFound is returned by PackRepository when no pack has the requested ID.
+var ErrPackNotFound = errors.New("pack not found")
+
+// ErrVersionConflict is returned when an update expects a version that is no longer the stored one.
+var ErrVersionConflict = errors.New("version conflict")
+
+// ErrWebhookNotFound is returned by WebhookRepository when no subscription or delivery has the requested ID.
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+// ErrCustomerNotFound is returned by CustomerRepository when no customer has the requested ID.
+var ErrCustomerNotFound = errors.New("customer not found")
+
+// ErrCartonNotFound is returned by CartonRepository when no carton has the requested ID.
+var ErrCartonNotFound = errors.New("carton not found")
+
+// ErrDuplicateSKU is returned by ProductRepository when a SKU is already used by another product.
+var ErrDuplicateSKU = errors.New("duplicate SKU")
@@ -0,0 +1,10 @@
+package port
+
+import "context"
+
+// WebhookSender posts webhook bodies to subscriber URLs.
+type WebhookSender interface {
+	// Send posts body to url with the given headers and returns the response status. An error means no
+	// response was received.
+	Send(ctx context.Context, url string, header map[string]string, body []byte) (status int, err error)
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+)
+
+// cartonLoad is a carton and the packs put in it.
+type cartonLoad struct {
+	carton  *model.Carton
+	packs   []*model.Pack
+	volume  int64 // of the packs, in cubic millimetres
+	weightG int
+}
+
+// accepts reports whether pack can be added to the load, were it in carton c.
+func (l *cartonLoad) accepts(c *model.Carton, pack *model.Pack) bool {
+	return pack.Dimensions.FitsIn(c.Inner) &&
+		l.volume+pack.Dimensions.Volume() <= c.Inner.Volume() &&
+		l.weightG+pack.WeightG <= c.MaxWeightG
+}
+
+// holdsAll reports whether carton c takes every pack of the load.
+func (l *cartonLoad) holdsAll(c *model.Carton) bool {
+	if l.volume > c.Inner.Volume() || l.weightG > c.MaxWeightG {
+		return false
+	}
+	for _, pack := range l.packs {
+		if !pack.Dimensions.FitsIn(c.Inner) {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *cartonLoad) add(pack *model.Pack) {
+	l.packs = append(l.packs, pack)
+	l.volume += pack.Dimensions.Volume()
+	l.weightG += pack.WeightG
+}
+
+// packCartons assigns measured packs to as few cartons as it can find, and among those to the least carton
+// volume. A carton takes packs as long as each fits its inner dimensions in some orientation and their
+// total volume and weight stay within the carton's; how the packs are arranged inside is left to whoever
+// packs them.
+//
+// Finding the fewest cartons is NP-hard, so this is first-fit decreasing: packs are taken largest first and
+// put in the first carton with room, a new carton being opened when none has. It runs once per carton type,
+// opening cartons of that type where the pack fits, and keeps the best outcome; each carton is then swapped
+// for the smallest type that still takes its packs.
+func packCartons(packs []*model.Pack, cartons []*model.Carton) ([]*cartonLoad, error) {
+	if len(packs) == 0 {
+		return nil, nil
+	}
+	cartons = slices.SortedFunc(slices.Values(cartons), func(a, b *model.Carton) int {
+		return cmp.Or(cmp.Compare(b.Inner.Volume(), a.Inner.Volume()), cmp.Compare(b.MaxWeightG, a.MaxWeightG), cmp.Compare(a.Name, b.Name))
+	})
+	for _, pack := range packs {
+		if !slices.ContainsFunc(cartons, func(c *model.Carton) bool { return c.Holds(*pack.Dimensions, pack.WeightG) }) {
+			return nil, fmt.Errorf("%w: no carton takes a pack of %d (%dx%dx%d mm, %d g)", ErrUnshippable,
+				pack.Size, pack.Dimensions.LengthMM, pack.Dimensions.WidthMM, pack.Dimensions.HeightMM, pack.WeightG)
+		}
+	}
+	packs = slices.SortedStableFunc(slices.Values(packs), func(a, b *model.Pack) int {
+		return cmp.Or(cmp.Compare(b.Dimensions.Volume(), a.Dimensions.Volume()), cmp.Compare(b.WeightG, a.WeightG),
+			cmp.Compare(a.ID.String(), b.ID.String()))
+	})
+
+	var best []*cartonLoad
+	for _, preferred := range cartons {
+		loads := firstFitDecreasing(packs, cartons, preferred)
+		if best == nil || len(loads) < len(best) || (len(loads) == len(best) && cartonVolume(loads) < cartonVolume(best)) {
+			best = loads
+		}
+	}
+	return best, nil
+}
+
+// firstFitDecreasing packs packs, sorted largest first, into cartons, which are sorted largest first. A new
+// carton is of the preferred type if that takes the pack, and of the largest type that does otherwise.
+func firstFitDecreasing(packs []*model.Pack, cartons []*model.Carton, preferred *model.Carton) []*cartonLoad {
+	var loads []*cartonLoad
+	var prev *model.Pack
+	from := 0 // loads before from were too full for prev, and so are for an identical pack
+	for _, pack := range packs {
+		if pack != prev {
+			prev, from = pack, 0
+		}
+		i := slices.IndexFunc(loads[from:], func(l *cartonLoad) bool { return l.accepts(l.carton, pack) })
+		if i >= 0 {
+			i += from
+		} else {
+			carton := preferred
+			if !carton.Holds(*pack.Dimensions, pack.WeightG) {
+				carton = cartons[slices.IndexFunc(cartons, func(c *model.Carton) bool { return c.Holds(*pack.Dimensions, pack.WeightG) })]
+			}
+			loads = append(loads, &cartonLoad{carton: carton})
+			i = len(loads) - 1
+		}
+		loads[i].add(pack)
+		from = i
+	}
+	for _, l := range loads {
+		for _, c := range slices.Backward(cartons) {
+			if l.holdsAll(c) {
+				l.carton = c
+				break
+			}
+		}
+	}
+	return loads
+}
+
+func cartonVolume(loads []*cartonLoad) int64 {
+	var v int64
+	for _, l := range loads {
+		v += l.carton.Inner.Volume()
+	}
+	return v
+}
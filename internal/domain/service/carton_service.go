@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+// ErrInvalidCarton is returned when a carton is malformed.
+var ErrInvalidCarton = errors.New("invalid carton")
+
+// CartonService manages the catalog of shipping cartons.
+type CartonService struct {
+	Repo port.CartonRepository
+}
+
+func (s *CartonService) Create(ctx context.Context, carton *model.Carton) (err error) {
+	ctx, span := tracer.Start(ctx, "CartonService.Create")
+	defer func() { endSpan(span, err) }()
+	if err := validateCarton(carton); err != nil {
+		return err
+	}
+	return s.Repo.Create(ctx, carton)
+}
+
+func (s *CartonService) GetByID(ctx context.Context, id uuid.UUID) (_ *model.Carton, err error) {
+	ctx, span := tracer.Start(ctx, "CartonService.GetByID")
+	span.SetAttributes(attribute.String("carton.id", id.String()))
+	defer func() { endSpan(span, err) }()
+	return s.Repo.GetByID(ctx, id)
+}
+
+func (s *CartonService) Update(ctx context.Context, carton *model.Carton) (err error) {
+	ctx, span := tracer.Start(ctx, "CartonService.Update")
+	defer func() { endSpan(span, err) }()
+	if err := validateCarton(carton); err != nil {
+		return err
+	}
+	return s.Repo.Update(ctx, carton)
+}
+
+func (s *CartonService) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "CartonService.Delete")
+	defer func() { endSpan(span, err) }()
+	return s.Repo.Delete(ctx, id)
+}
+
+func (s *CartonService) List(ctx context.Context) (_ []*model.Carton, err error) {
+	ctx, span := tracer.Start(ctx, "CartonService.List")
+	defer func() { endSpan(span, err) }()
+	return s.Repo.List(ctx)
+}
+
+// validateCarton checks that the carton is named and has positive inner dimensions and maximum weight.
+func validateCarton(carton *model.Carton) error {
+	if strings.TrimSpace(carton.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCarton)
+	}
+	if !validDimensions(carton.Inner) {
+		return fmt.Errorf("%w: inner dimensions must be positive", ErrInvalidCarton)
+	}
+	if carton.MaxWeightG <= 0 {
+		return fmt.Errorf("%w: max weight must be positive", ErrInvalidCarton)
+	}
+	return nil
+}
+
+func validDimensions(d model.Dimensions) bool {
+	return d.LengthMM > 0 && d.WidthMM > 0 && d.HeightMM > 0
+}
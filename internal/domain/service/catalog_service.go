@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+// Catalog import row outcomes.
+const (
+	CatalogActionCreated   = "created"
+	CatalogActionUpdated   = "updated"
+	CatalogActionUnchanged = "unchanged"
+	CatalogActionError     = "error"
+)
+
+// CatalogRow is one product of a catalog import, with its position in the source file.
+type CatalogRow struct {
+	Row     int
+	Product model.CatalogProduct
+}
+
+// CatalogRowResult reports what an import did, or would do in a dry run, with one row.
+type CatalogRowResult struct {
+	Row       int        `json:"row"`
+	ProductID *uuid.UUID `json:"product_id,omitempty"`
+	SKU       string     `json:"sku,omitempty"`
+	Name      string     `json:"name,omitempty"`
+	Action    string     `json:"action"`
+	Errors    []string   `json:"errors,omitempty"`
+}
+
+// CatalogImportReport summarizes a catalog import.
+type CatalogImportReport struct {
+	DryRun    bool               `json:"dry_run"`
+	Created   int                `json:"created"`
+	Updated   int                `json:"updated"`
+	Unchanged int                `json:"unchanged"`
+	Failed    int                `json:"failed"`
+	Rows      []CatalogRowResult `json:"rows"`
+}
+
+// Add records result in the report.
+func (r *CatalogImportReport) Add(result CatalogRowResult) {
+	switch result.Action {
+	case CatalogActionCreated:
+		r.Created++
+	case CatalogActionUpdated:
+		r.Updated++
+	case CatalogActionUnchanged:
+		r.Unchanged++
+	case CatalogActionError:
+		r.Failed++
+	}
+	r.Rows = append(r.Rows, result)
+}
+
+// CatalogService imports and exports the whole catalog: products together with their pack sizes.
+type CatalogService struct {
+	Products *ProductService
+	Packs    *PackService
+}
+
+// Export returns every product with its pack sizes, ordered by name.
+func (s *CatalogService) Export(ctx context.Context) ([]model.CatalogProduct, error) {
+	var catalog []model.CatalogProduct
+	err := s.ExportEach(ctx, func(p model.CatalogProduct) error {
+		catalog = append(catalog, p)
+		return nil
+	})
+	return catalog, err
+}
+
+// ExportEach calls fn for every product with its pack sizes sorted ascending, ordered by name,
+// loading packs one product at a time so large catalogs can be streamed.
+func (s *CatalogService) ExportEach(ctx context.Context, fn func(model.CatalogProduct) error) (err error) {
+	ctx, span := tracer.Start(ctx, "CatalogService.ExportEach")
+	defer func() { endSpan(span, err) }()
+	products, err := s.Products.List(ctx)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(products, func(a, b *model.Product) int { return strings.Compare(a.Name, b.Name) })
+	for _, p := range products {
+		packs, err := s.Packs.ListByProduct(ctx, p.ID)
+		if err != nil {
+			return err
+		}
+		sizes := make([]int, len(packs))
+		for i, pack := range packs {
+			sizes[i] = pack.Size
+		}
+		slices.Sort(sizes)
+		if err := fn(model.CatalogProduct{ID: p.ID, SKU: p.SKU, Name: p.Name, PackSizes: sizes}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Import upserts rows into the catalog. Rows are matched to existing products by SKU, then by ID,
+// then by name; unmatched rows are created. Pack sets are only replaced when they differ, so importing
+// the same file twice is a no-op. Invalid rows are reported and skipped; the remaining rows are applied.
+// With dryRun set nothing is written, and the report describes what would change.
+func (s *CatalogService) Import(ctx context.Context, rows []CatalogRow, dryRun bool) (report CatalogImportReport, err error) {
+	ctx, span := tracer.Start(ctx, "CatalogService.Import")
+	defer func() { endSpan(span, err) }()
+	report.DryRun = dryRun
+	report.Rows = []CatalogRowResult{}
+
+	products, err := s.Products.List(ctx)
+	if err != nil {
+		return report, err
+	}
+	idx := newCatalogIndex(products)
+	seenSKUs := map[string]int{}
+
+	for _, row := range rows {
+		item := row.Product
+		result := CatalogRowResult{Row: row.Row, SKU: item.SKU, Name: item.Name}
+		if errs := validateCatalogProduct(item); len(errs) > 0 {
+			result.Action, result.Errors = CatalogActionError, errs
+			report.Add(result)
+			continue
+		}
+		if item.SKU != "" {
+			if first, ok := seenSKUs[item.SKU]; ok {
+				result.Action = CatalogActionError
+				result.Errors = []string{fmt.Sprintf("sku %q already used by row %d", item.SKU, first)}
+				report.Add(result)
+				continue
+			}
+			seenSKUs[item.SKU] = row.Row
+		}
+
+		id, action, err := s.upsert(ctx, idx, item, dryRun)
+		if id != uuid.Nil {
+			result.ProductID = &id
+		}
+		result.Action = action
+		if err != nil {
+			var rowErr *catalogRowError
+			if !errors.As(err, &rowErr) && !errors.Is(err, port.ErrDuplicateSKU) {
+				return report, fmt.Errorf("row %d: %w", row.Row, err)
+			}
+			result.Action, result.Errors = CatalogActionError, []string{err.Error()}
+		}
+		report.Add(result)
+	}
+	return report, nil
+}
+
+// catalogRowError is a problem with a single row that does not abort the import.
+type catalogRowError struct{ msg string }
+
+func (e *catalogRowError) Error() string { return e.msg }
+
+// upsert creates or updates the product matching item and returns its ID and the action taken.
+func (s *CatalogService) upsert(ctx context.Context, idx *catalogIndex, item model.CatalogProduct, dryRun bool) (uuid.UUID, string, error) {
+	existing := idx.match(item)
+	if existing == nil {
+		product := &model.Product{Name: item.Name, SKU: item.SKU}
+		if !dryRun {
+			if err := s.Products.Create(ctx, product); err != nil {
+				return uuid.Nil, "", err
+			}
+			if _, err := s.Packs.ReplaceByProduct(ctx, product.ID, item.PackSizes); err != nil {
+				return uuid.Nil, "", err
+			}
+		}
+		idx.add(product)
+		return product.ID, CatalogActionCreated, nil
+	}
+
+	if item.SKU != "" && item.SKU != existing.SKU {
+		if other, ok := idx.bySKU[item.SKU]; ok && other.ID != existing.ID {
+			return existing.ID, "", &catalogRowError{fmt.Sprintf("sku %q belongs to product %s", item.SKU, other.ID)}
+		}
+	}
+
+	changed := false
+	sku := existing.SKU
+	if item.SKU != "" {
+		sku = item.SKU
+	}
+	if existing.Name != item.Name || existing.SKU != sku {
+		updated := &model.Product{ID: existing.ID, Name: item.Name, SKU: sku}
+		if !dryRun {
+			if err := s.Products.Update(ctx, updated); err != nil {
+				return existing.ID, "", err
+			}
+		}
+		idx.replace(existing, updated)
+		changed = true
+	}
+
+	packs, err := s.Packs.ListByProduct(ctx, existing.ID)
+	if err != nil {
+		return existing.ID, "", err
+	}
+	current := make([]int, len(packs))
+	for i, pack := range packs {
+		current[i] = pack.Size
+	}
+	if packSetKey(current) != packSetKey(item.PackSizes) {
+		if !dryRun {
+			if _, err := s.Packs.ReplaceByProduct(ctx, existing.ID, item.PackSizes); err != nil {
+				return existing.ID, "", err
+			}
+		}
+		changed = true
+	}
+	if changed {
+		return existing.ID, CatalogActionUpdated, nil
+	}
+	return existing.ID, CatalogActionUnchanged, nil
+}
+
+// validateCatalogProduct returns the problems with item, if any.
+func validateCatalogProduct(item model.CatalogProduct) []string {
+	var errs []string
+	if strings.TrimSpace(item.Name) == "" {
+		errs = append(errs, "name is required")
+	}
+	if len(item.PackSizes) == 0 {
+		errs = append(errs, "at least one pack size is required")
+	}
+	if err := validatePackSizes(item.PackSizes); err != nil {
+		errs = append(errs, err.Error())
+	}
+	seen := map[int]bool{}
+	for _, size := range item.PackSizes {
+		if seen[size] {
+			errs = append(errs, fmt.Sprintf("pack size %d is duplicated", size))
+		}
+		seen[size] = true
+	}
+	return errs
+}
+
+// catalogIndex looks up existing products by SKU, ID and name during an import.
+type catalogIndex struct {
+	bySKU  map[string]*model.Product
+	byID   map[uuid.UUID]*model.Product
+	byName map[string]*model.Product
+}
+
+func newCatalogIndex(products []*model.Product) *catalogIndex {
+	idx := &catalogIndex{
+		bySKU:  map[string]*model.Product{},
+		byID:   map[uuid.UUID]*model.Product{},
+		byName: map[string]*model.Product{},
+	}
+	for _, p := range products {
+		idx.add(p)
+	}
+	return idx
+}
+
+func (idx *catalogIndex) match(item model.CatalogProduct) *model.Product {
+	if item.SKU != "" {
+		if p, ok := idx.bySKU[item.SKU]; ok {
+			return p
+		}
+	}
+	if item.ID != uuid.Nil {
+		if p, ok := idx.byID[item.ID]; ok {
+			return p
+		}
+	}
+	// Only products without a SKU are matched by name, so a new SKU never claims another product.
+	if p, ok := idx.byName[item.Name]; ok && (p.SKU == "" || item.SKU == "") {
+		return p
+	}
+	return nil
+}
+
+func (idx *catalogIndex) add(p *model.Product) {
+	if p.SKU != "" {
+		idx.bySKU[p.SKU] = p
+	}
+	if p.ID != uuid.Nil {
+		idx.byID[p.ID] = p
+	}
+	idx.byName[p.Name] = p
+}
+
+func (idx *catalogIndex) replace(old, updated *model.Product) {
+	if old.SKU != "" {
+		delete(idx.bySKU, old.SKU)
+	}
+	if idx.byName[old.Name] == old {
+		delete(idx.byName, old.Name)
+	}
+	idx.add(updated)
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+)
+
+func newTestCatalog() *CatalogService {
+	return &CatalogService{
+		Products: &ProductService{Repo: out.NewProductRepositoryMem()},
+		Packs:    &PackService{Repo: out.NewPackRepositoryMem()},
+	}
+}
+
+func importItems(t *testing.T, svc *CatalogService, dryRun bool, items ...model.CatalogProduct) CatalogImportReport {
+	t.Helper()
+	rows := make([]CatalogRow, len(items))
+	for i, item := range items {
+		rows[i] = CatalogRow{Row: i + 1, Product: item}
+	}
+	report, err := svc.Import(context.Background(), rows, dryRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func counts(r CatalogImportReport) [4]int {
+	return [4]int{r.Created, r.Updated, r.Unchanged, r.Failed}
+}
+
+func TestCatalogService_ImportIsIdempotent(t *testing.T) {
+	svc := newTestCatalog()
+	items := []model.CatalogProduct{
+		{SKU: "SHOE-1", Name: "Shoes", PackSizes: []int{250, 500}},
+		{Name: "Socks", PackSizes: []int{10}},
+	}
+
+	if got := counts(importItems(t, svc, false, items...)); got != [4]int{2, 0, 0, 0} {
+		t.Errorf("first import: got %v", got)
+	}
+	if got := counts(importItems(t, svc, false, items...)); got != [4]int{0, 0, 2, 0} {
+		t.Errorf("second import: got %v", got)
+	}
+
+	items[0].PackSizes = []int{500, 1000}
+	if got := counts(importItems(t, svc, false, items...)); got != [4]int{0, 1, 1, 0} {
+		t.Errorf("third import: got %v", got)
+	}
+
+	catalog, err := svc.Export(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(catalog) != 2 || catalog[0].SKU != "SHOE-1" || !reflect.DeepEqual(catalog[0].PackSizes, []int{500, 1000}) {
+		t.Errorf("unexpected catalog: %+v", catalog)
+	}
+}
+
+func TestCatalogService_ImportMatchesBySKUThenID(t *testing.T) {
+	svc := newTestCatalog()
+	importItems(t, svc, false, model.CatalogProduct{SKU: "SHOE-1", Name: "Shoes", PackSizes: []int{250}})
+	catalog, _ := svc.Export(context.Background())
+
+	// Renamed by SKU
+	report := importItems(t, svc, false, model.CatalogProduct{SKU: "SHOE-1", Name: "Boots", PackSizes: []int{250}})
+	if got := counts(report); got != [4]int{0, 1, 0, 0} {
+		t.Errorf("rename by SKU: got %v", got)
+	}
+	// SKU changed by ID
+	report = importItems(t, svc, false, model.CatalogProduct{ID: catalog[0].ID, SKU: "BOOT-1", Name: "Boots", PackSizes: []int{250}})
+	if got := counts(report); got != [4]int{0, 1, 0, 0} {
+		t.Errorf("new SKU by ID: got %v", got)
+	}
+	p, err := svc.Products.GetByID(context.Background(), catalog[0].ID)
+	if err != nil || p.Name != "Boots" || p.SKU != "BOOT-1" {
+		t.Errorf("expected product to be updated, got %+v, %v", p, err)
+	}
+}
+
+func TestCatalogService_ImportReportsInvalidRows(t *testing.T) {
+	svc := newTestCatalog()
+	report := importItems(t, svc, false,
+		model.CatalogProduct{SKU: "A", Name: "Valid", PackSizes: []int{5}},
+		model.CatalogProduct{SKU: "B", Name: "", PackSizes: []int{0, 5, 5}},
+		model.CatalogProduct{SKU: "A", Name: "Duplicate SKU", PackSizes: []int{5}},
+	)
+	if got := counts(report); got != [4]int{1, 0, 0, 2} {
+		t.Fatalf("got %v", got)
+	}
+	if errs := report.Rows[1].Errors; len(errs) != 3 {
+		t.Errorf("expected 3 errors for row 2, got %v", errs)
+	}
+	if report.Rows[2].Action != CatalogActionError {
+		t.Errorf("expected duplicate SKU row to fail, got %q", report.Rows[2].Action)
+	}
+}
+
+func TestCatalogService_ImportDryRunWritesNothing(t *testing.T) {
+	svc := newTestCatalog()
+	report := importItems(t, svc, true, model.CatalogProduct{Name: "Shoes", PackSizes: []int{250}})
+	if !report.DryRun || report.Created != 1 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	products, _ := svc.Products.List(context.Background())
+	if len(products) != 0 {
+		t.Errorf("expected no products after a dry run, got %d", len(products))
+	}
+}
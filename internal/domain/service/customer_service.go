@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+// ErrInvalidCustomer is returned when a customer or one of its pack rules is malformed.
+var ErrInvalidCustomer = errors.New("invalid customer")
+
+// CustomerService provides business logic for customers and their pack restrictions.
+type CustomerService struct {
+	Repo port.CustomerRepository
+}
+
+func (s *CustomerService) Create(ctx context.Context, customer *model.Customer) (err error) {
+	ctx, span := tracer.Start(ctx, "CustomerService.Create")
+	defer func() { endSpan(span, err) }()
+	if err := validateCustomer(customer); err != nil {
+		return err
+	}
+	normalizeCustomer(customer)
+	return s.Repo.Create(ctx, customer)
+}
+
+func (s *CustomerService) GetByID(ctx context.Context, id uuid.UUID) (_ *model.Customer, err error) {
+	ctx, span := tracer.Start(ctx, "CustomerService.GetByID")
+	span.SetAttributes(attribute.String("customer.id", id.String()))
+	defer func() { endSpan(span, err) }()
+	return s.Repo.GetByID(ctx, id)
+}
+
+func (s *CustomerService) Update(ctx context.Context, customer *model.Customer) (err error) {
+	ctx, span := tracer.Start(ctx, "CustomerService.Update")
+	defer func() { endSpan(span, err) }()
+	if err := validateCustomer(customer); err != nil {
+		return err
+	}
+	normalizeCustomer(customer)
+	return s.Repo.Update(ctx, customer)
+}
+
+func (s *CustomerService) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "CustomerService.Delete")
+	defer func() { endSpan(span, err) }()
+	return s.Repo.Delete(ctx, id)
+}
+
+func (s *CustomerService) List(ctx context.Context) (_ []*model.Customer, err error) {
+	ctx, span := tracer.Start(ctx, "CustomerService.List")
+	defer func() { endSpan(span, err) }()
+	return s.Repo.List(ctx)
+}
+
+// normalizeCustomer makes an absent rule list an empty one.
+func normalizeCustomer(customer *model.Customer) {
+	if customer.PackRules == nil {
+		customer.PackRules = []model.PackRule{}
+	}
+}
+
+// validateCustomer checks that the customer is named and that its pack rules are well formed,
+// with at most one rule per product and at most one rule for all products.
+func validateCustomer(customer *model.Customer) error {
+	if strings.TrimSpace(customer.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCustomer)
+	}
+	seen := map[uuid.UUID]bool{}
+	for _, rule := range customer.PackRules {
+		if seen[rule.ProductID] {
+			if rule.ProductID == uuid.Nil {
+				return fmt.Errorf("%w: more than one rule applies to all products", ErrInvalidCustomer)
+			}
+			return fmt.Errorf("%w: more than one rule for product %s", ErrInvalidCustomer, rule.ProductID)
+		}
+		seen[rule.ProductID] = true
+		for _, size := range append(append([]int{}, rule.AllowedSizes...), rule.ForbiddenSizes...) {
+			if size <= 0 {
+				return fmt.Errorf("%w: pack size %d must be positive", ErrInvalidCustomer, size)
+			}
+		}
+		if rule.MaxPackSize < 0 {
+			return fmt.Errorf("%w: max pack size %d must not be negative", ErrInvalidCustomer, rule.MaxPackSize)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+)
+
+// Defaults of EventStream.
+const (
+	defaultEventStreamSize = 1000
+	eventStreamSubBuffer   = 64
+)
+
+// StreamedEvent is an event with its position in an EventStream. Positions increase by one per event
+// and let a client that reconnects resume after the last event it received.
+type StreamedEvent struct {
+	Seq uint64
+	*model.Event
+}
+
+// EventFilter selects events by product and type. The zero filter selects every event.
+type EventFilter struct {
+	ProductID uuid.UUID // uuid.Nil for every product
+	Types     []string  // empty for every type
+}
+
+// Matches reports whether the filter selects event.
+func (f EventFilter) Matches(event *model.Event) bool {
+	return (f.ProductID == uuid.Nil || event.ProductID == f.ProductID) &&
+		(len(f.Types) == 0 || slices.Contains(f.Types, event.Type))
+}
+
+// EventStream fans domain events out to live subscribers and keeps the most recent ones, so that
+// subscribers can catch up on what they missed while disconnected. It is a port.EventPublisher fed by the
+// outbox relay; since the relay delivers at least once, events already in the buffer are dropped.
+type EventStream struct {
+	mu   sync.Mutex
+	buf  []StreamedEvent // ring of the last len(buf) events; buf[seq % len(buf)] holds event seq
+	seen map[uuid.UUID]struct{}
+	last uint64 // seq of the newest event; 0 before the first
+	subs map[*EventSubscription]struct{}
+}
+
+// NewEventStream returns a stream keeping the last size events, or 1000 if size is not positive.
+func NewEventStream(size int) *EventStream {
+	if size <= 0 {
+		size = defaultEventStreamSize
+	}
+	return &EventStream{
+		buf:  make([]StreamedEvent, size),
+		seen: make(map[uuid.UUID]struct{}, size),
+		subs: make(map[*EventSubscription]struct{}),
+	}
+}
+
+// Publish appends event to the buffer and hands it to the subscribers whose filter matches. A subscriber
+// too slow to keep up is closed rather than allowed to hold the stream back; it can resubscribe from the
+// last event it received.
+func (s *EventStream) Publish(_ context.Context, event *model.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, dup := s.seen[event.ID]; dup {
+		return nil
+	}
+	s.last++
+	slot := &s.buf[s.last%uint64(len(s.buf))]
+	if slot.Event != nil {
+		delete(s.seen, slot.ID)
+	}
+	*slot = StreamedEvent{Seq: s.last, Event: event}
+	s.seen[event.ID] = struct{}{}
+
+	for sub := range s.subs {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- *slot:
+		default:
+			s.unsubscribe(sub)
+		}
+	}
+	return nil
+}
+
+// Wants reports whether a current subscriber's filter selects events of eventType.
+func (s *EventStream) Wants(_ context.Context, eventType string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subs {
+		if len(sub.filter.Types) == 0 || slices.Contains(sub.filter.Types, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribe registers a subscriber for the events matching filter. If after is non-zero, it also returns
+// the buffered events matching filter that follow position after, oldest first; complete is false when
+// some of the events following after have already left the buffer.
+func (s *EventStream) Subscribe(filter EventFilter, after uint64) (sub *EventSubscription, backlog []StreamedEvent, complete bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub = &EventSubscription{stream: s, filter: filter, events: make(chan StreamedEvent, eventStreamSubBuffer)}
+	s.subs[sub] = struct{}{}
+
+	if after == 0 || after == s.last {
+		return sub, nil, true
+	}
+	// A position beyond the newest event comes from before a restart, when positions started over.
+	first, complete := after+1, after < s.last
+	if oldest := s.oldest(); first < oldest || !complete {
+		first, complete = oldest, false
+	}
+	for seq := first; seq <= s.last; seq++ {
+		if e := s.buf[seq%uint64(len(s.buf))]; filter.Matches(e.Event) {
+			backlog = append(backlog, e)
+		}
+	}
+	return sub, backlog, complete
+}
+
+// oldest returns the position of the oldest buffered event.
+func (s *EventStream) oldest() uint64 {
+	if s.last < uint64(len(s.buf)) {
+		return 1
+	}
+	return s.last - uint64(len(s.buf)) + 1
+}
+
+func (s *EventStream) unsubscribe(sub *EventSubscription) {
+	if _, ok := s.subs[sub]; ok {
+		delete(s.subs, sub)
+		close(sub.events)
+	}
+}
+
+// EventSubscription receives the events of an EventStream that match its filter.
+type EventSubscription struct {
+	stream *EventStream
+	filter EventFilter
+	events chan StreamedEvent
+}
+
+// Events returns the channel of new events. It is closed by Close, or when the subscriber falls too far
+// behind.
+func (sub *EventSubscription) Events() <-chan StreamedEvent {
+	return sub.events
+}
+
+// Close stops the subscription.
+func (sub *EventSubscription) Close() {
+	sub.stream.mu.Lock()
+	defer sub.stream.mu.Unlock()
+	sub.stream.unsubscribe(sub)
+}
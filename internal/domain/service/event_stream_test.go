@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+)
+
+func publishEvents(t *testing.T, stream *EventStream, productID uuid.UUID, types ...string) []*model.Event {
+	t.Helper()
+	var events []*model.Event
+	for _, eventType := range types {
+		event := mustEvent(t, eventType, productID, time.Now())
+		if err := stream.Publish(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func seqs(events []StreamedEvent) []uint64 {
+	var s []uint64
+	for _, e := range events {
+		s = append(s, e.Seq)
+	}
+	return s
+}
+
+func TestEventStream_DeliversMatchingEvents(t *testing.T) {
+	stream := NewEventStream(10)
+	shoes, hats := uuid.New(), uuid.New()
+	sub, backlog, _ := stream.Subscribe(EventFilter{ProductID: shoes, Types: []string{model.EventPacksReplaced}}, 0)
+	defer sub.Close()
+	if len(backlog) != 0 {
+		t.Fatalf("expected no backlog for a new subscriber, got %v", seqs(backlog))
+	}
+
+	publishEvents(t, stream, hats, model.EventPacksReplaced)
+	publishEvents(t, stream, shoes, model.EventProductCreated, model.EventPacksReplaced)
+
+	select {
+	case e := <-sub.Events():
+		if e.Seq != 3 || e.ProductID != shoes || e.Type != model.EventPacksReplaced {
+			t.Fatalf("expected event 3, the shoes' PacksReplaced, got %d %s", e.Seq, e.Type)
+		}
+	default:
+		t.Fatal("expected an event")
+	}
+	select {
+	case e := <-sub.Events():
+		t.Fatalf("expected no other event, got %d %s", e.Seq, e.Type)
+	default:
+	}
+}
+
+func TestEventStream_ResumesFromBuffer(t *testing.T) {
+	stream := NewEventStream(3)
+	events := publishEvents(t, stream, uuid.New(), model.EventProductCreated, model.EventPacksReplaced,
+		model.EventProductUpdated, model.EventPacksReplaced, model.EventPacksReplaced)
+	// A duplicate delivery from the relay is dropped.
+	if err := stream.Publish(context.Background(), events[4]); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		filter   EventFilter
+		after    uint64
+		want     []uint64
+		complete bool
+	}{
+		{"caught up", EventFilter{}, 5, nil, true},
+		{"within buffer", EventFilter{}, 3, []uint64{4, 5}, true},
+		{"filtered", EventFilter{Types: []string{model.EventProductUpdated}}, 2, []uint64{3}, true},
+		{"partly evicted", EventFilter{}, 1, []uint64{3, 4, 5}, false},
+		{"before a restart", EventFilter{}, 9, []uint64{3, 4, 5}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, backlog, complete := stream.Subscribe(tt.filter, tt.after)
+			defer sub.Close()
+			if !slices.Equal(seqs(backlog), tt.want) || complete != tt.complete {
+				t.Errorf("expected backlog %v (complete %t), got %v (complete %t)", tt.want, tt.complete, seqs(backlog), complete)
+			}
+		})
+	}
+}
+
+func TestEventStream_ClosesSlowSubscribers(t *testing.T) {
+	stream := NewEventStream(0)
+	sub, _, _ := stream.Subscribe(EventFilter{}, 0)
+	types := make([]string, eventStreamSubBuffer+1)
+	for i := range types {
+		types[i] = model.EventFulfillmentComputed
+	}
+	publishEvents(t, stream, uuid.New(), types...)
+
+	n := 0
+	for range sub.Events() {
+		n++
+	}
+	if n != eventStreamSubBuffer {
+		t.Errorf("expected the %d buffered events before the channel closes, got %d", eventStreamSubBuffer, n)
+	}
+	sub.Close() // closing again is harmless
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+// Defaults of OutboxRelay.
+const (
+	defaultRelayBatchSize = 100
+	defaultRelayInterval  = time.Second
+	defaultRelayClaimTTL  = 30 * time.Second
+)
+
+// EventRecorder writes the domain events raised by changes to the outbox, in the same transaction as the
+// changes, so that an event is recorded if and only if its change is committed. Services with a nil
+// recorder raise no events.
+type EventRecorder struct {
+	Tx     port.Transactor
+	Outbox port.OutboxRepository
+	// Wants reports whether events of a type that are not tied to a change are to be recorded, see
+	// OutboxRelay.Wants; nil records them all.
+	Wants func(ctx context.Context, eventType string) bool
+}
+
+// record runs change and appends the events it returns to the outbox, in one transaction.
+func (r *EventRecorder) record(ctx context.Context, change func(ctx context.Context) ([]*model.Event, error)) error {
+	if r == nil {
+		_, err := change(ctx)
+		return err
+	}
+	return r.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		events, err := change(ctx)
+		if err != nil {
+			return err
+		}
+		return r.Outbox.Append(ctx, events...)
+	})
+}
+
+// raise appends an event that is not tied to a change, unless nobody wants it. Failures are logged rather
+// than returned, since nothing is to be rolled back.
+func (r *EventRecorder) raise(ctx context.Context, eventType string, productID uuid.UUID, payload any) {
+	if r == nil || r.Wants != nil && !r.Wants(ctx, eventType) {
+		return
+	}
+	events, err := newEvents(eventType, productID, payload)
+	if err == nil {
+		err = r.Outbox.Append(ctx, events...)
+	}
+	if err != nil {
+		slog.Error("Failed to record event", "type", eventType, "product_id", productID, "error", err)
+	}
+}
+
+// newEvents returns a single event of type eventType about productID, occurring now.
+func newEvents(eventType string, productID uuid.UUID, payload any) ([]*model.Event, error) {
+	event, err := model.NewEvent(eventType, productID, payload, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return []*model.Event{event}, nil
+}
+
+// OutboxRelay delivers the events in the outbox to every publisher, oldest first. An event that a publisher
+// fails to take stays in the outbox and is retried once its claim expires, after the events behind it, by
+// the publishers that have not taken it yet.
+type OutboxRelay struct {
+	Outbox port.OutboxRepository
+	// Publishers by name, under which the outbox records which publishers took an event.
+	Publishers map[string]port.EventPublisher
+	BatchSize  int           // Events claimed at once; 0 means 100
+	Interval   time.Duration // Pause after draining the outbox; 0 means 1s
+	ClaimTTL   time.Duration // How long claimed events are withheld from other relays; 0 means 30s
+}
+
+// Run relays events until ctx is done.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = defaultRelayInterval
+	}
+	for {
+		n, err := r.RelayBatch(ctx)
+		if err != nil {
+			slog.Error("Failed to relay events", "error", err)
+		}
+		if err == nil && n == r.batchSize() {
+			continue // more events are likely waiting
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// RelayBatch claims a batch of pending events, publishes them and returns how many it claimed.
+func (r *OutboxRelay) RelayBatch(ctx context.Context) (n int, err error) {
+	ctx, span := tracer.Start(ctx, "OutboxRelay.RelayBatch")
+	defer func() { endSpan(span, err) }()
+	ttl := r.ClaimTTL
+	if ttl <= 0 {
+		ttl = defaultRelayClaimTTL
+	}
+	events, err := r.Outbox.Claim(ctx, r.batchSize(), time.Now().Add(ttl))
+	if err != nil {
+		return 0, err
+	}
+	failed := 0
+	for _, event := range events {
+		if publishedTo, err := r.publish(ctx, event); err != nil {
+			failed++
+			slog.Warn("Failed to publish event", "id", event.ID, "type", event.Type, "error", err)
+			if err := r.Outbox.RecordFailure(ctx, event.ID, publishedTo, err.Error()); err != nil {
+				return len(events), err
+			}
+			continue
+		}
+		if err := r.Outbox.MarkPublished(ctx, event.ID); err != nil {
+			return len(events), err
+		}
+	}
+	span.SetAttributes(attribute.Int("outbox.claimed", len(events)), attribute.Int("outbox.failed", failed))
+	return len(events), nil
+}
+
+// Wants reports whether any publisher wants events of eventType; see port.SelectivePublisher.
+func (r *OutboxRelay) Wants(ctx context.Context, eventType string) bool {
+	for _, p := range r.Publishers {
+		if selective, ok := p.(port.SelectivePublisher); !ok || selective.Wants(ctx, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// publish hands event to the publishers that have not taken it yet and returns the names of those that
+// have taken it now.
+func (r *OutboxRelay) publish(ctx context.Context, event *model.OutboxEvent) ([]string, error) {
+	publishedTo := event.PublishedTo
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(r.Publishers)) {
+		if slices.Contains(publishedTo, name) {
+			continue
+		}
+		if err := r.Publishers[name].Publish(ctx, event.Event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		publishedTo = append(publishedTo, name)
+	}
+	return publishedTo, errors.Join(errs...)
+}
+
+func (r *OutboxRelay) batchSize() int {
+	if r.BatchSize <= 0 {
+		return defaultRelayBatchSize
+	}
+	return r.BatchSize
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+// recordingPublisher collects the types of published events, failing while err is set.
+type recordingPublisher struct {
+	types []string
+	err   error
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event *model.Event) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.types = append(p.types, event.Type)
+	return nil
+}
+
+func TestOutboxRelay_DeliversRecordedEvents(t *testing.T) {
+	ctx := context.Background()
+	recorder := &EventRecorder{Tx: out.TransactorMem{}, Outbox: out.NewOutboxRepositoryMem()}
+	products := &ProductService{Repo: out.NewProductRepositoryMem(), Events: recorder}
+	packs := &PackService{Repo: out.NewPackRepositoryMem(), Events: recorder}
+	publisher := &recordingPublisher{err: errors.New("unavailable")}
+	relay := &OutboxRelay{Outbox: recorder.Outbox, Publishers: map[string]port.EventPublisher{"recording": publisher}, ClaimTTL: time.Nanosecond}
+
+	p := &model.Product{Name: "Shoes"}
+	if err := products.Create(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := packs.ReplaceByProduct(ctx, p.ID, []int{250, 500}); err != nil {
+		t.Fatal(err)
+	}
+	if err := products.Delete(ctx, p.ID); err != nil {
+		t.Fatal(err)
+	}
+	// A failed change raises no event.
+	if err := products.Delete(ctx, p.ID); err == nil {
+		t.Fatal("expected deleting a missing product to fail")
+	}
+
+	if n, err := relay.RelayBatch(ctx); n != 3 || err != nil {
+		t.Fatalf("expected 3 claimed events, got %d, %v", n, err)
+	}
+	publisher.err = nil
+	time.Sleep(time.Millisecond) // let the failed events' claims expire
+	if _, err := relay.RelayBatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{model.EventProductCreated, model.EventPacksReplaced, model.EventProductDeleted}
+	if !slices.Equal(publisher.types, want) {
+		t.Errorf("published %v, want %v", publisher.types, want)
+	}
+	if n, _ := relay.RelayBatch(ctx); n != 0 {
+		t.Errorf("expected published events to leave the outbox, got %d pending", n)
+	}
+}
+
+func TestOutboxRelay_RetriesOnlyFailedPublishers(t *testing.T) {
+	ctx := context.Background()
+	recorder := &EventRecorder{Tx: out.TransactorMem{}, Outbox: out.NewOutboxRepositoryMem()}
+	products := &ProductService{Repo: out.NewProductRepositoryMem(), Events: recorder}
+	healthy, flaky := &recordingPublisher{}, &recordingPublisher{err: errors.New("unavailable")}
+	relay := &OutboxRelay{Outbox: recorder.Outbox, ClaimTTL: time.Nanosecond,
+		Publishers: map[string]port.EventPublisher{"healthy": healthy, "flaky": flaky}}
+
+	if err := products.Create(ctx, &model.Product{Name: "Shoes"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := relay.RelayBatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	flaky.err = nil
+	time.Sleep(time.Millisecond) // let the failed event's claim expire
+	if _, err := relay.RelayBatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{model.EventProductCreated}
+	if !slices.Equal(healthy.types, want) || !slices.Equal(flaky.types, want) {
+		t.Errorf("expected each publisher to take the event once, got %v and %v", healthy.types, flaky.types)
+	}
+	if n, _ := relay.RelayBatch(ctx); n != 0 {
+		t.Errorf("expected the published event to leave the outbox, got %d pending", n)
+	}
+}
+
+func TestEventRecorder_RaisesOnlyWantedEvents(t *testing.T) {
+	ctx := context.Background()
+	stream := NewEventStream(10)
+	recorder := &EventRecorder{Tx: out.TransactorMem{}, Outbox: out.NewOutboxRepositoryMem()}
+	relay := &OutboxRelay{Outbox: recorder.Outbox, Publishers: map[string]port.EventPublisher{"stream": stream}}
+	recorder.Wants = relay.Wants
+	fulfillment := &PackFulfillmentService{Events: recorder}
+	fulfill := func() {
+		if _, err := fulfillment.FulfillProductOrder(ctx, uuid.New(), 10, []int{5}, FulfillmentOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fulfill()
+	if n, _ := relay.RelayBatch(ctx); n != 0 {
+		t.Errorf("expected no event without a subscriber, got %d", n)
+	}
+	sub, _, _ := stream.Subscribe(EventFilter{Types: []string{model.EventFulfillmentComputed}}, 0)
+	defer sub.Close()
+	fulfill()
+	if n, _ := relay.RelayBatch(ctx); n != 1 {
+		t.Errorf("expected an event for the subscriber, got %d", n)
+	}
+}
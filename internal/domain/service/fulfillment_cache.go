@@ -0,0 +1,149 @@
+package service
+
+import (
+	"container/list"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// CacheStats reports the usage of a FulfillmentCache.
+type CacheStats struct {
+	Hits     uint64
+	Misses   uint64
+	Size     int
+	Capacity int
+}
+
+type cacheKey struct {
+	packSet  string
+	quantity int
+}
+
+type cacheEntry struct {
+	key    cacheKey
+	result PackFulfillmentResult
+}
+
+// FulfillmentCache is a bounded LRU cache of fulfillment results keyed by the normalized pack set and quantity.
+// It implements PackChangeListener, dropping results for a product's previous pack set when its packs are replaced.
+type FulfillmentCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // front is most recently used
+	entries  map[cacheKey]*list.Element
+	hits     uint64
+	misses   uint64
+}
+
+// NewFulfillmentCache creates a cache holding at most capacity results.
+func NewFulfillmentCache(capacity int) *FulfillmentCache {
+	return &FulfillmentCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[cacheKey]*list.Element),
+	}
+}
+
+// Get returns the cached result for quantity over packSizes, if present.
+func (c *FulfillmentCache) Get(packSizes []int, quantity int) (PackFulfillmentResult, bool) {
+	key := cacheKey{packSet: packSetKey(packSizes), quantity: quantity}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return PackFulfillmentResult{}, false
+	}
+	c.hits++
+	c.order.MoveToFront(el)
+	return copyResult(el.Value.(*cacheEntry).result), true
+}
+
+// Add stores result for quantity over packSizes, evicting the least recently used entry when full.
+func (c *FulfillmentCache) Add(packSizes []int, quantity int, result PackFulfillmentResult) {
+	key := cacheKey{packSet: packSetKey(packSizes), quantity: quantity}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.capacity <= 0 {
+		return
+	}
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*cacheEntry).result = copyResult(result)
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, result: copyResult(result)})
+	for c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// Resize changes the capacity of the cache, evicting the least recently used entries that no longer fit.
+// A capacity of 0 empties the cache and stops it from storing results.
+func (c *FulfillmentCache) Resize(capacity int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.capacity = max(capacity, 0)
+	for c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// InvalidatePackSet drops every cached result computed over packSizes.
+func (c *FulfillmentCache) InvalidatePackSet(packSizes []int) {
+	packSet := packSetKey(packSizes)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for el := c.order.Front(); el != nil; {
+		next := el.Next()
+		if el.Value.(*cacheEntry).key.packSet == packSet {
+			c.removeElement(el)
+		}
+		el = next
+	}
+}
+
+// PacksReplaced implements PackChangeListener.
+func (c *FulfillmentCache) PacksReplaced(_ uuid.UUID, oldSizes, _ []int) {
+	if len(oldSizes) > 0 {
+		c.InvalidatePackSet(oldSizes)
+	}
+}
+
+// Stats returns the hit/miss counters and current size of the cache.
+func (c *FulfillmentCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Hits: c.hits, Misses: c.misses, Size: c.order.Len(), Capacity: c.capacity}
+}
+
+func (c *FulfillmentCache) removeElement(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*cacheEntry).key)
+}
+
+// packSetKey normalizes pack sizes into a canonical key: sorted ascending, duplicates removed.
+func packSetKey(packSizes []int) string {
+	sorted := slices.Clone(packSizes)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+	parts := make([]string, len(sorted))
+	for i, size := range sorted {
+		parts[i] = strconv.Itoa(size)
+	}
+	return strings.Join(parts, ",")
+}
+
+// copyResult returns a copy of r that does not share its Packs map.
+func copyResult(r PackFulfillmentResult) PackFulfillmentResult {
+	packs := make(map[int]int, len(r.Packs))
+	for size, count := range r.Packs {
+		packs[size] = count
+	}
+	r.Packs = packs
+	return r
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+)
+
+func TestFulfillmentCache_NormalizesPackSet(t *testing.T) {
+	cache := NewFulfillmentCache(10)
+	cache.Add([]int{500, 250, 250}, 251, PackFulfillmentResult{TotalItems: 500, Packs: map[int]int{500: 1}})
+
+	got, ok := cache.Get([]int{250, 500}, 251)
+	if !ok {
+		t.Fatal("expected cache hit for the same pack set in a different order")
+	}
+	if !reflect.DeepEqual(got.Packs, map[int]int{500: 1}) {
+		t.Errorf("Packs: got %v, want %v", got.Packs, map[int]int{500: 1})
+	}
+	if _, ok := cache.Get([]int{250, 500}, 252); ok {
+		t.Error("expected cache miss for a different quantity")
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, got %+v", stats)
+	}
+}
+
+func TestFulfillmentCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewFulfillmentCache(2)
+	packs := []int{250, 500}
+	cache.Add(packs, 1, PackFulfillmentResult{TotalItems: 250})
+	cache.Add(packs, 2, PackFulfillmentResult{TotalItems: 250})
+	cache.Get(packs, 1) // 2 becomes least recently used
+	cache.Add(packs, 3, PackFulfillmentResult{TotalItems: 250})
+
+	if _, ok := cache.Get(packs, 2); ok {
+		t.Error("expected quantity 2 to be evicted")
+	}
+	for _, q := range []int{1, 3} {
+		if _, ok := cache.Get(packs, q); !ok {
+			t.Errorf("expected quantity %d to be cached", q)
+		}
+	}
+}
+
+func TestFulfillmentCache_InvalidatedWhenPacksReplaced(t *testing.T) {
+	productID := uuid.New()
+	cache := NewFulfillmentCache(10)
+	packSvc := &PackService{Repo: out.NewPackRepositoryMem(), Listeners: []PackChangeListener{cache}}
+	fulfillSvc := &PackFulfillmentService{Cache: cache}
+
+	ctx := context.Background()
+	if _, err := packSvc.ReplaceByProduct(ctx, productID, []int{250, 500}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fulfillSvc.FulfillOrder(ctx, 251, []int{250, 500}, FulfillmentOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get([]int{250, 500}, 251); !ok {
+		t.Fatal("expected result to be cached")
+	}
+
+	if _, err := packSvc.ReplaceByProduct(ctx, productID, []int{300}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get([]int{250, 500}, 251); ok {
+		t.Error("expected results for the previous pack set to be invalidated")
+	}
+}
+
+func TestFulfillmentCache_ResultsAreCopied(t *testing.T) {
+	cache := NewFulfillmentCache(10)
+	result := PackFulfillmentResult{TotalItems: 500, Packs: map[int]int{500: 1}}
+	cache.Add([]int{500}, 500, result)
+	result.Packs[500] = 2
+
+	got, _ := cache.Get([]int{500}, 500)
+	got.Packs[500] = 3
+	again, _ := cache.Get([]int{500}, 500)
+	if again.Packs[500] != 1 {
+		t.Errorf("expected cached result to be unaffected by callers, got %v", again.Packs)
+	}
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Fulfillment modes. Over, the default, ships at least the quantity with the smallest overage; under ships
+// the largest total not exceeding it; exact_only ships exactly the quantity or nothing.
+const (
+	ModeOver      = "over"
+	ModeUnder     = "under"
+	ModeExactOnly = "exact_only"
+)
+
+// Constraint names, as used in FulfillmentOptions' query parameters and InfeasibleError.
+const (
+	ConstraintMaxOverage    = "max_overage"
+	ConstraintMaxOveragePct = "max_overage_pct"
+	ConstraintMaxPacks      = "max_packs"
+)
+
+// ErrInvalidOptions is returned when a fulfillment constraint is out of range.
+var ErrInvalidOptions = errors.New("invalid fulfillment options")
+
+// FulfillmentOptions select the fulfillment mode and optional hard constraints on the plan. The zero value
+// leaves the order unconstrained in over mode.
+type FulfillmentOptions struct {
+	Mode          string   // One of the Mode constants; empty means ModeOver
+	MaxOverage    *int     // Most items that may be shipped beyond the quantity
+	MaxOveragePct *float64 // Most items that may be shipped beyond the quantity, as a percentage of it
+	MaxPacks      *int     // Most packs the plan may use
+	Explain       bool     // Attach an Explanation to the result
+}
+
+// Validate checks that the mode is known and the constraints are not negative.
+func (o FulfillmentOptions) Validate() error {
+	switch o.Mode {
+	case "", ModeOver, ModeUnder, ModeExactOnly:
+	default:
+		return fmt.Errorf("%w: unknown mode %q", ErrInvalidOptions, o.Mode)
+	}
+	if o.MaxOverage != nil && *o.MaxOverage < 0 {
+		return fmt.Errorf("%w: %s must not be negative", ErrInvalidOptions, ConstraintMaxOverage)
+	}
+	if o.MaxOveragePct != nil && (*o.MaxOveragePct < 0 || math.IsNaN(*o.MaxOveragePct)) {
+		return fmt.Errorf("%w: %s must not be negative", ErrInvalidOptions, ConstraintMaxOveragePct)
+	}
+	if o.MaxPacks != nil && *o.MaxPacks < 0 {
+		return fmt.Errorf("%w: %s must not be negative", ErrInvalidOptions, ConstraintMaxPacks)
+	}
+	return nil
+}
+
+// InfeasibleError reports that no pack distribution satisfies an order's constraints.
+type InfeasibleError struct {
+	Constraints []string `json:"constraints"` // Constraints that blocked every plan
+	Reason      string   `json:"reason"`
+	MinOverage  int      `json:"min_overage"` // Smallest overage any plan achieves
+	MinPacks    int      `json:"min_packs"`   // Fewest packs any plan uses
+}
+
+func (e *InfeasibleError) Error() string {
+	return "infeasible fulfillment: " + e.Reason
+}
+
+// solveLimits bound the solver's search when an order is constrained.
+type solveLimits struct {
+	maxOverage int
+	maxPacks   int
+	under      bool
+}
+
+// limits resolves the options for quantity into solver limits, combining the overage constraints.
+func (o FulfillmentOptions) limits(quantity int) solveLimits {
+	l := solveLimits{maxOverage: math.MaxInt, maxPacks: math.MaxInt, under: o.Mode == ModeUnder}
+	if o.Mode == ModeUnder || o.Mode == ModeExactOnly {
+		l.maxOverage = 0
+	}
+	if o.MaxOverage != nil {
+		l.maxOverage = min(l.maxOverage, *o.MaxOverage)
+	}
+	if o.MaxOveragePct != nil {
+		l.maxOverage = min(l.maxOverage, maxOverageForPct(quantity, *o.MaxOveragePct))
+	}
+	if o.MaxPacks != nil {
+		l.maxPacks = *o.MaxPacks
+	}
+	return l
+}
+
+// constrain returns optimum if it satisfies opts, and otherwise the best distribution that does. Since
+// optimum has the smallest overage, and the fewest packs for it, a constrained plan trades extra items for
+// fewer packs.
+func (s *PackFulfillmentService) constrain(ctx context.Context, quantity int, packSizes []int, opts FulfillmentOptions, optimum PackFulfillmentResult) (PackFulfillmentResult, error) {
+	if err := opts.Validate(); err != nil {
+		return PackFulfillmentResult{}, err
+	}
+	if len(opts.names()) == 0 {
+		return optimum, nil
+	}
+	limits := opts.limits(quantity)
+	overage := optimum.TotalItems - quantity
+	exact := opts.Mode != ModeExactOnly || overage == 0
+	if exact && overage <= limits.maxOverage && optimum.PackCount() <= limits.maxPacks {
+		return optimum, nil
+	}
+
+	infeasible := &InfeasibleError{MinOverage: overage, MinPacks: minPackCount(quantity, packSizes)}
+	var reasons []string
+	if !exact {
+		infeasible.Constraints = append(infeasible.Constraints, ModeExactOnly)
+		reasons = append(reasons, fmt.Sprintf("the quantity cannot be met exactly; the smallest achievable overage is %d items", overage))
+	}
+	if opts.MaxOverage != nil && overage > *opts.MaxOverage {
+		infeasible.Constraints = append(infeasible.Constraints, ConstraintMaxOverage)
+		reasons = append(reasons, fmt.Sprintf("the smallest achievable overage is %d items, above %s of %d", overage, ConstraintMaxOverage, *opts.MaxOverage))
+	}
+	if opts.MaxOveragePct != nil && overage > maxOverageForPct(quantity, *opts.MaxOveragePct) {
+		infeasible.Constraints = append(infeasible.Constraints, ConstraintMaxOveragePct)
+		reasons = append(reasons, fmt.Sprintf("the smallest achievable overage is %d items, above %s of %g%%", overage, ConstraintMaxOveragePct, *opts.MaxOveragePct))
+	}
+	if opts.MaxPacks != nil && infeasible.MinPacks > *opts.MaxPacks {
+		infeasible.Constraints = append(infeasible.Constraints, ConstraintMaxPacks)
+		reasons = append(reasons, fmt.Sprintf("at least %d packs are needed, above %s of %d", infeasible.MinPacks, ConstraintMaxPacks, *opts.MaxPacks))
+	}
+	if len(reasons) > 0 {
+		infeasible.Reason = strings.Join(reasons, "; ")
+		return PackFulfillmentResult{}, infeasible
+	}
+
+	// Each constraint can be met on its own, so search for a plan that meets them together.
+	result, err := s.search(ctx, quantity, slices.Clone(packSizes), &limits, opts.Explain)
+	if err != nil {
+		return PackFulfillmentResult{}, err
+	}
+	if result.TotalItems >= 0 {
+		if result.Explanation != nil {
+			rejected := RejectedPlan{TotalItems: optimum.TotalItems, Packs: optimum.Packs, PackCount: optimum.PackCount(), Reason: violationReason(opts, quantity, optimum)}
+			result.Explanation.RunnersUp = append([]RejectedPlan{rejected}, result.Explanation.RunnersUp...)
+		}
+		return result, nil
+	}
+	infeasible.Constraints = opts.names()
+	infeasible.Reason = "no plan stays within the overage and pack count limits together"
+	return PackFulfillmentResult{}, infeasible
+}
+
+// names returns the names of the constraints that are set, with exact_only mode counting as one.
+func (o FulfillmentOptions) names() []string {
+	var names []string
+	if o.Mode == ModeExactOnly {
+		names = append(names, ModeExactOnly)
+	}
+	if o.MaxOverage != nil {
+		names = append(names, ConstraintMaxOverage)
+	}
+	if o.MaxOveragePct != nil {
+		names = append(names, ConstraintMaxOveragePct)
+	}
+	if o.MaxPacks != nil {
+		names = append(names, ConstraintMaxPacks)
+	}
+	return names
+}
+
+// fulfillUnder returns the distribution with the largest total not exceeding quantity, using the fewest
+// packs for it, within opts' pack limit. Such a distribution always exists, if only an empty one.
+func (s *PackFulfillmentService) fulfillUnder(ctx context.Context, quantity int, packSizes []int, opts FulfillmentOptions) (PackFulfillmentResult, error) {
+	if err := opts.Validate(); err != nil {
+		return PackFulfillmentResult{}, err
+	}
+	limits := opts.limits(quantity)
+	result, err := s.search(ctx, quantity, packSizes, &limits, opts.Explain)
+	if err != nil {
+		return PackFulfillmentResult{}, err
+	}
+	result.Shortfall = quantity - result.TotalItems
+	return result, nil
+}
+
+// maxOverageForPct converts a percentage of quantity into a whole number of items, rounding down.
+func maxOverageForPct(quantity int, pct float64) int {
+	return int(math.Floor(float64(quantity) * pct / 100))
+}
+
+// minPackCount returns the fewest packs that cover quantity, which is achieved with the largest pack alone.
+func minPackCount(quantity int, packSizes []int) int {
+	if quantity <= 0 || len(packSizes) == 0 {
+		return 0
+	}
+	largest := slices.Max(packSizes)
+	return (quantity + largest - 1) / largest
+}
+
+// constraintAttributes describes opts on a span.
+func constraintAttributes(opts FulfillmentOptions) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if opts.Mode != "" {
+		attrs = append(attrs, attribute.String("fulfillment.mode", opts.Mode))
+	}
+	if opts.MaxOverage != nil {
+		attrs = append(attrs, attribute.Int("fulfillment.max_overage", *opts.MaxOverage))
+	}
+	if opts.MaxOveragePct != nil {
+		attrs = append(attrs, attribute.Float64("fulfillment.max_overage_pct", *opts.MaxOveragePct))
+	}
+	if opts.MaxPacks != nil {
+		attrs = append(attrs, attribute.Int("fulfillment.max_packs", *opts.MaxPacks))
+	}
+	return attrs
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// runnersUpCount is how many rejected plans an Explanation lists.
+const runnersUpCount = 3
+
+// maxFrobeniusModulus bounds the smallest pack size for which the Frobenius number is computed, since the
+// computation needs memory proportional to it.
+const maxFrobeniusModulus = 1 << 20
+
+// Explanation describes how a fulfillment result was chosen.
+type Explanation struct {
+	Overage   int            `json:"overage"`
+	PackCount int            `json:"pack_count"`
+	RunnersUp []RejectedPlan `json:"runners_up"`
+	// GCD of the pack sizes; only multiples of it can be shipped exactly.
+	GCD int `json:"gcd"`
+	// LargestUnreachable is the largest quantity no combination ships exactly (the Frobenius number). It is
+	// null when the GCD is above 1, as infinitely many quantities are then unreachable, and when every
+	// quantity is reachable.
+	LargestUnreachable *int        `json:"largest_unreachable"`
+	Solver             SolverStats `json:"solver"`
+}
+
+// RejectedPlan is a plan the solver considered and the reason it lost to the chosen one.
+type RejectedPlan struct {
+	TotalItems int         `json:"total_items"`
+	Packs      map[int]int `json:"packs"`
+	PackCount  int         `json:"pack_count"`
+	Reason     string      `json:"reason"`
+}
+
+// SolverStats reports the work done by the search that produced a result.
+type SolverStats struct {
+	StatesExplored int     `json:"states_explored"`
+	ElapsedMs      float64 `json:"elapsed_ms"`
+}
+
+// planRanking keeps the best plans offered to it, best first. Plans ranking equal keep the order in which
+// they were offered, matching the solver, which keeps the first of equal plans.
+type planRanking struct {
+	size  int
+	under bool
+	plans []PackFulfillmentResult
+}
+
+func (r *planRanking) offer(items, packCount int, packs map[int]int) {
+	i := len(r.plans)
+	for i > 0 && r.better(items, packCount, r.plans[i-1]) {
+		i--
+	}
+	if i >= r.size {
+		return
+	}
+	r.plans = slices.Insert(r.plans, i, PackFulfillmentResult{TotalItems: items, Packs: maps.Clone(packs)})
+	if len(r.plans) > r.size {
+		r.plans = r.plans[:r.size]
+	}
+}
+
+func (r *planRanking) better(items, packCount int, than PackFulfillmentResult) bool {
+	if items != than.TotalItems {
+		return (items < than.TotalItems) != r.under
+	}
+	return packCount < than.PackCount()
+}
+
+// explainResult builds the explanation of result, found by sv for quantity.
+func explainResult(quantity int, packSizes []int, result PackFulfillmentResult, sv *solver, elapsed time.Duration) *Explanation {
+	e := &Explanation{
+		Overage:   max(result.TotalItems-quantity, 0),
+		PackCount: result.PackCount(),
+		RunnersUp: []RejectedPlan{},
+		GCD:       gcdOf(packSizes),
+		Solver:    SolverStats{StatesExplored: sv.states, ElapsedMs: float64(elapsed.Microseconds()) / 1000},
+	}
+	if e.GCD == 1 {
+		if n, ok := frobenius(packSizes); ok && n > 0 {
+			e.LargestUnreachable = &n
+		}
+	}
+	for _, plan := range sv.ranking.plans {
+		if maps.Equal(plan.Packs, result.Packs) {
+			continue
+		}
+		e.RunnersUp = append(e.RunnersUp, RejectedPlan{
+			TotalItems: plan.TotalItems,
+			Packs:      plan.Packs,
+			PackCount:  plan.PackCount(),
+			Reason:     rejectionReason(plan, result),
+		})
+	}
+	return e
+}
+
+// rejectionReason explains why plan lost to chosen.
+func rejectionReason(plan, chosen PackFulfillmentResult) string {
+	switch d := plan.TotalItems - chosen.TotalItems; {
+	case d > 0:
+		return fmt.Sprintf("ships %s more than the chosen plan", plural(d, "item"))
+	case d < 0:
+		return fmt.Sprintf("ships %s fewer than the chosen plan", plural(-d, "item"))
+	}
+	if plan.PackCount() > chosen.PackCount() {
+		return fmt.Sprintf("ships the same items in %s instead of %d", plural(plan.PackCount(), "pack"), chosen.PackCount())
+	}
+	return "equivalent to the chosen plan, which was found first"
+}
+
+// violationReason explains which of opts' limits plan, found without them, exceeds.
+func violationReason(opts FulfillmentOptions, quantity int, plan PackFulfillmentResult) string {
+	var reasons []string
+	overage := plan.TotalItems - quantity
+	if opts.Mode == ModeExactOnly && overage > 0 {
+		reasons = append(reasons, fmt.Sprintf("ships %s more than %s allows", plural(overage, "item"), ModeExactOnly))
+	}
+	if opts.MaxOverage != nil && overage > *opts.MaxOverage {
+		reasons = append(reasons, fmt.Sprintf("overage of %d exceeds %s of %d", overage, ConstraintMaxOverage, *opts.MaxOverage))
+	}
+	if opts.MaxOveragePct != nil && overage > maxOverageForPct(quantity, *opts.MaxOveragePct) {
+		reasons = append(reasons, fmt.Sprintf("overage of %d exceeds %s of %g%%", overage, ConstraintMaxOveragePct, *opts.MaxOveragePct))
+	}
+	if opts.MaxPacks != nil && plan.PackCount() > *opts.MaxPacks {
+		reasons = append(reasons, fmt.Sprintf("%s exceed %s of %d", plural(plan.PackCount(), "pack"), ConstraintMaxPacks, *opts.MaxPacks))
+	}
+	return strings.Join(reasons, "; ")
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func gcdOf(sizes []int) int {
+	g := 0
+	for _, size := range sizes {
+		a, b := g, size
+		for b != 0 {
+			a, b = b, a%b
+		}
+		g = a
+	}
+	return g
+}
+
+// frobenius returns the largest quantity that no combination of sizes reaches exactly, or -1 if every
+// quantity is reachable. The sizes' GCD must be 1. It uses the round robin algorithm of Böcker and Lipták,
+// which finds the smallest reachable quantity in every residue class modulo the smallest size; ok is false
+// when that size exceeds maxFrobeniusModulus.
+func frobenius(sizes []int) (n int, ok bool) {
+	sorted := slices.Clone(sizes)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+	a0 := sorted[0]
+	if a0 > maxFrobeniusModulus {
+		return 0, false
+	}
+	reach := make([]int, a0)
+	for r := 1; r < a0; r++ {
+		reach[r] = math.MaxInt
+	}
+	for _, a := range sorted[1:] {
+		d := gcdOf([]int{a0, a})
+		for p := 0; p < d; p++ {
+			m := math.MaxInt
+			for q := p; q < a0; q += d {
+				m = min(m, reach[q])
+			}
+			if m == math.MaxInt {
+				continue
+			}
+			for range a0/d - 1 {
+				m += a
+				r := m % a0
+				m = min(m, reach[r])
+				reach[r] = m
+			}
+		}
+	}
+	return slices.Max(reach) - a0, true
+}
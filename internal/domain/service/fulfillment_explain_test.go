@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestFrobenius(t *testing.T) {
+	tests := []struct {
+		sizes []int
+		want  int
+	}{
+		{sizes: []int{3, 5}, want: 7},
+		{sizes: []int{6, 9, 20}, want: 43},
+		{sizes: []int{23, 31, 53}, want: 326},
+		{sizes: []int{1, 250}, want: -1},
+		{sizes: []int{5, 3, 5}, want: 7},
+	}
+	for _, tt := range tests {
+		got, ok := frobenius(tt.sizes)
+		if !ok || got != tt.want {
+			t.Errorf("frobenius(%v) = %d, %v; want %d", tt.sizes, got, ok, tt.want)
+		}
+	}
+}
+
+func TestPackFulfillmentService_Explain(t *testing.T) {
+	svc := &PackFulfillmentService{Cache: NewFulfillmentCache(10)}
+
+	got, err := svc.FulfillOrder(context.Background(), 251, []int{250, 500, 1000}, FulfillmentOptions{Explain: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := got.Explanation
+	if e == nil {
+		t.Fatal("expected an explanation")
+	}
+	if e.Overage != 249 || e.PackCount != 1 || e.GCD != 250 || e.LargestUnreachable != nil || e.Solver.StatesExplored == 0 {
+		t.Errorf("unexpected explanation %+v", e)
+	}
+	wantRunnersUp := []RejectedPlan{
+		{TotalItems: 500, Packs: map[int]int{250: 2}, PackCount: 2, Reason: "ships the same items in 2 packs instead of 1"},
+		{TotalItems: 1000, Packs: map[int]int{1000: 1}, PackCount: 1, Reason: "ships 500 items more than the chosen plan"},
+	}
+	if !reflect.DeepEqual(e.RunnersUp, wantRunnersUp) {
+		t.Errorf("RunnersUp: got %+v, want %+v", e.RunnersUp, wantRunnersUp)
+	}
+	if stats := svc.Cache.Stats(); stats.Size != 0 || stats.Misses != 0 {
+		t.Errorf("expected explained orders to bypass the cache, got %+v", stats)
+	}
+
+	maxPacks := 3
+	got, err = svc.FulfillOrder(context.Background(), 1001, []int{3, 5, 1000}, FulfillmentOptions{Explain: true, MaxPacks: &maxPacks})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TotalItems != 1003 {
+		t.Errorf("expected 1003 items within max_packs, got %+v", got)
+	}
+	if e := got.Explanation; e.LargestUnreachable == nil || *e.LargestUnreachable != 7 {
+		t.Errorf("expected the largest unreachable quantity to be 7, got %+v", e)
+	}
+	if rejected := got.Explanation.RunnersUp[0]; rejected.TotalItems != 1001 || rejected.Reason != "201 packs exceed max_packs of 3" {
+		t.Errorf("expected the unconstrained optimum to be rejected for max_packs, got %+v", rejected)
+	}
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Fulfillment table build states.
+const (
+	TableStatusNone     = "none"
+	TableStatusBuilding = "building"
+	TableStatusReady    = "ready"
+	TableStatusFailed   = "failed"
+)
+
+// FulfillmentTable is a precomputed dynamic programming table answering fulfillment queries
+// for quantities up to MaxQuantity by backtracking, in O(packs used) per query.
+type FulfillmentTable struct {
+	MaxQuantity int
+	sizes       []int   // pack sizes, descending
+	packCount   []int32 // minimum packs summing exactly to t, -1 if unreachable
+	choice      []int32 // index into sizes of the last pack used to reach t
+	next        []int32 // smallest reachable total >= t, for t <= MaxQuantity
+}
+
+// BuildFulfillmentTable computes the table for packSizes covering quantities up to maxQuantity.
+// Every quantity q <= maxQuantity is fulfilled by a total below q plus the largest pack size,
+// so totals are computed up to that bound.
+func BuildFulfillmentTable(ctx context.Context, packSizes []int, maxQuantity int) (*FulfillmentTable, error) {
+	sizes := slices.Clone(packSizes)
+	slices.Sort(sizes)
+	sizes = slices.Compact(sizes)
+	slices.Reverse(sizes)
+	if len(sizes) == 0 || sizes[len(sizes)-1] <= 0 {
+		return nil, ErrInvalidPackSizes
+	}
+
+	limit := maxQuantity + sizes[0] - 1
+	t := &FulfillmentTable{
+		MaxQuantity: maxQuantity,
+		sizes:       sizes,
+		packCount:   make([]int32, limit+1),
+		choice:      make([]int32, limit+1),
+		next:        make([]int32, maxQuantity+1),
+	}
+	for total := 1; total <= limit; total++ {
+		if total%65536 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		t.packCount[total] = -1
+		for i, size := range sizes {
+			if size > total || t.packCount[total-size] < 0 {
+				continue
+			}
+			if n := t.packCount[total-size] + 1; t.packCount[total] < 0 || n < t.packCount[total] {
+				t.packCount[total] = n
+				t.choice[total] = int32(i)
+			}
+		}
+	}
+
+	nextReachable := int32(-1)
+	for total := limit; total >= 0; total-- {
+		if t.packCount[total] >= 0 {
+			nextReachable = int32(total)
+		}
+		if total <= maxQuantity {
+			t.next[total] = nextReachable
+		}
+	}
+	return t, nil
+}
+
+// Lookup returns the optimal plan for quantity, or false if quantity is outside the table.
+func (t *FulfillmentTable) Lookup(quantity int) (PackFulfillmentResult, bool) {
+	if quantity < 0 || quantity > t.MaxQuantity {
+		return PackFulfillmentResult{}, false
+	}
+	total := int(t.next[quantity])
+	packs := map[int]int{}
+	for rem := total; rem > 0; {
+		size := t.sizes[t.choice[rem]]
+		packs[size]++
+		rem -= size
+	}
+	return PackFulfillmentResult{TotalItems: total, Packs: packs}, true
+}
+
+// TableStatus reports the build state of a product's fulfillment table.
+type TableStatus struct {
+	ProductID       uuid.UUID  `json:"product_id"`
+	Status          string     `json:"status"`
+	PackSizes       []int      `json:"pack_sizes,omitempty"`
+	MaxQuantity     int        `json:"max_quantity"`
+	BuiltAt         *time.Time `json:"built_at,omitempty"`
+	BuildDurationMs int64      `json:"build_duration_ms,omitempty"`
+	Error           string     `json:"error,omitempty"`
+}
+
+type productTable struct {
+	generation int
+	packSet    string
+	status     TableStatus
+	table      *FulfillmentTable
+}
+
+// FulfillmentTables keeps a precomputed FulfillmentTable per product. Tables are rebuilt
+// asynchronously whenever a product's packs are replaced, and dropped when the product is deleted; it
+// implements PackChangeListener and ProductDeleteListener.
+type FulfillmentTables struct {
+	MaxQuantity int
+
+	mu       sync.RWMutex
+	products map[uuid.UUID]*productTable
+	wg       sync.WaitGroup
+}
+
+// NewFulfillmentTables creates an empty table store covering quantities up to maxQuantity.
+func NewFulfillmentTables(maxQuantity int) *FulfillmentTables {
+	return &FulfillmentTables{
+		MaxQuantity: maxQuantity,
+		products:    make(map[uuid.UUID]*productTable),
+	}
+}
+
+// Rebuild starts building the table for productID over packSizes in the background,
+// superseding any build in progress. An empty pack set removes the product's table.
+func (f *FulfillmentTables) Rebuild(productID uuid.UUID, packSizes []int) {
+	f.mu.Lock()
+	if len(packSizes) == 0 {
+		delete(f.products, productID)
+		f.mu.Unlock()
+		return
+	}
+	pt, ok := f.products[productID]
+	if !ok {
+		pt = &productTable{}
+		f.products[productID] = pt
+	}
+	pt.generation++
+	generation := pt.generation
+	pt.packSet = packSetKey(packSizes)
+	pt.table = nil
+	pt.status = TableStatus{
+		ProductID:   productID,
+		Status:      TableStatusBuilding,
+		PackSizes:   slices.Clone(packSizes),
+		MaxQuantity: f.MaxQuantity,
+	}
+	f.mu.Unlock()
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		start := time.Now()
+		table, err := BuildFulfillmentTable(context.Background(), packSizes, f.MaxQuantity)
+		elapsed := time.Since(start)
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		pt, ok := f.products[productID]
+		if !ok || pt.generation != generation {
+			return // superseded by a newer pack set
+		}
+		builtAt := time.Now()
+		pt.status.BuiltAt = &builtAt
+		pt.status.BuildDurationMs = elapsed.Milliseconds()
+		if err != nil {
+			pt.status.Status = TableStatusFailed
+			pt.status.Error = err.Error()
+			slog.Error("Failed to build fulfillment table", "product_id", productID, "error", err)
+			return
+		}
+		pt.table = table
+		pt.status.Status = TableStatusReady
+		slog.Info("Fulfillment table built", "product_id", productID, "max_quantity", f.MaxQuantity, "duration", elapsed)
+	}()
+}
+
+// Wait blocks until all builds started so far have finished.
+func (f *FulfillmentTables) Wait() {
+	f.wg.Wait()
+}
+
+// PacksReplaced implements PackChangeListener.
+func (f *FulfillmentTables) PacksReplaced(productID uuid.UUID, _, newSizes []int) {
+	f.Rebuild(productID, newSizes)
+}
+
+// ProductDeleted implements ProductDeleteListener, dropping the product's table and any build in progress.
+func (f *FulfillmentTables) ProductDeleted(productID uuid.UUID) {
+	f.Rebuild(productID, nil)
+}
+
+// Lookup answers quantity from productID's table, provided it is ready, was built for packSizes
+// and covers quantity.
+func (f *FulfillmentTables) Lookup(productID uuid.UUID, packSizes []int, quantity int) (PackFulfillmentResult, bool) {
+	f.mu.RLock()
+	pt, ok := f.products[productID]
+	if !ok || pt.table == nil || pt.packSet != packSetKey(packSizes) {
+		f.mu.RUnlock()
+		return PackFulfillmentResult{}, false
+	}
+	table := pt.table
+	f.mu.RUnlock()
+	return table.Lookup(quantity)
+}
+
+// Status returns the build state of productID's table.
+func (f *FulfillmentTables) Status(productID uuid.UUID) TableStatus {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	pt, ok := f.products[productID]
+	if !ok {
+		return TableStatus{ProductID: productID, Status: TableStatusNone, MaxQuantity: f.MaxQuantity}
+	}
+	status := pt.status
+	status.PackSizes = slices.Clone(status.PackSizes)
+	return status
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+)
+
+func TestFulfillmentTable_MatchesSolver(t *testing.T) {
+	packSets := [][]int{
+		{250, 500, 1000, 2000, 5000},
+		{23, 31, 53},
+		{6, 10},
+	}
+	svc := &PackFulfillmentService{}
+	for _, sizes := range packSets {
+		table, err := BuildFulfillmentTable(context.Background(), sizes, 600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for q := 0; q <= 600; q++ {
+			got, ok := table.Lookup(q)
+			if !ok {
+				t.Fatalf("%v: expected quantity %d to be covered", sizes, q)
+			}
+			want, err := svc.FulfillOrder(context.Background(), q, append([]int(nil), sizes...), FulfillmentOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.TotalItems != want.TotalItems || got.PackCount() != want.PackCount() {
+				t.Fatalf("%v, quantity %d: got %d items in %d packs, want %d items in %d packs",
+					sizes, q, got.TotalItems, got.PackCount(), want.TotalItems, want.PackCount())
+			}
+		}
+		if _, ok := table.Lookup(601); ok {
+			t.Errorf("%v: expected quantity above the bound to miss", sizes)
+		}
+	}
+}
+
+func TestFulfillmentTable_InvalidPackSizes(t *testing.T) {
+	if _, err := BuildFulfillmentTable(context.Background(), []int{0, 250}, 100); err != ErrInvalidPackSizes {
+		t.Errorf("expected ErrInvalidPackSizes, got %v", err)
+	}
+}
+
+func TestFulfillmentTables_RebuildOnPackChange(t *testing.T) {
+	productID := uuid.New()
+	tables := NewFulfillmentTables(10000)
+	svc := &PackFulfillmentService{Tables: tables}
+
+	if status := tables.Status(productID); status.Status != TableStatusNone {
+		t.Fatalf("expected status %q, got %q", TableStatusNone, status.Status)
+	}
+
+	tables.PacksReplaced(productID, nil, []int{250, 500})
+	tables.Wait()
+	if status := tables.Status(productID); status.Status != TableStatusReady {
+		t.Fatalf("expected status %q, got %q", TableStatusReady, status.Status)
+	}
+	if _, ok := tables.Lookup(productID, []int{500, 250}, 251); !ok {
+		t.Error("expected lookup to be served from the table")
+	}
+	if _, ok := tables.Lookup(productID, []int{300}, 251); ok {
+		t.Error("expected lookup with a different pack set to miss")
+	}
+	if got, _ := svc.FulfillProductOrder(context.Background(), productID, 251, []int{250, 500}, FulfillmentOptions{}); got.TotalItems != 500 {
+		t.Errorf("expected 500 items, got %d", got.TotalItems)
+	}
+	// Above the bound the live solver is used
+	if got, _ := svc.FulfillProductOrder(context.Background(), productID, 10001, []int{250, 500}, FulfillmentOptions{}); got.TotalItems != 10250 {
+		t.Errorf("expected 10250 items, got %d", got.TotalItems)
+	}
+}
+
+func TestFulfillmentTables_DropOnProductDelete(t *testing.T) {
+	ctx := context.Background()
+	tables := NewFulfillmentTables(10000)
+	products := &ProductService{Repo: out.NewProductRepositoryMem(), Listeners: []ProductDeleteListener{tables}}
+	p := &model.Product{Name: "Shoes"}
+	if err := products.Create(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	tables.PacksReplaced(p.ID, nil, []int{250, 500})
+	tables.Wait()
+
+	if err := products.Delete(ctx, p.ID); err != nil {
+		t.Fatal(err)
+	}
+	if status := tables.Status(p.ID); status.Status != TableStatusNone {
+		t.Errorf("expected the deleted product's table to be dropped, got status %q", status.Status)
+	}
+	if _, ok := tables.Lookup(p.ID, []int{250, 500}, 251); ok {
+		t.Error("expected lookup to miss after the product was deleted")
+	}
+}
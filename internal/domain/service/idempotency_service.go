@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+// ErrIdempotencyKeyReused is returned when an idempotency key is reused for a different request.
+var ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+
+// ErrIdempotencyKeyInProgress is returned when a request is retried while the original is still processed.
+var ErrIdempotencyKeyInProgress = errors.New("request with idempotency key is still in progress")
+
+// IdempotencyService remembers the responses of requests made with idempotency keys for Window, so that
+// retries replay the original response instead of repeating the request.
+type IdempotencyService struct {
+	Repo   port.IdempotencyRepository
+	Window time.Duration
+
+	now func() time.Time // replaced in tests
+}
+
+// Begin claims key for the request identified by fingerprint. It returns the stored record if the request
+// was already completed, in which case its response is to be replayed, and nil if the request is to be
+// processed and then passed to Complete or Release. Retries of a request still being processed fail with
+// ErrIdempotencyKeyInProgress, and requests reusing a key with another fingerprint with
+// ErrIdempotencyKeyReused.
+func (s *IdempotencyService) Begin(ctx context.Context, key, fingerprint string) (_ *model.IdempotencyRecord, err error) {
+	ctx, span := tracer.Start(ctx, "IdempotencyService.Begin")
+	defer func() { endSpan(span, err) }()
+	now := s.clock()
+	existing, err := s.Repo.Reserve(ctx, &model.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.Window),
+	})
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.Bool("idempotency.replayed", existing != nil))
+	switch {
+	case existing == nil:
+		return nil, nil
+	case existing.Fingerprint != fingerprint:
+		return nil, ErrIdempotencyKeyReused
+	case !existing.Completed():
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	return existing, nil
+}
+
+// Complete stores the response of the request that claimed key, to be replayed to its retries.
+func (s *IdempotencyService) Complete(ctx context.Context, key string, statusCode int, header map[string][]string, body []byte) (err error) {
+	ctx, span := tracer.Start(ctx, "IdempotencyService.Complete")
+	span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+	defer func() { endSpan(span, err) }()
+	return s.Repo.Complete(ctx, &model.IdempotencyRecord{Key: key, StatusCode: statusCode, Header: header, Body: body})
+}
+
+// Release gives up key without storing a response, so that a retry processes the request again.
+func (s *IdempotencyService) Release(ctx context.Context, key string) (err error) {
+	ctx, span := tracer.Start(ctx, "IdempotencyService.Release")
+	defer func() { endSpan(span, err) }()
+	return s.Repo.Release(ctx, key)
+}
+
+// PurgeExpired deletes the records whose window has passed and returns how many it deleted.
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) (_ int64, err error) {
+	ctx, span := tracer.Start(ctx, "IdempotencyService.PurgeExpired")
+	defer func() { endSpan(span, err) }()
+	return s.Repo.DeleteExpired(ctx, s.clock())
+}
+
+func (s *IdempotencyService) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
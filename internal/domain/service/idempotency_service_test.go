@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+)
+
+func TestIdempotencyService_Window(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	svc := &IdempotencyService{Repo: out.NewIdempotencyRepositoryMem(), Window: time.Hour, now: func() time.Time { return now }}
+
+	if record, err := svc.Begin(ctx, "k1", "a"); record != nil || err != nil {
+		t.Fatalf("expected a new key to be claimed, got %+v, %v", record, err)
+	}
+	if _, err := svc.Begin(ctx, "k1", "a"); !errors.Is(err, ErrIdempotencyKeyInProgress) {
+		t.Errorf("expected ErrIdempotencyKeyInProgress before completion, got %v", err)
+	}
+	if err := svc.Complete(ctx, "k1", 201, nil, []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if record, err := svc.Begin(ctx, "k1", "a"); err != nil || record == nil || record.StatusCode != 201 {
+		t.Errorf("expected the stored response, got %+v, %v", record, err)
+	}
+	if _, err := svc.Begin(ctx, "k1", "b"); !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Errorf("expected ErrIdempotencyKeyReused for another fingerprint, got %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	if record, err := svc.Begin(ctx, "k1", "b"); record != nil || err != nil {
+		t.Errorf("expected an expired key to be claimed again, got %+v, %v", record, err)
+	}
+	now = now.Add(time.Hour)
+	if n, err := svc.PurgeExpired(ctx); n != 1 || err != nil {
+		t.Errorf("expected 1 purged record, got %d, %v", n, err)
+	}
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+)
+
+// maxOrderLines bounds the lines of an order, each of which runs the solver.
+const maxOrderLines = 100
+
+// ShipmentLimits bound the cartons sent together as one shipment; a zero limit is no limit.
+type ShipmentLimits struct {
+	MaxWeightG   int   // Weight of the packs of a shipment
+	MaxVolumeCM3 int64 // Inner volume of the cartons of a shipment
+}
+
+// OrderLine is a quantity of a product ordered.
+type OrderLine struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Quantity  int       `json:"quantity" example:"751"`
+}
+
+// OrderLineError is an error planning a line of an order.
+type OrderLineError struct {
+	Line      int // 1-based
+	ProductID uuid.UUID
+	Err       error
+}
+
+func (e *OrderLineError) Error() string {
+	return fmt.Sprintf("order line %d: %v", e.Line, e.Err)
+}
+
+func (e *OrderLineError) Unwrap() error { return e.Err }
+
+// OrderPlan is the packs fulfilling each line of an order and the shipments to send them in.
+type OrderPlan struct {
+	Lines         []OrderLinePlan   `json:"lines"`
+	PackCount     int               `json:"pack_count" example:"4"`
+	CartonCount   int               `json:"carton_count" example:"2"`
+	ShipmentCount int               `json:"shipment_count" example:"1"`
+	TotalWeightG  int               `json:"total_weight_g" example:"9400"`
+	Shipments     []PlannedShipment `json:"shipments"`
+}
+
+// OrderLinePlan is the packs fulfilling a line of an order.
+type OrderLinePlan struct {
+	ProductID         uuid.UUID     `json:"product_id"`
+	RequestedQuantity int           `json:"requested_quantity" example:"751"`
+	TotalItems        int           `json:"total_items" example:"1000"`
+	Overage           int           `json:"overage" example:"249"`
+	Shortfall         int           `json:"shortfall" example:"0"`
+	PackCount         int           `json:"pack_count" example:"2"`
+	Packs             []ShippedPack `json:"packs"`
+}
+
+// PlannedShipment is cartons sent together, with the items of each product they hold.
+type PlannedShipment struct {
+	CartonCount int              `json:"carton_count" example:"2"`
+	PackCount   int              `json:"pack_count" example:"4"`
+	WeightG     int              `json:"weight_g" example:"9400"`    // of the packs
+	VolumeCM3   int64            `json:"volume_cm3" example:"54000"` // inner volume of the cartons, rounded up
+	Products    []ShippedProduct `json:"products"`
+	Cartons     []PlannedCarton  `json:"cartons"`
+}
+
+// ShippedProduct is how many items of a product a shipment holds.
+type ShippedProduct struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Items     int       `json:"items" example:"1000"`
+}
+
+// PlanOrder fulfills every line of an order like Plan, restricted to the pack sizes the customer accepts
+// unless customerID is uuid.Nil, puts the packs of all lines together in the fewest cartons, and groups the
+// cartons into as few shipments within s.Limits as it finds. A carton is only used if it fits a shipment
+// alone, and is filled to no more than the shipment weight limit.
+//
+// Each product may appear in one line only. Errors planning a line are returned as an *OrderLineError.
+func (s *ShipmentService) PlanOrder(ctx context.Context, lines []OrderLine, customerID uuid.UUID, opts FulfillmentOptions) (_ *OrderPlan, err error) {
+	ctx, span := tracer.Start(ctx, "ShipmentService.PlanOrder")
+	span.SetAttributes(attribute.Int("order.lines", len(lines)))
+	defer func() { endSpan(span, err) }()
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: the order has no lines", ErrInvalidOptions)
+	}
+	if len(lines) > maxOrderLines {
+		return nil, fmt.Errorf("%w: %d lines exceed the limit of %d per order", ErrInvalidOptions, len(lines), maxOrderLines)
+	}
+	customer, err := s.Fulfillment.customer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &OrderPlan{Lines: make([]OrderLinePlan, 0, len(lines))}
+	var shipped []*model.Pack
+	for i, line := range lines {
+		lineErr := func(err error) error { return &OrderLineError{Line: i + 1, ProductID: line.ProductID, Err: err} }
+		if slices.ContainsFunc(lines[:i], func(l OrderLine) bool { return l.ProductID == line.ProductID }) {
+			return nil, lineErr(fmt.Errorf("%w: product %s is in an earlier line", ErrInvalidOptions, line.ProductID))
+		}
+		result, packs, err := s.fulfillLine(ctx, line.ProductID, line.Quantity, customer, opts)
+		if err != nil {
+			return nil, lineErr(err)
+		}
+		if n := len(shipped) + len(packs); n > maxShipmentPacks {
+			return nil, lineErr(fmt.Errorf("%w: %d packs exceed the limit of %d per order", ErrUnshippable, n, maxShipmentPacks))
+		}
+		shipped = append(shipped, packs...)
+		plan.Lines = append(plan.Lines, OrderLinePlan{
+			ProductID:         line.ProductID,
+			RequestedQuantity: line.Quantity,
+			TotalItems:        result.TotalItems,
+			Overage:           max(result.TotalItems-line.Quantity, 0),
+			Shortfall:         result.Shortfall,
+			PackCount:         result.PackCount(),
+			Packs:             shippedPacks(packs),
+		})
+		plan.PackCount += result.PackCount()
+	}
+
+	cartons, err := s.Cartons.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	loads, err := packCartons(shipped, s.Limits.cartons(cartons))
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range s.Limits.consolidate(loads) {
+		shipment := PlannedShipment{Cartons: plannedCartons(group)}
+		items := map[uuid.UUID]int{}
+		for _, l := range group {
+			shipment.VolumeCM3 += l.carton.Inner.Volume()
+			for _, pack := range l.packs {
+				items[pack.ProductID] += pack.Size
+			}
+		}
+		shipment.VolumeCM3 = (shipment.VolumeCM3 + 999) / 1000
+		for _, c := range shipment.Cartons {
+			shipment.WeightG += c.WeightG
+			for _, p := range c.Packs {
+				shipment.PackCount += p.Count
+			}
+		}
+		for _, line := range plan.Lines {
+			if n := items[line.ProductID]; n > 0 {
+				shipment.Products = append(shipment.Products, ShippedProduct{ProductID: line.ProductID, Items: n})
+			}
+		}
+		shipment.CartonCount = len(shipment.Cartons)
+		plan.Shipments = append(plan.Shipments, shipment)
+		plan.CartonCount += shipment.CartonCount
+		plan.TotalWeightG += shipment.WeightG
+	}
+	plan.ShipmentCount = len(plan.Shipments)
+	span.SetAttributes(attribute.Int("order.shipments", plan.ShipmentCount), attribute.Int("shipment.cartons", plan.CartonCount))
+	return plan, nil
+}
+
+// shippedPacks counts packs by pack, largest first and then by product.
+func shippedPacks(packs []*model.Pack) []ShippedPack {
+	shipped := []ShippedPack{}
+	for _, pack := range packs {
+		i := slices.IndexFunc(shipped, func(p ShippedPack) bool { return p.PackID == pack.ID })
+		if i < 0 {
+			shipped = append(shipped, ShippedPack{ProductID: pack.ProductID, PackID: pack.ID, Size: pack.Size})
+			i = len(shipped) - 1
+		}
+		shipped[i].Count++
+	}
+	slices.SortFunc(shipped, func(a, b ShippedPack) int {
+		return cmp.Or(cmp.Compare(b.Size, a.Size), cmp.Compare(a.ProductID.String(), b.ProductID.String()))
+	})
+	return shipped
+}
+
+// cartons returns the cartons that fit a shipment alone, with their maximum weight lowered to the
+// shipment's where that is lower.
+func (lim ShipmentLimits) cartons(cartons []*model.Carton) []*model.Carton {
+	fitting := make([]*model.Carton, 0, len(cartons))
+	for _, c := range cartons {
+		if lim.MaxVolumeCM3 > 0 && c.Inner.Volume() > lim.MaxVolumeCM3*1000 {
+			continue
+		}
+		if lim.MaxWeightG > 0 && c.MaxWeightG > lim.MaxWeightG {
+			capped := *c
+			capped.MaxWeightG = lim.MaxWeightG
+			c = &capped
+		}
+		fitting = append(fitting, c)
+	}
+	return fitting
+}
+
+// consolidate groups loads, each of which fits a shipment alone, into shipments. Like packCartons it is
+// first-fit decreasing, heaviest load first.
+func (lim ShipmentLimits) consolidate(loads []*cartonLoad) [][]*cartonLoad {
+	loads = slices.SortedStableFunc(slices.Values(loads), func(a, b *cartonLoad) int {
+		return cmp.Or(cmp.Compare(b.weightG, a.weightG), cmp.Compare(b.carton.Inner.Volume(), a.carton.Inner.Volume()))
+	})
+	type shipment struct {
+		loads   []*cartonLoad
+		weightG int
+		volume  int64
+	}
+	var shipments []*shipment
+	for _, l := range loads {
+		i := slices.IndexFunc(shipments, func(s *shipment) bool {
+			return (lim.MaxWeightG <= 0 || s.weightG+l.weightG <= lim.MaxWeightG) &&
+				(lim.MaxVolumeCM3 <= 0 || s.volume+l.carton.Inner.Volume() <= lim.MaxVolumeCM3*1000)
+		})
+		if i < 0 {
+			shipments = append(shipments, &shipment{})
+			i = len(shipments) - 1
+		}
+		s := shipments[i]
+		s.loads = append(s.loads, l)
+		s.weightG += l.weightG
+		s.volume += l.carton.Inner.Volume()
+	}
+	groups := make([][]*cartonLoad, len(shipments))
+	for i, s := range shipments {
+		groups[i] = s.loads
+	}
+	return groups
+}
@@ -1,16 +1,236 @@
 package service
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+// ErrInvalidPackSizes is returned when a pack set is empty or contains non-positive sizes.
+var ErrInvalidPackSizes = errors.New("pack sizes must be positive")
+
+// ErrSolverBudgetExceeded is returned when the solver does not finish within its time budget.
+var ErrSolverBudgetExceeded = errors.New("fulfillment solver exceeded its time budget")
+
+// ErrSolverBusy is returned when the solver limiter admits no further fulfillment.
+var ErrSolverBusy = errors.New("too many concurrent fulfillments")
+
+// ErrNoAcceptedPackSizes is returned when a customer accepts none of a product's pack sizes.
+var ErrNoAcceptedPackSizes = errors.New("customer accepts none of the product's pack sizes")
+
+// SolverLimiter bounds concurrent fulfillment computations, like ratelimit.ConcurrencyLimiter.
+type SolverLimiter interface {
+	TryAcquire() bool
+	Release()
+}
+
 // PackFulfillmentResult holds the result of pack fulfillment.
 type PackFulfillmentResult struct {
 	TotalItems int
 	Packs      map[int]int // pack size -> count
+	Shortfall  int         `json:",omitempty"` // items short of the quantity, in under mode
+
+	Explanation *Explanation `json:",omitempty"` // set when FulfillmentOptions.Explain is requested
+}
+
+// PackCount returns the total number of packs in the result.
+func (r PackFulfillmentResult) PackCount() int {
+	n := 0
+	for _, count := range r.Packs {
+		n += count
+	}
+	return n
+}
+
+// Fulfillment strategies that can be switched off at runtime. The solver itself is always available.
+const (
+	StrategyCache = "cache"
+	StrategyTable = "table"
+)
+
+// FulfillmentSettings are the runtime-adjustable knobs of a PackFulfillmentService.
+type FulfillmentSettings struct {
+	MaxQuantity   int             // Largest quantity FulfillForCustomer accepts; 0 means unlimited
+	SolverTimeout time.Duration   // Time budget per solve; 0 means unlimited
+	Strategies    map[string]bool // Enabled strategies; nil enables all
+}
+
+func (s FulfillmentSettings) enabled(strategy string) bool {
+	return s.Strategies == nil || s.Strategies[strategy]
 }
 
 // PackFulfillmentService provides pack fulfillment logic.
-type PackFulfillmentService struct{}
+type PackFulfillmentService struct {
+	Packs     *PackService            // required by FulfillForCustomer
+	Customers *CustomerService        // optional; without it, FulfillForCustomer rejects customers
+	Solvers   SolverLimiter           // optional bound on concurrent FulfillForCustomer calls
+	Metrics   port.FulfillmentMetrics // optional
+	Cache     *FulfillmentCache       // optional
+	Tables    *FulfillmentTables      // optional
+	Events    *EventRecorder          // optional
+
+	settings atomic.Pointer[FulfillmentSettings]
+}
+
+// Configure replaces the service's settings. It is safe to call while orders are being fulfilled.
+func (s *PackFulfillmentService) Configure(settings FulfillmentSettings) {
+	s.settings.Store(&settings)
+}
+
+// Settings returns the service's current settings.
+func (s *PackFulfillmentService) Settings() FulfillmentSettings {
+	if settings := s.settings.Load(); settings != nil {
+		return *settings
+	}
+	return FulfillmentSettings{}
+}
+
+// FulfillForCustomer fulfills an order for quantity items of a product with its packs, restricted to the
+// pack sizes the customer accepts unless customerID is uuid.Nil, records the result's metrics and returns
+// the result with the product's packs. It fails with ErrInvalidOptions unless quantity is positive and within
+// the configured MaxQuantity, port.ErrProductNotFound if the product has no packs,
+// ErrNoAcceptedPackSizes if the customer accepts none of them, ErrSolverBusy if s.Solvers admits no
+// further fulfillment, and otherwise like FulfillProductOrder.
+func (s *PackFulfillmentService) FulfillForCustomer(ctx context.Context, productID uuid.UUID, quantity int, customerID uuid.UUID, opts FulfillmentOptions) (_ PackFulfillmentResult, _ []*model.Pack, err error) {
+	ctx, span := tracer.Start(ctx, "PackFulfillmentService.FulfillForCustomer")
+	defer func() { endSpan(span, err) }()
+	customer, err := s.customer(ctx, customerID)
+	if err != nil {
+		return PackFulfillmentResult{}, nil, err
+	}
+	return s.fulfillFor(ctx, productID, quantity, customer, opts)
+}
+
+// customer returns the customer with the given ID, or nil if id is uuid.Nil.
+func (s *PackFulfillmentService) customer(ctx context.Context, id uuid.UUID) (*model.Customer, error) {
+	if id == uuid.Nil {
+		return nil, nil
+	}
+	if s.Customers == nil {
+		return nil, port.ErrCustomerNotFound
+	}
+	return s.Customers.GetByID(ctx, id)
+}
+
+// fulfillFor is FulfillForCustomer for a customer already loaded, or nil for none.
+func (s *PackFulfillmentService) fulfillFor(ctx context.Context, productID uuid.UUID, quantity int, customer *model.Customer, opts FulfillmentOptions) (PackFulfillmentResult, []*model.Pack, error) {
+	if quantity <= 0 {
+		return PackFulfillmentResult{}, nil, fmt.Errorf("%w: quantity must be positive", ErrInvalidOptions)
+	}
+	if limit := s.Settings().MaxQuantity; limit > 0 && quantity > limit {
+		return PackFulfillmentResult{}, nil, fmt.Errorf("%w: quantity %d exceeds the limit of %d", ErrInvalidOptions, quantity, limit)
+	}
+	packs, err := s.Packs.ListByProduct(ctx, productID)
+	if err != nil {
+		return PackFulfillmentResult{}, nil, err
+	}
+	if len(packs) == 0 {
+		return PackFulfillmentResult{}, nil, fmt.Errorf("%w: product %s has no packs", port.ErrProductNotFound, productID)
+	}
+	sizes := make([]int, 0, len(packs))
+	for _, p := range packs {
+		sizes = append(sizes, p.Size)
+	}
+	if customer != nil {
+		if sizes = customer.PackSizesFor(productID, sizes); len(sizes) == 0 {
+			return PackFulfillmentResult{}, nil, fmt.Errorf("%w: customer %s, product %s", ErrNoAcceptedPackSizes, customer.ID, productID)
+		}
+	}
+	if s.Solvers != nil {
+		if !s.Solvers.TryAcquire() {
+			return PackFulfillmentResult{}, nil, ErrSolverBusy
+		}
+		defer s.Solvers.Release()
+	}
+	result, err := s.FulfillProductOrder(ctx, productID, quantity, sizes, opts)
+	if err != nil {
+		return PackFulfillmentResult{}, nil, err
+	}
+	s.RecordResult(productID, quantity, result)
+	return result, packs, nil
+}
+
+// FulfillProductOrder returns the optimal pack distribution for a product's order. It answers from the
+// product's precomputed table when one is ready and covers quantity, and falls back to FulfillOrder otherwise.
+func (s *PackFulfillmentService) FulfillProductOrder(ctx context.Context, productID uuid.UUID, quantity int, packSizes []int, opts FulfillmentOptions) (result PackFulfillmentResult, err error) {
+	ctx, span := tracer.Start(ctx, "PackFulfillmentService.FulfillProductOrder")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes(attribute.String("product.id", productID.String()), attribute.Int("fulfillment.quantity", quantity))
+	defer func() {
+		if err == nil {
+			s.Events.raise(ctx, model.EventFulfillmentComputed, productID, model.FulfillmentComputedPayload{
+				Quantity:   quantity,
+				TotalItems: result.TotalItems,
+				Shortfall:  result.Shortfall,
+				Packs:      result.Packs,
+			})
+		}
+	}()
+	if s.Tables != nil && s.Settings().enabled(StrategyTable) && opts.Mode != ModeUnder && !opts.Explain {
+		if result, ok := s.Tables.Lookup(productID, packSizes, quantity); ok {
+			span.SetAttributes(attribute.Bool("fulfillment.table_hit", true))
+			return s.constrain(ctx, quantity, packSizes, opts, result)
+		}
+	}
+	return s.FulfillOrder(ctx, quantity, packSizes, opts)
+}
+
+// FulfillOrder returns the optimal pack distribution for a given quantity and available pack sizes that
+// satisfies opts. It returns ErrInvalidPackSizes if a size is not positive, an *InfeasibleError if no
+// distribution satisfies opts, ErrSolverBudgetExceeded if the search outlasts the configured solver timeout,
+// and the context's error if ctx is done first.
+func (s *PackFulfillmentService) FulfillOrder(ctx context.Context, quantity int, packSizes []int, opts FulfillmentOptions) (result PackFulfillmentResult, err error) {
+	ctx, span := tracer.Start(ctx, "PackFulfillmentService.FulfillOrder")
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes(attribute.Int("fulfillment.quantity", quantity), attribute.IntSlice("pack.sizes", packSizes))
+	span.SetAttributes(constraintAttributes(opts)...)
+	if err := validatePackSizes(packSizes); err != nil {
+		return PackFulfillmentResult{}, err
+	}
+	if opts.Mode == ModeUnder {
+		return s.fulfillUnder(ctx, quantity, packSizes, opts)
+	}
+	// an explanation needs the solver's statistics, so it always runs the solver
+	useCache := s.Cache != nil && s.Settings().enabled(StrategyCache) && !opts.Explain
+	if useCache {
+		if result, ok := s.Cache.Get(packSizes, quantity); ok {
+			span.SetAttributes(attribute.Bool("fulfillment.cache_hit", true))
+			return s.constrain(ctx, quantity, packSizes, opts, result)
+		}
+	}
+	result, err = s.search(ctx, quantity, packSizes, nil, opts.Explain)
+	if err != nil {
+		return PackFulfillmentResult{}, err
+	}
+	if useCache {
+		s.Cache.Add(packSizes, quantity, result)
+	}
+	return s.constrain(ctx, quantity, packSizes, opts, result)
+}
+
+// search runs the solver within the configured time budget. A nil limits searches without constraints;
+// otherwise a TotalItems of -1 means no distribution is within limits. In under mode the search looks for
+// the largest total not exceeding quantity instead of the smallest total covering it. With explain, the
+// result carries an Explanation.
+func (s *PackFulfillmentService) search(ctx context.Context, quantity int, packSizes []int, limits *solveLimits, explain bool) (PackFulfillmentResult, error) {
+	span := trace.SpanFromContext(ctx)
+	if timeout := s.Settings().SolverTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, ErrSolverBudgetExceeded)
+		defer cancel()
+	}
+
+	start := time.Now()
 
-// FulfillOrder returns the optimal pack distribution for a given quantity and available pack sizes.
-func (s *PackFulfillmentService) FulfillOrder(quantity int, packSizes []int) PackFulfillmentResult {
 	// Sort packSizes descending
 	for i := 0; i < len(packSizes)-1; i++ {
 		for j := i + 1; j < len(packSizes); j++ {
@@ -20,23 +240,62 @@ func (s *PackFulfillmentService) FulfillOrder(quantity int, packSizes []int) Pac
 		}
 	}
 
-	minItems := -1
-	minPacks := -1
-	best := map[int]int{}
+	sv := &solver{done: ctx.Done(), packSizes: packSizes, limits: limits, minItems: -1, minPacks: -1, best: map[int]int{}}
+	if explain {
+		sv.ranking = &planRanking{size: runnersUpCount + 1, under: limits != nil && limits.under}
+	}
+	sv.dfs(0, quantity, 0, map[int]int{})
+	elapsed := time.Since(start)
+
+	span.SetAttributes(attribute.Int("fulfillment.states_explored", sv.states), attribute.Int("fulfillment.total_items", sv.minItems))
+	if s.Metrics != nil {
+		s.Metrics.ObserveSolve(elapsed, sv.states)
+	}
+
+	if ctx.Err() != nil {
+		return PackFulfillmentResult{}, context.Cause(ctx)
+	}
 
-	s.dfs(packSizes, 0, quantity, map[int]int{}, &minItems, &minPacks, &best)
+	result := PackFulfillmentResult{
+		TotalItems: sv.minItems,
+		Packs:      sv.best,
+	}
+	if explain && result.TotalItems >= 0 {
+		result.Explanation = explainResult(quantity, packSizes, result, sv, elapsed)
+	}
+	return result, nil
+}
 
-	return PackFulfillmentResult{
-		TotalItems: minItems,
-		Packs:      best,
+// RecordResult reports the overage and pack count of a product's fulfillment result.
+func (s *PackFulfillmentService) RecordResult(productID uuid.UUID, quantity int, result PackFulfillmentResult) {
+	if s.Metrics == nil || result.TotalItems < 0 {
+		return
 	}
+	// under mode ships short, which is not an overage
+	s.Metrics.ObserveResult(productID, max(result.TotalItems-quantity, 0), result.PackCount())
 }
 
-func (s *PackFulfillmentService) dfs(packSizes []int, idx, rem int, packs map[int]int, minItems, minPacks *int, best *map[int]int) {
+// solver holds the state of one depth-first search over pack combinations.
+type solver struct {
+	done      <-chan struct{}
+	packSizes []int        // sorted descending
+	limits    *solveLimits // optional
+	ranking   *planRanking // optional; records the best plans found for an explanation
+
+	minItems int
+	minPacks int
+	states   int
+	best     map[int]int
+}
+
+// dfs searches pack combinations, abandoning the search once done is closed. With limits, branches that
+// exceed the pack count or overage limit are pruned.
+func (sv *solver) dfs(idx, rem, count int, packs map[int]int) {
+	sv.states++
 	// all pack sizes have been considered
-	if idx == len(packSizes) {
-		// if we still have remaining items, this is not a valid solution
-		if rem > 0 {
+	if idx == len(sv.packSizes) {
+		// if we still have remaining items, this is not a valid solution unless shipping short
+		if rem > 0 && (sv.limits == nil || !sv.limits.under) {
 			return
 		}
 		items := 0
@@ -45,25 +304,46 @@ func (s *PackFulfillmentService) dfs(packSizes []int, idx, rem int, packs map[in
 			items += size * count
 			packCount += count
 		}
-		if *minItems == -1 || items < *minItems || (items == *minItems && packCount < *minPacks) {
-			*minItems = items
-			*minPacks = packCount
-			*best = make(map[int]int)
+		if sv.ranking != nil {
+			sv.ranking.offer(items, packCount, packs)
+		}
+		better := items < sv.minItems
+		if sv.limits != nil && sv.limits.under {
+			better = items > sv.minItems
+		}
+		if sv.minItems == -1 || better || (items == sv.minItems && packCount < sv.minPacks) {
+			sv.minItems = items
+			sv.minPacks = packCount
+			sv.best = make(map[int]int)
 			for k, v := range packs {
-				(*best)[k] = v
+				sv.best[k] = v
 			}
 		}
 		return
 	}
-	max := (rem + packSizes[idx] - 1) / packSizes[idx]
+	size := sv.packSizes[idx]
+	max := (rem + size - 1) / size
+	// once the order is covered, the remaining sizes are skipped rather than abandoning the branch
+	if max < 0 {
+		max = 0
+	}
 	for i := 0; i <= max; i++ {
+		// adding packs only raises the count and the overage, so no larger i can be within limits either
+		if sv.limits != nil && (count+i > sv.limits.maxPacks || rem-size*i < -sv.limits.maxOverage) {
+			break
+		}
 		newPacks := make(map[int]int)
 		for k, v := range packs {
 			newPacks[k] = v
 		}
 		if i > 0 {
-			newPacks[packSizes[idx]] = i
+			newPacks[size] = i
+		}
+		select {
+		case <-sv.done:
+			return
+		default:
 		}
-		s.dfs(packSizes, idx+1, rem-packSizes[idx]*i, newPacks, minItems, minPacks, best)
+		sv.dfs(idx+1, rem-size*i, count+i, newPacks)
 	}
 }
@@ -1,8 +1,16 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
 )
 
 func TestPackFulfillmentService_FulfillOrder(t *testing.T) {
@@ -41,7 +49,10 @@ func TestPackFulfillmentService_FulfillOrder(t *testing.T) {
 	svc := &PackFulfillmentService{}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := svc.FulfillOrder(tt.quantity, tt.packSizes)
+			got, err := svc.FulfillOrder(context.Background(), tt.quantity, tt.packSizes, FulfillmentOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
 			if got.TotalItems != tt.expect.TotalItems {
 				t.Errorf("TotalItems: got %d, want %d", got.TotalItems, tt.expect.TotalItems)
 			}
@@ -51,3 +62,281 @@ func TestPackFulfillmentService_FulfillOrder(t *testing.T) {
 		})
 	}
 }
+
+func TestPackFulfillmentService_SolverTimeout(t *testing.T) {
+	svc := &PackFulfillmentService{Cache: NewFulfillmentCache(10)}
+	svc.Configure(FulfillmentSettings{SolverTimeout: time.Nanosecond})
+
+	_, err := svc.FulfillOrder(context.Background(), 1_000_000, []int{3, 7, 11, 13, 17}, FulfillmentOptions{})
+	if !errors.Is(err, ErrSolverBudgetExceeded) {
+		t.Fatalf("expected ErrSolverBudgetExceeded, got %v", err)
+	}
+	if _, ok := svc.Cache.Get([]int{3, 7, 11, 13, 17}, 1_000_000); ok {
+		t.Error("expected an abandoned search not to be cached")
+	}
+}
+
+func TestPackFulfillmentService_DisabledStrategies(t *testing.T) {
+	cache := NewFulfillmentCache(10)
+	svc := &PackFulfillmentService{Cache: cache}
+	svc.Configure(FulfillmentSettings{Strategies: map[string]bool{}})
+
+	if _, err := svc.FulfillOrder(context.Background(), 251, []int{250, 500}, FulfillmentOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if stats := cache.Stats(); stats.Size != 0 || stats.Misses != 0 {
+		t.Errorf("expected the disabled cache to be bypassed, got %+v", stats)
+	}
+}
+
+func TestPackFulfillmentService_Constraints(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+	pctPtr := func(f float64) *float64 { return &f }
+	packSizes := []int{250, 500, 1000, 2000, 5000}
+
+	tests := []struct {
+		name            string
+		quantity        int
+		opts            FulfillmentOptions
+		expect          PackFulfillmentResult
+		wantConstraints []string
+	}{
+		{
+			name:     "Optimum within limits",
+			quantity: 251,
+			opts:     FulfillmentOptions{MaxOverage: intPtr(249), MaxPacks: intPtr(1)},
+			expect:   PackFulfillmentResult{TotalItems: 500, Packs: map[int]int{500: 1}},
+		},
+		{
+			name:     "Fewer packs for more items",
+			quantity: 12001,
+			opts:     FulfillmentOptions{MaxPacks: intPtr(3)},
+			expect:   PackFulfillmentResult{TotalItems: 15000, Packs: map[int]int{5000: 3}},
+		},
+		{
+			name:            "Overage too small",
+			quantity:        251,
+			opts:            FulfillmentOptions{MaxOverage: intPtr(100)},
+			wantConstraints: []string{ConstraintMaxOverage},
+		},
+		{
+			name:            "Overage percentage too small",
+			quantity:        251,
+			opts:            FulfillmentOptions{MaxOveragePct: pctPtr(50)},
+			wantConstraints: []string{ConstraintMaxOveragePct},
+		},
+		{
+			name:            "Too few packs",
+			quantity:        12001,
+			opts:            FulfillmentOptions{MaxPacks: intPtr(2)},
+			wantConstraints: []string{ConstraintMaxPacks},
+		},
+		{
+			name:            "Limits conflict",
+			quantity:        12001,
+			opts:            FulfillmentOptions{MaxOverage: intPtr(1000), MaxPacks: intPtr(3)},
+			wantConstraints: []string{ConstraintMaxOverage, ConstraintMaxPacks},
+		},
+	}
+
+	svc := &PackFulfillmentService{Cache: NewFulfillmentCache(10)}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.FulfillOrder(context.Background(), tt.quantity, append([]int(nil), packSizes...), tt.opts)
+			if tt.wantConstraints != nil {
+				var infeasible *InfeasibleError
+				if !errors.As(err, &infeasible) {
+					t.Fatalf("expected an InfeasibleError, got %v", err)
+				}
+				if !reflect.DeepEqual(infeasible.Constraints, tt.wantConstraints) {
+					t.Errorf("Constraints: got %v, want %v", infeasible.Constraints, tt.wantConstraints)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.TotalItems != tt.expect.TotalItems || !reflect.DeepEqual(got.Packs, tt.expect.Packs) {
+				t.Errorf("got %+v, want %+v", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestPackFulfillmentService_Modes(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+	packSizes := []int{250, 500, 1000}
+
+	tests := []struct {
+		name            string
+		quantity        int
+		opts            FulfillmentOptions
+		expect          PackFulfillmentResult
+		wantConstraints []string
+	}{
+		{
+			name:     "Under ships short",
+			quantity: 1499,
+			opts:     FulfillmentOptions{Mode: ModeUnder},
+			expect:   PackFulfillmentResult{TotalItems: 1250, Packs: map[int]int{1000: 1, 250: 1}, Shortfall: 249},
+		},
+		{
+			name:     "Under with exact quantity",
+			quantity: 1500,
+			opts:     FulfillmentOptions{Mode: ModeUnder},
+			expect:   PackFulfillmentResult{TotalItems: 1500, Packs: map[int]int{1000: 1, 500: 1}},
+		},
+		{
+			name:     "Under with pack limit",
+			quantity: 1750,
+			opts:     FulfillmentOptions{Mode: ModeUnder, MaxPacks: intPtr(1)},
+			expect:   PackFulfillmentResult{TotalItems: 1000, Packs: map[int]int{1000: 1}, Shortfall: 750},
+		},
+		{
+			name:     "Under below the smallest pack",
+			quantity: 100,
+			opts:     FulfillmentOptions{Mode: ModeUnder},
+			expect:   PackFulfillmentResult{TotalItems: 0, Packs: map[int]int{}, Shortfall: 100},
+		},
+		{
+			name:     "Exact only met",
+			quantity: 1750,
+			opts:     FulfillmentOptions{Mode: ModeExactOnly},
+			expect:   PackFulfillmentResult{TotalItems: 1750, Packs: map[int]int{1000: 1, 500: 1, 250: 1}},
+		},
+		{
+			name:            "Exact only not met",
+			quantity:        1751,
+			opts:            FulfillmentOptions{Mode: ModeExactOnly},
+			wantConstraints: []string{ModeExactOnly},
+		},
+		{
+			name:     "Under with a larger max_overage",
+			quantity: 251,
+			opts:     FulfillmentOptions{Mode: ModeUnder, MaxOverage: intPtr(249)},
+			expect:   PackFulfillmentResult{TotalItems: 250, Packs: map[int]int{250: 1}, Shortfall: 1},
+		},
+		{
+			name:            "Exact only not met within max_overage",
+			quantity:        1745,
+			opts:            FulfillmentOptions{Mode: ModeExactOnly, MaxOverage: intPtr(10)},
+			wantConstraints: []string{ModeExactOnly},
+		},
+	}
+
+	svc := &PackFulfillmentService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.FulfillOrder(context.Background(), tt.quantity, append([]int(nil), packSizes...), tt.opts)
+			if tt.wantConstraints != nil {
+				var infeasible *InfeasibleError
+				if !errors.As(err, &infeasible) {
+					t.Fatalf("expected an InfeasibleError, got %v", err)
+				}
+				if !reflect.DeepEqual(infeasible.Constraints, tt.wantConstraints) {
+					t.Errorf("Constraints: got %v, want %v", infeasible.Constraints, tt.wantConstraints)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.expect) {
+				t.Errorf("got %+v, want %+v", got, tt.expect)
+			}
+		})
+	}
+
+	if _, err := svc.FulfillOrder(context.Background(), 100, []int{250}, FulfillmentOptions{Mode: "sideways"}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("expected ErrInvalidOptions for an unknown mode, got %v", err)
+	}
+}
+
+func TestPackFulfillmentService_RejectsNonPositivePackSizes(t *testing.T) {
+	ctx := context.Background()
+	packSvc := &PackService{Repo: out.NewPackRepositoryMem()}
+	productID := uuid.New()
+	for _, sizes := range [][]int{{0}, {250, -5}} {
+		if _, err := packSvc.ReplacePackSet(ctx, productID, sizes, 0); !errors.Is(err, ErrInvalidPackSizes) {
+			t.Errorf("ReplacePackSet(%v): err = %v, want ErrInvalidPackSizes", sizes, err)
+		}
+		if _, err := (&PackFulfillmentService{}).FulfillOrder(ctx, 251, sizes, FulfillmentOptions{}); !errors.Is(err, ErrInvalidPackSizes) {
+			t.Errorf("FulfillOrder(%v): err = %v, want ErrInvalidPackSizes", sizes, err)
+		}
+	}
+}
+
+// recordingMetrics records the results observed by a PackFulfillmentService.
+type recordingMetrics struct{ results []int } // pack counts
+
+func (m *recordingMetrics) ObserveSolve(time.Duration, int) {}
+
+func (m *recordingMetrics) ObserveResult(_ uuid.UUID, _, packCount int) {
+	m.results = append(m.results, packCount)
+}
+
+// fullLimiter admits no fulfillment.
+type fullLimiter struct{}
+
+func (fullLimiter) TryAcquire() bool { return false }
+func (fullLimiter) Release()         {}
+
+func TestPackFulfillmentService_FulfillForCustomer(t *testing.T) {
+	ctx := context.Background()
+	metrics := &recordingMetrics{}
+	svc := &PackFulfillmentService{
+		Packs:     &PackService{Repo: out.NewPackRepositoryMem()},
+		Customers: &CustomerService{Repo: out.NewCustomerRepositoryMem()},
+		Metrics:   metrics,
+	}
+	productID := uuid.New()
+	if _, err := svc.Packs.ReplaceByProduct(ctx, productID, []int{250, 500}); err != nil {
+		t.Fatal(err)
+	}
+	restricted := &model.Customer{Name: "Small shop", PackRules: []model.PackRule{{MaxPackSize: 100}}}
+	if err := svc.Customers.Create(ctx, restricted); err != nil {
+		t.Fatal(err)
+	}
+
+	result, packs, err := svc.FulfillForCustomer(ctx, productID, 251, uuid.Nil, FulfillmentOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TotalItems != 500 || len(packs) != 2 {
+		t.Errorf("got %d items and %d packs, want 500 items and the product's 2 packs", result.TotalItems, len(packs))
+	}
+	if len(metrics.results) != 1 {
+		t.Errorf("recorded %d results, want 1", len(metrics.results))
+	}
+
+	tests := []struct {
+		name       string
+		productID  uuid.UUID
+		customerID uuid.UUID
+		solvers    SolverLimiter
+		want       error
+	}{
+		{"no packs", uuid.New(), uuid.Nil, nil, port.ErrProductNotFound},
+		{"unknown customer", productID, uuid.New(), nil, port.ErrCustomerNotFound},
+		{"no accepted pack size", productID, restricted.ID, nil, ErrNoAcceptedPackSizes},
+		{"solvers busy", productID, uuid.Nil, fullLimiter{}, ErrSolverBusy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc.Solvers = tt.solvers
+			if _, _, err := svc.FulfillForCustomer(ctx, tt.productID, 251, tt.customerID, FulfillmentOptions{}); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	svc.Solvers = nil
+	svc.Configure(FulfillmentSettings{MaxQuantity: 1000})
+	for _, quantity := range []int{0, -1, 1001} {
+		if _, _, err := svc.FulfillForCustomer(ctx, productID, quantity, uuid.Nil, FulfillmentOptions{}); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("quantity %d: err = %v, want ErrInvalidOptions", quantity, err)
+		}
+	}
+	if len(metrics.results) != 1 {
+		t.Errorf("recorded %d results after failures, want 1", len(metrics.results))
+	}
+}
@@ -1,48 +1,151 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
 )
 
+// ErrInvalidMeasurements is returned when a pack's dimensions or weight are not positive.
+var ErrInvalidMeasurements = errors.New("pack dimensions and weight must be positive")
+
+// PackChangeListener is notified after a product's pack set has been replaced.
+type PackChangeListener interface {
+	PacksReplaced(productID uuid.UUID, oldSizes, newSizes []int)
+}
+
 // PackService provides business logic for packs.
 type PackService struct {
-	Repo port.PackRepository
+	Repo      port.PackRepository
+	Listeners []PackChangeListener
+	Events    *EventRecorder // optional
+}
+
+func (s *PackService) Create(ctx context.Context, pack *model.Pack) (err error) {
+	ctx, span := tracer.Start(ctx, "PackService.Create")
+	defer func() { endSpan(span, err) }()
+	return s.Repo.Create(ctx, pack)
 }
 
-func (s *PackService) Create(pack *model.Pack) error {
-	return s.Repo.Create(pack)
+func (s *PackService) GetByID(ctx context.Context, id uuid.UUID) (_ *model.Pack, err error) {
+	ctx, span := tracer.Start(ctx, "PackService.GetByID")
+	defer func() { endSpan(span, err) }()
+	return s.Repo.GetByID(ctx, id)
 }
 
-func (s *PackService) GetByID(id uuid.UUID) (*model.Pack, error) {
-	return s.Repo.GetByID(id)
+func (s *PackService) Update(ctx context.Context, pack *model.Pack) (err error) {
+	ctx, span := tracer.Start(ctx, "PackService.Update")
+	defer func() { endSpan(span, err) }()
+	return s.Repo.Update(ctx, pack)
 }
 
-func (s *PackService) Update(pack *model.Pack) error {
-	return s.Repo.Update(pack)
+func (s *PackService) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "PackService.Delete")
+	defer func() { endSpan(span, err) }()
+	return s.Repo.Delete(ctx, id)
 }
 
-func (s *PackService) Delete(id uuid.UUID) error {
-	return s.Repo.Delete(id)
+func (s *PackService) ListByProduct(ctx context.Context, productID uuid.UUID) (_ []*model.Pack, err error) {
+	ctx, span := tracer.Start(ctx, "PackService.ListByProduct")
+	span.SetAttributes(attribute.String("product.id", productID.String()))
+	defer func() { endSpan(span, err) }()
+	return s.Repo.ListByProduct(ctx, productID)
+}
+
+// UpdateMeasurements sets the dimensions and weight of one of a product's packs; a nil dims and a zero
+// weight mark them unknown. It fails with port.ErrPackNotFound if the product has no such pack.
+func (s *PackService) UpdateMeasurements(ctx context.Context, productID, packID uuid.UUID, dims *model.Dimensions, weightG int) (_ *model.Pack, err error) {
+	ctx, span := tracer.Start(ctx, "PackService.UpdateMeasurements")
+	span.SetAttributes(attribute.String("product.id", productID.String()), attribute.String("pack.id", packID.String()))
+	defer func() { endSpan(span, err) }()
+	if (dims != nil && !validDimensions(*dims)) || weightG < 0 {
+		return nil, ErrInvalidMeasurements
+	}
+	pack, err := s.Repo.GetByID(ctx, packID)
+	if err != nil {
+		return nil, err
+	}
+	if pack.ProductID != productID {
+		return nil, port.ErrPackNotFound
+	}
+	updated := *pack
+	updated.Dimensions, updated.WeightG = dims, weightG
+	if err := s.Repo.Update(ctx, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
 }
 
-func (s *PackService) ListByProduct(productID uuid.UUID) ([]*model.Pack, error) {
-	return s.Repo.ListByProduct(productID)
+// GetPackSet returns a product's packs together with the version of the set.
+func (s *PackService) GetPackSet(ctx context.Context, productID uuid.UUID) (_ *model.PackSet, err error) {
+	ctx, span := tracer.Start(ctx, "PackService.GetPackSet")
+	span.SetAttributes(attribute.String("product.id", productID.String()))
+	defer func() { endSpan(span, err) }()
+	return s.Repo.GetPackSet(ctx, productID)
 }
 
 // ReplaceByProduct deletes all existing packs for a product and creates new ones with the given sizes.
-func (s *PackService) ReplaceByProduct(productID uuid.UUID, sizes []int) ([]*model.Pack, error) {
-	if err := s.Repo.DeleteByProduct(productID); err != nil {
+func (s *PackService) ReplaceByProduct(ctx context.Context, productID uuid.UUID, sizes []int) ([]*model.Pack, error) {
+	set, err := s.ReplacePackSet(ctx, productID, sizes, 0)
+	if err != nil {
 		return nil, err
 	}
-	var packs []*model.Pack
-	for _, size := range sizes {
-		pack := &model.Pack{ProductID: productID, Size: size}
-		if err := s.Repo.Create(pack); err != nil {
+	return set.Packs, nil
+}
+
+// ReplacePackSet replaces a product's packs with new ones of the given sizes, provided the pack set is still
+// at expectedVersion; zero skips the check. It fails with ErrInvalidPackSizes if a size is not positive and
+// with port.ErrVersionConflict if the set has changed.
+func (s *PackService) ReplacePackSet(ctx context.Context, productID uuid.UUID, sizes []int, expectedVersion int64) (_ *model.PackSet, err error) {
+	ctx, span := tracer.Start(ctx, "PackService.ReplacePackSet")
+	span.SetAttributes(
+		attribute.String("product.id", productID.String()),
+		attribute.IntSlice("pack.sizes", sizes),
+		attribute.Int64("pack_set.expected_version", expectedVersion),
+	)
+	defer func() { endSpan(span, err) }()
+	if err := validatePackSizes(sizes); err != nil {
+		return nil, err
+	}
+	var replaced *model.PackSet
+	oldSizes := []int{}
+	err = s.Events.record(ctx, func(ctx context.Context) ([]*model.Event, error) {
+		old, set, err := s.Repo.ReplacePackSet(ctx, productID, sizes, expectedVersion)
+		if err != nil {
 			return nil, err
 		}
-		packs = append(packs, pack)
+		replaced = set
+		for _, p := range old.Packs {
+			oldSizes = append(oldSizes, p.Size)
+		}
+		return newEvents(model.EventPacksReplaced, productID, model.PacksReplacedPayload{
+			Version:  replaced.Version,
+			OldSizes: oldSizes,
+			NewSizes: sizes,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range s.Listeners {
+		l.PacksReplaced(productID, oldSizes, sizes)
+	}
+	return replaced, nil
+}
+
+// validatePackSizes fails with ErrInvalidPackSizes, naming the first offending size, unless every size is
+// positive.
+func validatePackSizes(sizes []int) error {
+	for _, size := range sizes {
+		if size <= 0 {
+			return fmt.Errorf("%w: got %d", ErrInvalidPackSizes, size)
+		}
 	}
-	return packs, nil
+	return nil
 }
@@ -1,32 +1,73 @@
 package service
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
 )
 
+// ProductDeleteListener is notified after a product has been deleted.
+type ProductDeleteListener interface {
+	ProductDeleted(productID uuid.UUID)
+}
+
 // ProductService provides business logic for products.
 type ProductService struct {
-	Repo port.ProductRepository
+	Repo      port.ProductRepository
+	Listeners []ProductDeleteListener
+	Events    *EventRecorder // optional
 }
 
-func (s *ProductService) Create(product *model.Product) error {
-	return s.Repo.Create(product)
+func (s *ProductService) Create(ctx context.Context, product *model.Product) (err error) {
+	ctx, span := tracer.Start(ctx, "ProductService.Create")
+	defer func() { endSpan(span, err) }()
+	return s.Events.record(ctx, func(ctx context.Context) ([]*model.Event, error) {
+		if err := s.Repo.Create(ctx, product); err != nil {
+			return nil, err
+		}
+		return newEvents(model.EventProductCreated, product.ID, product)
+	})
 }
 
-func (s *ProductService) GetByID(id uuid.UUID) (*model.Product, error) {
-	return s.Repo.GetByID(id)
+func (s *ProductService) GetByID(ctx context.Context, id uuid.UUID) (_ *model.Product, err error) {
+	ctx, span := tracer.Start(ctx, "ProductService.GetByID")
+	defer func() { endSpan(span, err) }()
+	return s.Repo.GetByID(ctx, id)
 }
 
-func (s *ProductService) Update(product *model.Product) error {
-	return s.Repo.Update(product)
+func (s *ProductService) Update(ctx context.Context, product *model.Product) (err error) {
+	ctx, span := tracer.Start(ctx, "ProductService.Update")
+	defer func() { endSpan(span, err) }()
+	return s.Events.record(ctx, func(ctx context.Context) ([]*model.Event, error) {
+		if err := s.Repo.Update(ctx, product); err != nil {
+			return nil, err
+		}
+		return newEvents(model.EventProductUpdated, product.ID, product)
+	})
 }
 
-func (s *ProductService) Delete(id uuid.UUID) error {
-	return s.Repo.Delete(id)
+func (s *ProductService) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "ProductService.Delete")
+	defer func() { endSpan(span, err) }()
+	err = s.Events.record(ctx, func(ctx context.Context) ([]*model.Event, error) {
+		if err := s.Repo.Delete(ctx, id); err != nil {
+			return nil, err
+		}
+		return newEvents(model.EventProductDeleted, id, model.ProductDeletedPayload{ID: id})
+	})
+	if err != nil {
+		return err
+	}
+	for _, l := range s.Listeners {
+		l.ProductDeleted(id)
+	}
+	return nil
 }
 
-func (s *ProductService) List() ([]*model.Product, error) {
-	return s.Repo.List()
+func (s *ProductService) List(ctx context.Context) (_ []*model.Product, err error) {
+	ctx, span := tracer.Start(ctx, "ProductService.List")
+	defer func() { endSpan(span, err) }()
+	return s.Repo.List(ctx)
 }
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+)
+
+// ErrUnshippable is returned when the packs of an order cannot be put in cartons: a pack's dimensions or
+// weight are unknown, no carton takes a pack, there are too many packs to plan, or the customer accepts
+// none of the product's pack sizes.
+var ErrUnshippable = errors.New("shipment cannot be planned")
+
+// maxShipmentPacks bounds the packs of a shipment plan, as the time to assign them to cartons grows with the
+// square of their number.
+const maxShipmentPacks = 10000
+
+// ShipmentPlan is the packs fulfilling an order and the cartons to ship them in.
+type ShipmentPlan struct {
+	ProductID         uuid.UUID       `json:"product_id"`
+	RequestedQuantity int             `json:"requested_quantity" example:"251"`
+	TotalItems        int             `json:"total_items" example:"500"`
+	Overage           int             `json:"overage" example:"249"`
+	Shortfall         int             `json:"shortfall" example:"0"`
+	PackCount         int             `json:"pack_count" example:"1"`
+	CartonCount       int             `json:"carton_count" example:"1"`
+	TotalWeightG      int             `json:"total_weight_g" example:"5200"`
+	Cartons           []PlannedCarton `json:"cartons"`
+}
+
+// PlannedCarton is a carton of a shipment and the packs to put in it.
+type PlannedCarton struct {
+	CartonID  uuid.UUID     `json:"carton_id"`
+	Name      string        `json:"name" example:"Medium box"`
+	Packs     []ShippedPack `json:"packs"`
+	WeightG   int           `json:"weight_g" example:"5200"`    // of the packs
+	FillRatio float64       `json:"fill_ratio" example:"0.625"` // share of the carton's volume taken by the packs, to 3 decimals
+}
+
+// ShippedPack is how many of a pack go in a carton.
+type ShippedPack struct {
+	ProductID uuid.UUID `json:"product_id"`
+	PackID    uuid.UUID `json:"pack_id"`
+	Size      int       `json:"size" example:"500"`
+	Count     int       `json:"count" example:"1"`
+}
+
+// ShipmentService plans shipments: it fulfills an order with packs, puts the packs in cartons and, for
+// orders of several products, groups the cartons into shipments.
+type ShipmentService struct {
+	Cartons     *CartonService
+	Fulfillment *PackFulfillmentService // fulfills the order lines; see FulfillForCustomer
+	Limits      ShipmentLimits          // of the shipments of PlanOrder
+}
+
+// Plan fulfills an order for quantity items of a product like FulfillForCustomer, restricted to the pack
+// sizes the customer accepts unless customerID is uuid.Nil, and assigns the packs to the fewest cartons
+// of the catalog; see packCartons. It fails with ErrUnshippable if the packs cannot be put in cartons.
+func (s *ShipmentService) Plan(ctx context.Context, productID uuid.UUID, quantity int, customerID uuid.UUID, opts FulfillmentOptions) (_ *ShipmentPlan, err error) {
+	ctx, span := tracer.Start(ctx, "ShipmentService.Plan")
+	span.SetAttributes(attribute.String("product.id", productID.String()), attribute.Int("fulfillment.quantity", quantity))
+	defer func() { endSpan(span, err) }()
+	customer, err := s.Fulfillment.customer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	result, shipped, err := s.fulfillLine(ctx, productID, quantity, customer, opts)
+	if err != nil {
+		return nil, err
+	}
+	cartons, err := s.Cartons.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	loads, err := packCartons(shipped, cartons)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("shipment.cartons", len(loads)))
+
+	plan := &ShipmentPlan{
+		ProductID:         productID,
+		RequestedQuantity: quantity,
+		TotalItems:        result.TotalItems,
+		Overage:           max(result.TotalItems-quantity, 0),
+		Shortfall:         result.Shortfall,
+		PackCount:         result.PackCount(),
+		Cartons:           plannedCartons(loads),
+	}
+	plan.CartonCount = len(plan.Cartons)
+	for _, c := range plan.Cartons {
+		plan.TotalWeightG += c.WeightG
+	}
+	return plan, nil
+}
+
+// fulfillLine fulfills an order for quantity items of a product like FulfillForCustomer, for a customer
+// already loaded or nil for none, and returns the result with one element per pack to ship.
+func (s *ShipmentService) fulfillLine(ctx context.Context, productID uuid.UUID, quantity int, customer *model.Customer, opts FulfillmentOptions) (PackFulfillmentResult, []*model.Pack, error) {
+	result, packs, err := s.Fulfillment.fulfillFor(ctx, productID, quantity, customer, opts)
+	if errors.Is(err, ErrNoAcceptedPackSizes) {
+		return PackFulfillmentResult{}, nil, fmt.Errorf("%w: %w", ErrUnshippable, err)
+	}
+	if err != nil {
+		return PackFulfillmentResult{}, nil, err
+	}
+	if n := result.PackCount(); n > maxShipmentPacks {
+		return PackFulfillmentResult{}, nil, fmt.Errorf("%w: %d packs exceed the limit of %d per shipment", ErrUnshippable, n, maxShipmentPacks)
+	}
+
+	bySize := map[int]*model.Pack{}
+	for _, p := range packs {
+		if bySize[p.Size] == nil {
+			bySize[p.Size] = p
+		}
+	}
+	var shipped []*model.Pack
+	for size, count := range result.Packs {
+		pack := bySize[size]
+		if !pack.Measured() {
+			return PackFulfillmentResult{}, nil, fmt.Errorf("%w: the dimensions or weight of the pack of %d of product %s are unknown", ErrUnshippable, size, productID)
+		}
+		for range count {
+			shipped = append(shipped, pack)
+		}
+	}
+	return result, shipped, nil
+}
+
+// plannedCartons describes loads, largest carton first, with each carton's packs largest first.
+func plannedCartons(loads []*cartonLoad) []PlannedCarton {
+	loads = slices.SortedStableFunc(slices.Values(loads), func(a, b *cartonLoad) int {
+		return cmp.Compare(b.carton.Inner.Volume(), a.carton.Inner.Volume())
+	})
+	cartons := make([]PlannedCarton, 0, len(loads))
+	for _, l := range loads {
+		cartons = append(cartons, PlannedCarton{
+			CartonID:  l.carton.ID,
+			Name:      l.carton.Name,
+			Packs:     shippedPacks(l.packs),
+			WeightG:   l.weightG,
+			FillRatio: math.Round(1000*float64(l.volume)/float64(l.carton.Inner.Volume())) / 1000,
+		})
+	}
+	return cartons
+}
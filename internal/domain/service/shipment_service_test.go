@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+var (
+	testLargeCarton = &model.Carton{ID: uuid.New(), Name: "Large", Inner: model.Dimensions{LengthMM: 300, WidthMM: 300, HeightMM: 300}, MaxWeightG: 20000}
+	testSmallCarton = &model.Carton{ID: uuid.New(), Name: "Small", Inner: model.Dimensions{LengthMM: 200, WidthMM: 200, HeightMM: 200}, MaxWeightG: 5000}
+)
+
+// testPacks returns n packs of a 100 mm cube weighing a kilogram.
+func testPacks(n int) []*model.Pack {
+	pack := &model.Pack{ID: uuid.New(), Size: 10, Dimensions: &model.Dimensions{LengthMM: 100, WidthMM: 100, HeightMM: 100}, WeightG: 1000}
+	packs := make([]*model.Pack, n)
+	for i := range packs {
+		packs[i] = pack
+	}
+	return packs
+}
+
+func TestPackCartons(t *testing.T) {
+	cartons := []*model.Carton{testSmallCarton, testLargeCarton}
+	tests := []struct {
+		name  string
+		packs int
+		want  []string // carton names
+	}{
+		{"fits the small carton", 3, []string{"Small"}},
+		{"too heavy for the small carton", 10, []string{"Large"}},
+		{"heavier than one large carton", 30, []string{"Large", "Large"}},
+		{"remainder fits the small carton", 24, []string{"Large", "Small"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loads, err := packCartons(testPacks(tt.packs), cartons)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, c := range plannedCartons(loads) {
+				got = append(got, c.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("cartons = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackCartons_Orientation(t *testing.T) {
+	// Too long for the carton lying flat, but it fits along the carton's longest side.
+	pack := &model.Pack{ID: uuid.New(), Size: 1, Dimensions: &model.Dimensions{LengthMM: 50, WidthMM: 50, HeightMM: 280}, WeightG: 100}
+	flat := &model.Carton{ID: uuid.New(), Name: "Flat", Inner: model.Dimensions{LengthMM: 100, WidthMM: 300, HeightMM: 60}, MaxWeightG: 1000}
+	loads, err := packCartons([]*model.Pack{pack}, []*model.Carton{flat})
+	if err != nil || len(loads) != 1 {
+		t.Fatalf("loads = %d, err = %v, want one carton", len(loads), err)
+	}
+
+	pack.Dimensions.HeightMM = 400
+	if _, err := packCartons([]*model.Pack{pack}, []*model.Carton{flat}); !errors.Is(err, ErrUnshippable) {
+		t.Errorf("oversized pack: err = %v, want ErrUnshippable", err)
+	}
+}
+
+func TestShipmentService_Plan(t *testing.T) {
+	ctx := context.Background()
+	packSvc := &PackService{Repo: out.NewPackRepositoryMem()}
+	cartonSvc := &CartonService{Repo: out.NewCartonRepositoryMem()}
+	svc := &ShipmentService{Cartons: cartonSvc, Fulfillment: &PackFulfillmentService{Packs: packSvc}}
+	for _, c := range []*model.Carton{testSmallCarton, testLargeCarton} {
+		c := *c
+		if err := cartonSvc.Create(ctx, &c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	productID := uuid.New()
+	packs, err := packSvc.ReplaceByProduct(ctx, productID, []int{250, 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.Plan(ctx, productID, 251, uuid.Nil, FulfillmentOptions{}); !errors.Is(err, ErrUnshippable) {
+		t.Fatalf("unmeasured packs: err = %v, want ErrUnshippable", err)
+	}
+	for _, p := range packs {
+		side := 100 + p.Size/5 // 150 and 200 mm cubes
+		if _, err := packSvc.UpdateMeasurements(ctx, productID, p.ID, &model.Dimensions{LengthMM: side, WidthMM: side, HeightMM: side}, 4*p.Size); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 1250 items are two packs of 500 and one of 250. Only one 200 mm cube fits a small carton, while all
+	// three fit a large one.
+	plan, err := svc.Plan(ctx, productID, 1250, uuid.Nil, FulfillmentOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.PackCount != 3 || plan.TotalWeightG != 5000 || plan.CartonCount != 1 || plan.Cartons[0].Name != "Large" {
+		t.Errorf("plan = %+v, want 3 packs of 5000 g in one large carton", plan)
+	}
+	if got := plan.Cartons[0].Packs; len(got) != 2 || got[0].Size != 500 || got[0].Count != 2 || got[1].Size != 250 || got[1].Count != 1 {
+		t.Errorf("packs = %+v, want 2 x 500 then 1 x 250", got)
+	}
+}
+
+// newTestShipments returns a ShipmentService over in-memory repositories with the small and large cartons.
+func newTestShipments(t *testing.T) *ShipmentService {
+	t.Helper()
+	cartonSvc := &CartonService{Repo: out.NewCartonRepositoryMem()}
+	for _, c := range []*model.Carton{testSmallCarton, testLargeCarton} {
+		c := *c
+		if err := cartonSvc.Create(context.Background(), &c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &ShipmentService{Cartons: cartonSvc, Fulfillment: &PackFulfillmentService{Packs: &PackService{Repo: out.NewPackRepositoryMem()}}}
+}
+
+// addTestProduct gives a new product packs of the given sizes, measured unless measured is false: a pack of
+// n items is a cube of 100+n/5 mm weighing 4n g.
+func addTestProduct(t *testing.T, svc *ShipmentService, measured bool, sizes ...int) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+	productID := uuid.New()
+	packs, err := svc.Fulfillment.Packs.ReplaceByProduct(ctx, productID, sizes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range packs {
+		if !measured {
+			break
+		}
+		side := 100 + p.Size/5
+		if _, err := svc.Fulfillment.Packs.UpdateMeasurements(ctx, productID, p.ID, &model.Dimensions{LengthMM: side, WidthMM: side, HeightMM: side}, 4*p.Size); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return productID
+}
+
+func TestShipmentService_PlanOrder(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestShipments(t)
+	shoes := addTestProduct(t, svc, true, 250, 500) // two packs of 500: 200 mm cubes of 2000 g
+	socks := addTestProduct(t, svc, true, 100)      // three packs of 100: 120 mm cubes of 400 g
+	lines := []OrderLine{{ProductID: shoes, Quantity: 1000}, {ProductID: socks, Quantity: 300}}
+
+	plan, err := svc.PlanOrder(ctx, lines, uuid.Nil, FulfillmentOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.ShipmentCount != 1 || plan.CartonCount != 1 || plan.PackCount != 5 || plan.TotalWeightG != 5200 {
+		t.Errorf("plan = %+v, want 5 packs of 5200 g in one carton and shipment", plan)
+	}
+	if got := plan.Shipments[0].Products; len(got) != 2 || got[0] != (ShippedProduct{shoes, 1000}) || got[1] != (ShippedProduct{socks, 300}) {
+		t.Errorf("shipment products = %+v", got)
+	}
+
+	// A shipment of at most 3000 g takes one pack of 500 and two of 100 at most.
+	svc.Limits = ShipmentLimits{MaxWeightG: 3000}
+	plan, err = svc.PlanOrder(ctx, lines, uuid.Nil, FulfillmentOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.ShipmentCount != 2 || plan.CartonCount != 2 || plan.TotalWeightG != 5200 {
+		t.Errorf("plan = %+v, want two shipments of one carton", plan)
+	}
+	for _, shipment := range plan.Shipments {
+		if shipment.WeightG > 3000 || len(shipment.Products) != 2 {
+			t.Errorf("shipment = %+v, want both products within 3000 g", shipment)
+		}
+	}
+
+	// Cartons larger than a shipment are not used.
+	svc.Limits = ShipmentLimits{MaxVolumeCM3: 8000}
+	plan, err = svc.PlanOrder(ctx, lines, uuid.Nil, FulfillmentOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, shipment := range plan.Shipments {
+		if shipment.VolumeCM3 > 8000 || shipment.Cartons[0].Name != "Small" {
+			t.Errorf("shipment = %+v, want one small carton", shipment)
+		}
+	}
+
+	var lineErr *OrderLineError
+	_, err = svc.PlanOrder(ctx, append(lines, OrderLine{ProductID: shoes, Quantity: 1}), uuid.Nil, FulfillmentOptions{})
+	if !errors.As(err, &lineErr) || lineErr.Line != 3 || !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("repeated product: err = %v, want ErrInvalidOptions on line 3", err)
+	}
+	_, err = svc.PlanOrder(ctx, []OrderLine{{ProductID: uuid.New(), Quantity: 1}}, uuid.Nil, FulfillmentOptions{})
+	if !errors.As(err, &lineErr) || lineErr.Line != 1 || !errors.Is(err, port.ErrProductNotFound) {
+		t.Errorf("unknown product: err = %v, want ErrProductNotFound on line 1", err)
+	}
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/port"
+)
+
+// Webhook request headers. The signature is "sha256=" followed by the hex HMAC-SHA256, keyed with the
+// subscription's secret, of the timestamp header, a dot and the body; see SignWebhook.
+const (
+	WebhookHeaderID        = "Webhook-Id"
+	WebhookHeaderEvent     = "Webhook-Event"
+	WebhookHeaderTimestamp = "Webhook-Timestamp"
+	WebhookHeaderSignature = "Webhook-Signature"
+)
+
+// Defaults of WebhookService.
+const (
+	defaultWebhookMaxAttempts = 8
+	defaultWebhookBackoff     = 10 * time.Second
+	defaultWebhookMaxBackoff  = time.Hour
+	webhookClaimTTL           = time.Minute
+	webhookBatchSize          = 50
+	webhookInterestTTL        = 10 * time.Second
+)
+
+// ErrInvalidWebhook is returned when a webhook subscription is malformed.
+var ErrInvalidWebhook = errors.New("invalid webhook subscription")
+
+// WebhookService manages webhook subscriptions and delivers domain events to them. It is a
+// port.EventPublisher: publishing an event queues a delivery per interested subscription, which Dispatch
+// sends, retrying failures with exponential backoff until MaxAttempts is reached and the delivery is dead.
+type WebhookService struct {
+	Repo        port.WebhookRepository
+	Sender      port.WebhookSender
+	MaxAttempts int           // Attempts before a delivery is dead; 0 means 8
+	Backoff     time.Duration // Wait after the first failed attempt, doubled after each further one; 0 means 10s
+	MaxBackoff  time.Duration // Longest wait between attempts; 0 means 1h
+
+	// AllowPrivateTargets accepts subscriptions whose host is, or resolves to, a loopback, private or
+	// link-local address; see model.PublicWebhookTarget. The Sender must enforce the same policy when it
+	// connects, as the host may resolve differently by then.
+	AllowPrivateTargets bool
+
+	// subs caches the subscriptions for Wants; it is dropped by Create and Delete.
+	subsMu sync.Mutex
+	subs   []*model.WebhookSubscription
+	subsAt time.Time
+
+	now func() time.Time // replaced in tests
+}
+
+// Create validates and stores a subscription, generating its secret if none is given.
+func (s *WebhookService) Create(ctx context.Context, sub *model.WebhookSubscription) (err error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.Create")
+	defer func() { endSpan(span, err) }()
+	if err := validateWebhook(sub); err != nil {
+		return err
+	}
+	if !s.AllowPrivateTargets {
+		if err := checkWebhookHost(ctx, sub.URL); err != nil {
+			return err
+		}
+	}
+	if sub.EventTypes == nil {
+		sub.EventTypes = []string{}
+	}
+	if sub.Secret == "" {
+		secret := make([]byte, 32)
+		rand.Read(secret)
+		sub.Secret = hex.EncodeToString(secret)
+	}
+	sub.CreatedAt = s.clock().UTC()
+	defer s.dropCachedSubscriptions()
+	return s.Repo.CreateSubscription(ctx, sub)
+}
+
+func (s *WebhookService) GetByID(ctx context.Context, id uuid.UUID) (_ *model.WebhookSubscription, err error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.GetByID")
+	span.SetAttributes(attribute.String("webhook.id", id.String()))
+	defer func() { endSpan(span, err) }()
+	return s.Repo.GetSubscription(ctx, id)
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.Delete")
+	span.SetAttributes(attribute.String("webhook.id", id.String()))
+	defer func() { endSpan(span, err) }()
+	defer s.dropCachedSubscriptions()
+	return s.Repo.DeleteSubscription(ctx, id)
+}
+
+func (s *WebhookService) List(ctx context.Context) (_ []*model.WebhookSubscription, err error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.List")
+	defer func() { endSpan(span, err) }()
+	return s.Repo.ListSubscriptions(ctx)
+}
+
+// Deliveries returns the delivery log of a subscription, newest first, optionally only with status.
+func (s *WebhookService) Deliveries(ctx context.Context, subscriptionID uuid.UUID, status string, limit int) (_ []*model.WebhookDelivery, err error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.Deliveries")
+	span.SetAttributes(attribute.String("webhook.id", subscriptionID.String()))
+	defer func() { endSpan(span, err) }()
+	if _, err := s.Repo.GetSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	return s.Repo.ListDeliveries(ctx, subscriptionID, status, limit)
+}
+
+// Redeliver schedules a delivery of a subscription for an immediate new round of attempts, e.g. once a
+// dead delivery's receiver is fixed.
+func (s *WebhookService) Redeliver(ctx context.Context, subscriptionID, deliveryID uuid.UUID) (_ *model.WebhookDelivery, err error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.Redeliver")
+	span.SetAttributes(attribute.String("webhook.id", subscriptionID.String()), attribute.String("webhook.delivery_id", deliveryID.String()))
+	defer func() { endSpan(span, err) }()
+	d, err := s.Repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if d.SubscriptionID != subscriptionID {
+		return nil, port.ErrWebhookNotFound
+	}
+	now := s.clock().UTC()
+	d.Status, d.Attempts, d.NextAttemptAt, d.UpdatedAt = model.DeliveryPending, 0, now, now
+	if err := s.Repo.UpdateDelivery(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Wants reports whether a subscription covers events of eventType. Subscriptions are cached for up to 10s,
+// so a subscription created on another instance may miss the events of that time. If the subscriptions
+// cannot be listed, every event is wanted.
+func (s *WebhookService) Wants(ctx context.Context, eventType string) bool {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+	if now := s.clock(); s.subsAt.IsZero() || now.Sub(s.subsAt) >= webhookInterestTTL {
+		subs, err := s.Repo.ListSubscriptions(ctx)
+		if err != nil {
+			slog.Warn("Failed to list webhook subscriptions", "error", err)
+			return true
+		}
+		s.subs, s.subsAt = subs, now
+	}
+	return slices.ContainsFunc(s.subs, func(sub *model.WebhookSubscription) bool { return sub.Wants(eventType) })
+}
+
+func (s *WebhookService) dropCachedSubscriptions() {
+	s.subsMu.Lock()
+	s.subsAt = time.Time{}
+	s.subsMu.Unlock()
+}
+
+// Publish queues a delivery of event to every subscription that wants it.
+func (s *WebhookService) Publish(ctx context.Context, event *model.Event) (err error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.Publish")
+	span.SetAttributes(attribute.String("event.type", event.Type))
+	defer func() { endSpan(span, err) }()
+	subs, err := s.Repo.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	now := s.clock().UTC()
+	var deliveries []*model.WebhookDelivery
+	for _, sub := range subs {
+		if !sub.Wants(event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, &model.WebhookDelivery{
+			ID:             uuid.New(),
+			SubscriptionID: sub.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Body:           body,
+			Status:         model.DeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return s.Repo.CreateDeliveries(ctx, deliveries...)
+}
+
+// Run dispatches due deliveries every interval until ctx is done.
+func (s *WebhookService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Dispatch(ctx); err != nil {
+			slog.Error("Failed to dispatch webhooks", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch sends the deliveries that are due and returns how many it attempted.
+func (s *WebhookService) Dispatch(ctx context.Context) (n int, err error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.Dispatch")
+	defer func() { endSpan(span, err) }()
+	now := s.clock().UTC()
+	due, err := s.Repo.ClaimDueDeliveries(ctx, now, webhookBatchSize, now.Add(webhookClaimTTL))
+	if err != nil {
+		return 0, err
+	}
+	span.SetAttributes(attribute.Int("webhook.deliveries", len(due)))
+	for _, d := range due {
+		if err := s.attempt(ctx, d); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// attempt sends d once and records the outcome.
+func (s *WebhookService) attempt(ctx context.Context, d *model.WebhookDelivery) error {
+	sub, err := s.Repo.GetSubscription(ctx, d.SubscriptionID)
+	if errors.Is(err, port.ErrWebhookNotFound) {
+		return nil // deleted with its deliveries meanwhile
+	}
+	if err != nil {
+		return err
+	}
+	now := s.clock().UTC()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	header := map[string]string{
+		"Content-Type":         "application/json",
+		WebhookHeaderID:        d.ID.String(),
+		WebhookHeaderEvent:     d.EventType,
+		WebhookHeaderTimestamp: timestamp,
+		WebhookHeaderSignature: SignWebhook(sub.Secret, timestamp, d.Body),
+	}
+	status, sendErr := s.Sender.Send(ctx, sub.URL, header, d.Body)
+	if sendErr == nil && (status < 200 || status > 299) {
+		sendErr = fmt.Errorf("receiver responded with status %d", status)
+	}
+
+	d.Attempts++
+	d.ResponseStatus = status
+	d.UpdatedAt = s.clock().UTC()
+	switch {
+	case sendErr == nil:
+		d.Status, d.LastError = model.DeliveryDelivered, ""
+	case d.Attempts >= s.maxAttempts():
+		d.Status, d.LastError = model.DeliveryDead, sendErr.Error()
+		slog.Warn("Webhook delivery dead", "id", d.ID, "subscription_id", d.SubscriptionID, "attempts", d.Attempts, "error", sendErr)
+	default:
+		d.LastError = sendErr.Error()
+		d.NextAttemptAt = d.UpdatedAt.Add(s.backoff(d.Attempts))
+	}
+	if err := s.Repo.UpdateDelivery(ctx, d); err != nil && !errors.Is(err, port.ErrWebhookNotFound) {
+		return err
+	}
+	return nil
+}
+
+// backoff returns the wait after the given number of failed attempts.
+func (s *WebhookService) backoff(attempts int) time.Duration {
+	wait, limit := s.Backoff, s.MaxBackoff
+	if wait <= 0 {
+		wait = defaultWebhookBackoff
+	}
+	if limit <= 0 {
+		limit = defaultWebhookMaxBackoff
+	}
+	for i := 1; i < attempts && wait < limit; i++ {
+		wait *= 2
+	}
+	return min(wait, limit)
+}
+
+func (s *WebhookService) maxAttempts() int {
+	if s.MaxAttempts <= 0 {
+		return defaultWebhookMaxAttempts
+	}
+	return s.MaxAttempts
+}
+
+func (s *WebhookService) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+// SignWebhook returns the Webhook-Signature header of a delivery body sent at timestamp, a Unix time in
+// seconds, to a subscription with secret. Receivers verify a delivery by computing it again.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// validateWebhook checks that the subscription has an absolute HTTP(S) URL and only known event types.
+func validateWebhook(sub *model.WebhookSubscription) error {
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	for _, t := range sub.EventTypes {
+		if !slices.Contains(model.EventTypes, t) {
+			return fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, t)
+		}
+	}
+	return nil
+}
+
+// checkWebhookHost resolves the host of a valid webhook URL and checks that every address it resolves to is
+// a public webhook target.
+func checkWebhookHost(ctx context.Context, rawURL string) error {
+	u, _ := url.Parse(rawURL) // validated by validateWebhook
+	host := u.Hostname()
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{addr}
+	} else if addrs, err = net.DefaultResolver.LookupNetIP(ctx, "ip", host); err != nil {
+		return fmt.Errorf("%w: cannot resolve host %q: %v", ErrInvalidWebhook, host, err)
+	}
+	for _, addr := range addrs {
+		if !model.PublicWebhookTarget(addr) {
+			return fmt.Errorf("%w: host %q resolves to %s, which is not a public address", ErrInvalidWebhook, host, addr)
+		}
+	}
+	return nil
+}
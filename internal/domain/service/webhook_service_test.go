@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/model"
+)
+
+// webhookReceiver is a local webhook endpoint answering with status and recording the requests whose
+// signature matches secret.
+type webhookReceiver struct {
+	*httptest.Server
+	secret string
+
+	mu       sync.Mutex
+	status   int
+	received []*model.Event
+	forged   int
+}
+
+func newWebhookReceiver(t *testing.T, secret string) *webhookReceiver {
+	rcv := &webhookReceiver{secret: secret, status: http.StatusNoContent}
+	rcv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rcv.mu.Lock()
+		defer rcv.mu.Unlock()
+		if r.Header.Get(WebhookHeaderSignature) != SignWebhook(rcv.secret, r.Header.Get(WebhookHeaderTimestamp), body) {
+			rcv.forged++
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var event model.Event
+		if err := json.Unmarshal(body, &event); err != nil || event.Type != r.Header.Get(WebhookHeaderEvent) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		rcv.received = append(rcv.received, &event)
+		w.WriteHeader(rcv.status)
+	}))
+	t.Cleanup(rcv.Close)
+	return rcv
+}
+
+func (rcv *webhookReceiver) respond(status int) {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	rcv.status = status
+}
+
+func (rcv *webhookReceiver) events() []*model.Event {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	return append([]*model.Event(nil), rcv.received...)
+}
+
+func mustEvent(t *testing.T, eventType string, productID uuid.UUID, at time.Time) *model.Event {
+	t.Helper()
+	event, err := model.NewEvent(eventType, productID, nil, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
+
+// newTestWebhookService returns a service with an in-memory repository whose clock is *now. It delivers to
+// private addresses, as the test receivers listen on loopback.
+func newTestWebhookService(now *time.Time) *WebhookService {
+	return &WebhookService{
+		Repo:                out.NewWebhookRepositoryMem(),
+		Sender:              out.WebhookSenderHTTP{Client: out.NewWebhookClient(0, true)},
+		MaxAttempts:         3,
+		Backoff:             time.Minute,
+		MaxBackoff:          time.Hour,
+		AllowPrivateTargets: true,
+		now:                 func() time.Time { return *now },
+	}
+}
+
+func TestWebhookService_DeliversSignedEvents(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc := newTestWebhookService(&now)
+	rcv := newWebhookReceiver(t, "s3cret")
+
+	sub := &model.WebhookSubscription{URL: rcv.URL, EventTypes: []string{model.EventPacksReplaced}, Secret: "s3cret"}
+	if err := svc.Create(ctx, sub); err != nil {
+		t.Fatal(err)
+	}
+	productID := uuid.New()
+	for _, eventType := range []string{model.EventProductCreated, model.EventPacksReplaced} {
+		if err := svc.Publish(ctx, mustEvent(t, eventType, productID, now)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n, err := svc.Dispatch(ctx); n != 1 || err != nil {
+		t.Fatalf("expected 1 delivery attempted, got %d, %v", n, err)
+	}
+	events := rcv.events()
+	if len(events) != 1 || events[0].Type != model.EventPacksReplaced || events[0].ProductID != productID {
+		t.Fatalf("expected the PacksReplaced event only, got %+v", events)
+	}
+	rcv.mu.Lock()
+	if rcv.forged != 0 {
+		t.Errorf("expected every request to be signed, got %d with a bad signature", rcv.forged)
+	}
+	rcv.mu.Unlock()
+	log, err := svc.Deliveries(ctx, sub.ID, model.DeliveryDelivered, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log) != 1 || log[0].Attempts != 1 || log[0].ResponseStatus != http.StatusNoContent {
+		t.Errorf("expected one delivered attempt in the log, got %+v", log)
+	}
+}
+
+func TestWebhookService_GeneratesSecret(t *testing.T) {
+	svc := newTestWebhookService(&time.Time{})
+	sub := &model.WebhookSubscription{URL: "https://example.com/hooks"}
+	if err := svc.Create(context.Background(), sub); err != nil {
+		t.Fatal(err)
+	}
+	if len(sub.Secret) < 32 {
+		t.Errorf("expected a generated secret, got %q", sub.Secret)
+	}
+}
+
+func TestWebhookService_RejectsInvalidSubscriptions(t *testing.T) {
+	svc := newTestWebhookService(&time.Time{})
+	for _, sub := range []*model.WebhookSubscription{
+		{URL: "example.com/hooks"},
+		{URL: "ftp://example.com/hooks"},
+		{URL: "https://example.com/hooks", EventTypes: []string{"OrderShipped"}},
+	} {
+		if err := svc.Create(context.Background(), sub); !errors.Is(err, ErrInvalidWebhook) {
+			t.Errorf("expected ErrInvalidWebhook for %+v, got %v", sub, err)
+		}
+	}
+}
+
+func TestWebhookService_RejectsNonPublicTargets(t *testing.T) {
+	svc := newTestWebhookService(&time.Time{})
+	svc.AllowPrivateTargets = false
+	for _, url := range []string{
+		"http://127.0.0.1:8080/hooks",
+		"http://localhost/hooks",
+		"http://[::1]/hooks",
+		"http://[::ffff:10.0.0.1]/hooks",
+		"https://10.1.2.3/hooks",
+		"https://192.168.0.10/hooks",
+		"http://169.254.169.254/latest/meta-data",
+		"http://100.100.100.200/latest/meta-data",
+		"http://0.0.0.0/hooks",
+	} {
+		if err := svc.Create(context.Background(), &model.WebhookSubscription{URL: url}); !errors.Is(err, ErrInvalidWebhook) {
+			t.Errorf("%s: expected ErrInvalidWebhook, got %v", url, err)
+		}
+	}
+	if err := svc.Create(context.Background(), &model.WebhookSubscription{URL: "https://203.0.113.10/hooks"}); err != nil {
+		t.Errorf("public address: %v", err)
+	}
+}
+
+func TestWebhookSenderHTTP_RefusesPrivateTargetsAndRedirects(t *testing.T) {
+	rcv := newWebhookReceiver(t, "s3cret")
+	redirect := httptest.NewServer(http.RedirectHandler(rcv.URL, http.StatusTemporaryRedirect))
+	t.Cleanup(redirect.Close)
+	ctx := context.Background()
+
+	// The host may resolve to a public address when the subscription is created and to loopback later.
+	public := out.WebhookSenderHTTP{Client: out.NewWebhookClient(0, false)}
+	if _, err := public.Send(ctx, rcv.URL, nil, []byte("{}")); err == nil || !strings.Contains(err.Error(), "not a public address") {
+		t.Errorf("expected the connection to a loopback address to be refused, got %v", err)
+	}
+
+	private := out.WebhookSenderHTTP{Client: out.NewWebhookClient(0, true)}
+	status, err := private.Send(ctx, redirect.URL, nil, []byte("{}"))
+	if err != nil || status != http.StatusTemporaryRedirect {
+		t.Errorf("got %d, %v, want the redirect itself as the response", status, err)
+	}
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	if len(rcv.received) != 0 || rcv.forged != 0 {
+		t.Error("expected the redirect not to be followed")
+	}
+}
+
+func TestWebhookService_RetriesWithBackoffUntilDead(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc := newTestWebhookService(&now)
+	rcv := newWebhookReceiver(t, "s3cret")
+	rcv.respond(http.StatusInternalServerError)
+
+	sub := &model.WebhookSubscription{URL: rcv.URL, Secret: "s3cret"}
+	if err := svc.Create(ctx, sub); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Publish(ctx, mustEvent(t, model.EventProductDeleted, uuid.New(), now)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Attempts at 12:00, 12:01 and 12:03 fail; the backoff doubles in between.
+	for i, wait := range []time.Duration{0, time.Minute, 2 * time.Minute} {
+		now = now.Add(wait - time.Second)
+		if n, _ := svc.Dispatch(ctx); i > 0 && n != 0 {
+			t.Fatalf("attempt %d: expected nothing due before the backoff, got %d", i+1, n)
+		}
+		now = now.Add(time.Second)
+		if n, err := svc.Dispatch(ctx); n != 1 || err != nil {
+			t.Fatalf("attempt %d: expected 1 delivery attempted, got %d, %v", i+1, n, err)
+		}
+	}
+	log, err := svc.Deliveries(ctx, sub.ID, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log) != 1 || log[0].Status != model.DeliveryDead || log[0].Attempts != 3 || log[0].ResponseStatus != 500 {
+		t.Fatalf("expected a dead delivery after 3 attempts, got %+v", log)
+	}
+	now = now.Add(24 * time.Hour)
+	if n, _ := svc.Dispatch(ctx); n != 0 {
+		t.Fatalf("expected a dead delivery not to be attempted again, got %d", n)
+	}
+
+	// Once the receiver is fixed, a manual retry delivers it.
+	rcv.respond(http.StatusOK)
+	if _, err := svc.Redeliver(ctx, sub.ID, log[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := svc.Dispatch(ctx); n != 1 || err != nil {
+		t.Fatalf("expected the redelivery to be attempted, got %d, %v", n, err)
+	}
+	if log, _ := svc.Deliveries(ctx, sub.ID, model.DeliveryDelivered, 0); len(log) != 1 {
+		t.Errorf("expected the redelivery to succeed, got %+v", log)
+	}
+	if got := len(rcv.events()); got != 4 {
+		t.Errorf("expected 4 requests received, got %d", got)
+	}
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Migrations holds the SQL migrations, in golang-migrate format.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+// NewMigrator returns a golang-migrate instance applying the embedded migrations to conn.
+// It shares the schema_migrations table with the migrate CLI used by the Makefile.
+func NewMigrator(conn *sql.DB) (*migrate.Migrate, error) {
+	src, err := iofs.New(Migrations, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("open migrations: %w", err)
+	}
+	driver, err := postgres.WithInstance(conn, &postgres.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("open migration driver: %w", err)
+	}
+	return migrate.NewWithInstance("iofs", src, "postgres", driver)
+}
+
+// MigrateUp applies all pending migrations.
+func MigrateUp(conn *sql.DB) error {
+	m, err := NewMigrator(conn)
+	if err != nil {
+		return err
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// MigrateDown rolls back the given number of migrations, which must be at least one.
+func MigrateDown(conn *sql.DB, steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("migrate down: steps must be at least 1, got %d", steps)
+	}
+	m, err := NewMigrator(conn)
+	if err != nil {
+		return err
+	}
+	if err := m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// MigrateDownAll rolls back every migration, dropping the whole schema and its data.
+func MigrateDownAll(conn *sql.DB) error {
+	m, err := NewMigrator(conn)
+	if err != nil {
+		return err
+	}
+	if err := m.Down(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// MigrationVersion returns the current schema version and whether the last migration failed midway.
+func MigrationVersion(conn *sql.DB) (version uint, dirty bool, err error) {
+	m, err := NewMigrator(conn)
+	if err != nil {
+		return 0, false, err
+	}
+	version, dirty, err = m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS customer_pack_rules;
+DROP TABLE IF EXISTS customers;
@@ -0,0 +1,16 @@
+CREATE TABLE customers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL
+);
+
+-- A rule with a NULL product_id applies to every product without a rule of its own.
+CREATE TABLE customer_pack_rules (
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    product_id UUID REFERENCES products(id) ON DELETE CASCADE,
+    allowed_sizes INT[] NOT NULL DEFAULT '{}',
+    forbidden_sizes INT[] NOT NULL DEFAULT '{}',
+    max_pack_size INT NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX customer_pack_rules_product_key
+    ON customer_pack_rules (customer_id, COALESCE(product_id, '00000000-0000-0000-0000-000000000000'));
@@ -0,0 +1,3 @@
+ALTER TABLE products
+    DROP COLUMN IF EXISTS packs_version,
+    DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+-- version counts updates of a product's own fields, packs_version replacements of its pack set.
+ALTER TABLE products
+    ADD COLUMN version BIGINT NOT NULL DEFAULT 1,
+    ADD COLUMN packs_version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,13 @@
+-- Domain events written in the same transaction as the changes raising them. The relay deletes them once
+-- they are published; until then, failed attempts are counted.
+CREATE TABLE outbox (
+    seq BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL UNIQUE,
+    type TEXT NOT NULL,
+    product_id UUID NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    payload JSONB NOT NULL,
+    claimed_until TIMESTAMPTZ,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT
+);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS cartons;
+ALTER TABLE packs
+    DROP CONSTRAINT IF EXISTS packs_dimensions_complete,
+    DROP COLUMN IF EXISTS length_mm,
+    DROP COLUMN IF EXISTS width_mm,
+    DROP COLUMN IF EXISTS height_mm,
+    DROP COLUMN IF EXISTS weight_g;
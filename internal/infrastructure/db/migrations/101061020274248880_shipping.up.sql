@@ -0,0 +1,18 @@
+-- Outer dimensions (mm) and weight (g) of a full pack, needed to plan shipments. Dimensions are all set or
+-- all NULL.
+ALTER TABLE packs
+    ADD COLUMN length_mm INT CHECK (length_mm > 0),
+    ADD COLUMN width_mm INT CHECK (width_mm > 0),
+    ADD COLUMN height_mm INT CHECK (height_mm > 0),
+    ADD COLUMN weight_g INT CHECK (weight_g > 0),
+    ADD CONSTRAINT packs_dimensions_complete
+        CHECK ((length_mm IS NULL) = (width_mm IS NULL) AND (width_mm IS NULL) = (height_mm IS NULL));
+
+CREATE TABLE cartons (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL,
+    inner_length_mm INT NOT NULL CHECK (inner_length_mm > 0),
+    inner_width_mm INT NOT NULL CHECK (inner_width_mm > 0),
+    inner_height_mm INT NOT NULL CHECK (inner_height_mm > 0),
+    max_weight_g INT NOT NULL CHECK (max_weight_g > 0)
+);
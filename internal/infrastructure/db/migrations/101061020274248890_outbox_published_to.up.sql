@@ -0,0 +1,2 @@
+-- Publishers that took an event whose publication failed elsewhere, so that retries skip them.
+ALTER TABLE outbox ADD COLUMN published_to TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+const namespace = "order_fulfillment"
+
+// Metrics holds the Prometheus collectors exposed on /metrics.
+// It implements port.FulfillmentMetrics and port.QueryMetrics.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests  *prometheus.CounterVec
+	httpDuration  *prometheus.HistogramVec
+	solveDuration prometheus.Histogram
+	searchSpace   prometheus.Histogram
+	overageItems  *prometheus.CounterVec
+	packsShipped  *prometheus.CounterVec
+	fulfillments  *prometheus.CounterVec
+	queryDuration *prometheus.HistogramVec
+}
+
+// New creates a Metrics instance with its own registry, including Go runtime and process collectors.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		solveDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "fulfillment_solve_duration_seconds",
+			Help:      "Time spent computing a pack fulfillment plan.",
+			Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 12),
+		}),
+		searchSpace: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "fulfillment_search_space_states",
+			Help:      "Number of solver states explored per fulfillment computation.",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 14),
+		}),
+		overageItems: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "fulfillment_overage_items_total",
+			Help:      "Items shipped beyond the requested quantity, by product.",
+		}, []string{"product_id"}),
+		packsShipped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "fulfillment_packs_total",
+			Help:      "Packs used in fulfillment plans, by product.",
+		}, []string{"product_id"}),
+		fulfillments: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "fulfillments_total",
+			Help:      "Fulfillment plans computed, by product.",
+		}, []string{"product_id"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Database query latency by repository and operation.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"repository", "operation"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.solveDuration,
+		m.searchSpace,
+		m.overageItems,
+		m.packsShipped,
+		m.fulfillments,
+		m.queryDuration,
+	)
+	return m
+}
+
+// Registry returns the registry backing this instance, so other components can register collectors.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// RegisterFulfillmentCache exposes the hit/miss counters and size of cache.
+func (m *Metrics) RegisterFulfillmentCache(cache *service.FulfillmentCache) {
+	m.registry.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "fulfillment_cache_hits_total",
+			Help:      "Fulfillment results served from the cache.",
+		}, func() float64 { return float64(cache.Stats().Hits) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "fulfillment_cache_misses_total",
+			Help:      "Fulfillment lookups not found in the cache.",
+		}, func() float64 { return float64(cache.Stats().Misses) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "fulfillment_cache_entries",
+			Help:      "Fulfillment results currently cached.",
+		}, func() float64 { return float64(cache.Stats().Size) }),
+	)
+}
+
+// Handler returns the HTTP handler serving the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records the count and latency of an HTTP request served by route.
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObserveSolve implements port.FulfillmentMetrics.
+func (m *Metrics) ObserveSolve(duration time.Duration, searchSpace int) {
+	m.solveDuration.Observe(duration.Seconds())
+	m.searchSpace.Observe(float64(searchSpace))
+}
+
+// ObserveResult implements port.FulfillmentMetrics.
+func (m *Metrics) ObserveResult(productID uuid.UUID, overage, packCount int) {
+	id := productID.String()
+	m.fulfillments.WithLabelValues(id).Inc()
+	m.overageItems.WithLabelValues(id).Add(float64(overage))
+	m.packsShipped.WithLabelValues(id).Add(float64(packCount))
+}
+
+// ObserveQuery implements port.QueryMetrics.
+func (m *Metrics) ObserveQuery(repository, operation string, duration time.Duration) {
+	m.queryDuration.WithLabelValues(repository, operation).Observe(duration.Seconds())
+}
@@ -0,0 +1,232 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// APIKeyHeader identifies a client whose key is one of the configured API keys; other requests are keyed by
+// IP address.
+const APIKeyHeader = "X-API-Key"
+
+// idleTTL is how long a client's bucket is kept after its last request.
+const idleTTL = 10 * time.Minute
+
+type client struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// ClientIdentity configures how a ClientLimiter tells clients apart.
+type ClientIdentity struct {
+	// TrustedProxies is the number of reverse proxies in front of the server, each appending the address it
+	// received the request from to X-Forwarded-For. The client is the address the outermost one appended;
+	// 0 ignores the header.
+	TrustedProxies int
+	// APIKeys are the keys that identify a client by X-API-Key. Unknown keys are ignored, so that a client
+	// cannot obtain fresh buckets by varying its key.
+	APIKeys []string
+}
+
+// ClientLimiter applies a token bucket per client, keyed by API key or IP address.
+type ClientLimiter struct {
+	mu             sync.Mutex
+	rps            rate.Limit
+	burst          int
+	trustedProxies int
+	apiKeys        map[string]bool
+	clients        map[string]*client
+	lastSweep      time.Time
+	now            func() time.Time
+}
+
+// NewClientLimiter creates a limiter allowing rps requests per second per client, with bursts of up to burst
+// requests. A non-positive rps lets every request through.
+func NewClientLimiter(rps float64, burst int, id ClientIdentity) *ClientLimiter {
+	l := &ClientLimiter{
+		clients: make(map[string]*client),
+		now:     time.Now,
+	}
+	l.SetLimits(rps, burst, id)
+	return l
+}
+
+// SetLimits changes the limits of the limiter, including the buckets of clients already seen.
+func (l *ClientLimiter) SetLimits(rps float64, burst int, id ClientIdentity) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.rps, l.burst, l.trustedProxies = rate.Limit(rps), burst, id.TrustedProxies
+	l.apiKeys = make(map[string]bool, len(id.APIKeys))
+	for _, key := range id.APIKeys {
+		l.apiKeys[key] = true
+	}
+	for _, c := range l.clients {
+		c.limiter.SetLimitAt(now, l.rps)
+		c.limiter.SetBurstAt(now, l.burst)
+	}
+}
+
+// Allow reports whether a request from key may proceed; if not, it returns how long the client should wait.
+func (l *ClientLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rps <= 0 {
+		return true, 0
+	}
+	now := l.now()
+	l.sweep(now)
+	c, ok := l.clients[key]
+	if !ok {
+		c = &client{limiter: rate.NewLimiter(l.rps, l.burst)}
+		l.clients[key] = c
+	}
+	c.lastSeen = now
+	res := c.limiter.ReserveN(now, 1)
+	if !res.OK() {
+		return false, time.Second
+	}
+	if delay := res.DelayFrom(now); delay > 0 {
+		res.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// sweep drops buckets of clients idle for longer than idleTTL. Callers must hold l.mu.
+func (l *ClientLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleTTL {
+		return
+	}
+	l.lastSweep = now
+	for key, c := range l.clients {
+		if now.Sub(c.lastSeen) > idleTTL {
+			delete(l.clients, key)
+		}
+	}
+}
+
+// Key returns the rate limiting key for r.
+func (l *ClientLimiter) Key(r *http.Request) string {
+	return l.ClientKey(r.Header.Get(APIKeyHeader), r.RemoteAddr, r.Header.Values("X-Forwarded-For"))
+}
+
+// ClientKey returns the rate limiting key of a client that sent apiKey, possibly empty, from remoteAddr, with
+// the given X-Forwarded-For header values.
+func (l *ClientLimiter) ClientKey(apiKey, remoteAddr string, forwardedFor []string) string {
+	l.mu.Lock()
+	known, trustedProxies := l.apiKeys[apiKey], l.trustedProxies
+	l.mu.Unlock()
+	if apiKey != "" && known {
+		return "key:" + apiKey
+	}
+	if ip := forwardedClient(forwardedFor, trustedProxies); ip != "" {
+		return "ip:" + ip
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return "ip:" + host
+}
+
+// forwardedClient returns the address the outermost of trustedProxies proxies appended to X-Forwarded-For,
+// counting from the right, or "" if there is no such valid address. Entries further left were sent by the
+// client and cannot be trusted.
+func forwardedClient(headers []string, trustedProxies int) string {
+	if trustedProxies <= 0 {
+		return ""
+	}
+	var hops []string
+	for _, h := range headers {
+		for hop := range strings.SplitSeq(h, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	if len(hops) < trustedProxies {
+		return ""
+	}
+	ip := net.ParseIP(hops[len(hops)-trustedProxies])
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
+
+// Middleware rejects requests exceeding the client's rate with 429 Too Many Requests.
+func (l *ClientLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ok, wait := l.Allow(l.Key(r)); !ok {
+			tooManyRequests(w, wait)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ConcurrencyLimiter bounds the number of requests processed at the same time.
+type ConcurrencyLimiter struct {
+	mu    sync.Mutex
+	max   int
+	inUse int
+}
+
+// NewConcurrencyLimiter creates a limiter allowing at most max concurrent requests.
+// A non-positive max lets every request through.
+func NewConcurrencyLimiter(max int) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{max: max}
+}
+
+// SetMax changes the number of concurrent requests allowed. Requests already running are not interrupted.
+func (l *ConcurrencyLimiter) SetMax(max int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.max = max
+}
+
+// TryAcquire takes a slot if one is free. Each successful call must be paired with Release.
+func (l *ConcurrencyLimiter) TryAcquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.max > 0 && l.inUse >= l.max {
+		return false
+	}
+	l.inUse++
+	return true
+}
+
+// Release returns a slot taken by TryAcquire.
+func (l *ConcurrencyLimiter) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inUse--
+}
+
+// Middleware rejects requests with 429 Too Many Requests while all slots are in use.
+func (l *ConcurrencyLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.TryAcquire() {
+			tooManyRequests(w, time.Second)
+			return
+		}
+		defer l.Release()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tooManyRequests writes a 429 response with a Retry-After header rounded up to whole seconds.
+func tooManyRequests(w http.ResponseWriter, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	w.WriteHeader(http.StatusTooManyRequests)
+}
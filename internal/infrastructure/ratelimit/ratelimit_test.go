@@ -0,0 +1,187 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientLimiter_RejectsAfterBurst(t *testing.T) {
+	limiter := NewClientLimiter(1, 2, ClientIdentity{APIKeys: []string{"client-a", "client-b"}})
+	now := time.Unix(0, 0)
+	limiter.now = func() time.Time { return now }
+
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/fulfill", nil)
+		if apiKey != "" {
+			req.Header.Set(APIKeyHeader, apiKey)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := serve("client-a"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected status %d, got %d", i, http.StatusOK, rec.Code)
+		}
+	}
+	rec := serve("client-a")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status %d, got %d", http.StatusTooManyRequests, rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("expected Retry-After 1, got %q", got)
+	}
+
+	// Other clients have their own bucket
+	if rec := serve("client-b"); rec.Code != http.StatusOK {
+		t.Errorf("expected status %d for another client, got %d", http.StatusOK, rec.Code)
+	}
+
+	// Tokens refill over time
+	now = now.Add(time.Second)
+	if rec := serve("client-a"); rec.Code != http.StatusOK {
+		t.Errorf("expected status %d after refill, got %d", http.StatusOK, rec.Code)
+	}
+}
+
+func TestClientLimiter_Key(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/fulfill", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+
+	if got := NewClientLimiter(1, 1, ClientIdentity{}).Key(req); got != "ip:10.0.0.1" {
+		t.Errorf("expected connection address, got %q", got)
+	}
+	if got := NewClientLimiter(1, 1, ClientIdentity{TrustedProxies: 2}).Key(req); got != "ip:203.0.113.7" {
+		t.Errorf("expected forwarded address, got %q", got)
+	}
+	req.Header.Set(APIKeyHeader, "secret")
+	if got := NewClientLimiter(1, 1, ClientIdentity{APIKeys: []string{"secret"}}).Key(req); got != "key:secret" {
+		t.Errorf("expected API key, got %q", got)
+	}
+}
+
+func TestClientLimiter_UnknownKeysShareTheAddressBucket(t *testing.T) {
+	limiter := NewClientLimiter(1, 1, ClientIdentity{APIKeys: []string{"secret"}})
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for i, key := range []string{"rotated-1", "rotated-2", "rotated-3"} {
+		req := httptest.NewRequest(http.MethodGet, "/fulfill", nil)
+		req.RemoteAddr = "198.51.100.9:4000"
+		req.Header.Set(APIKeyHeader, key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if want := map[bool]int{true: http.StatusOK, false: http.StatusTooManyRequests}[i == 0]; rec.Code != want {
+			t.Errorf("request %d with key %q: status %d, want %d", i, key, rec.Code, want)
+		}
+	}
+}
+
+func TestClientLimiter_IgnoresSpoofedForwardedFor(t *testing.T) {
+	// The client sends its own X-Forwarded-For; the single trusted proxy appends the real address.
+	limiter := NewClientLimiter(1, 1, ClientIdentity{TrustedProxies: 1})
+	for i, spoofed := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3, 192.0.2.4"} {
+		req := httptest.NewRequest(http.MethodGet, "/fulfill", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Add("X-Forwarded-For", spoofed)
+		req.Header.Add("X-Forwarded-For", "203.0.113.7")
+		if got := limiter.Key(req); got != "ip:203.0.113.7" {
+			t.Errorf("request %d: key %q, want the proxy-appended address", i, got)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		proxies int
+		fwd     string
+	}{
+		{"fewer hops than proxies", 2, "203.0.113.7"},
+		{"invalid hop", 1, "203.0.113.7, not-an-ip"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/fulfill", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", tt.fwd)
+		if got := NewClientLimiter(1, 1, ClientIdentity{TrustedProxies: tt.proxies}).Key(req); got != "ip:10.0.0.1" {
+			t.Errorf("%s: key %q, want the connection address", tt.name, got)
+		}
+	}
+}
+
+func TestConcurrencyLimiter_RejectsWhenFull(t *testing.T) {
+	limiter := NewConcurrencyLimiter(1)
+	if !limiter.TryAcquire() {
+		t.Fatal("expected first acquire to succeed")
+	}
+
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fulfill", nil))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status %d, got %d", http.StatusTooManyRequests, rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+
+	limiter.Release()
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fulfill", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status %d after release, got %d", http.StatusOK, rec.Code)
+	}
+}
+
+func TestClientLimiter_SetLimitsAppliesToExistingClients(t *testing.T) {
+	limiter := NewClientLimiter(1, 1, ClientIdentity{})
+	now := time.Unix(0, 0)
+	limiter.now = func() time.Time { return now }
+
+	if ok, _ := limiter.Allow("client-a"); !ok {
+		t.Fatal("expected first request to be allowed")
+	}
+	if ok, _ := limiter.Allow("client-a"); ok {
+		t.Fatal("expected second request to exceed the burst")
+	}
+
+	limiter.SetLimits(0, 0, ClientIdentity{})
+	if ok, _ := limiter.Allow("client-a"); !ok {
+		t.Error("expected requests to pass once rate limiting is disabled")
+	}
+
+	limiter.SetLimits(1, 3, ClientIdentity{})
+	now = now.Add(3 * time.Second)
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.Allow("client-a"); !ok {
+			t.Fatalf("request %d: expected the raised burst to apply", i)
+		}
+	}
+}
+
+func TestConcurrencyLimiter_SetMax(t *testing.T) {
+	limiter := NewConcurrencyLimiter(1)
+	if !limiter.TryAcquire() {
+		t.Fatal("expected first acquire to succeed")
+	}
+	if limiter.TryAcquire() {
+		t.Fatal("expected second acquire to fail")
+	}
+	limiter.SetMax(2)
+	if !limiter.TryAcquire() {
+		t.Fatal("expected acquire to succeed after raising the limit")
+	}
+	limiter.SetMax(1)
+	limiter.Release()
+	if limiter.TryAcquire() {
+		t.Error("expected acquire to fail while over the lowered limit")
+	}
+}
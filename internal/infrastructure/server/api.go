@@ -2,32 +2,287 @@ package server
 
 import (
 	"net/http"
+	"time"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 
 	"github.com/rlpaul93/order-fulfillment/internal/adapters/in"
 	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+	"github.com/rlpaul93/order-fulfillment/internal/infrastructure/metrics"
+	"github.com/rlpaul93/order-fulfillment/internal/infrastructure/ratelimit"
 )
 
-// NewHandler sets up the HTTP routes and returns the handler
-func NewHandler(prodSvc *service.ProductService, packSvc *service.PackService, fulfillSvc *service.PackFulfillmentService) http.Handler {
+// Option customizes the handler returned by NewHandler.
+type Option func(*options)
+
+type options struct {
+	metrics *metrics.Metrics
+	tracing bool
+
+	fulfillRate *ratelimit.ClientLimiter
+
+	configStatus http.Handler
+	configReload http.Handler
+
+	customers   *service.CustomerService
+	cartons     *service.CartonService
+	shipments   *service.ShipmentService
+	idempotency *service.IdempotencyService
+	webhooks    *service.WebhookService
+	events      *service.EventStream
+	graphql     http.Handler
+
+	legacyRoutes bool
+	legacySunset time.Time
+	versions     []apiVersion
+}
+
+type apiVersion struct {
+	name     string
+	register func(Routes)
+}
+
+// WithMetrics exposes m on GET /metrics and records HTTP traffic into it.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// WithTracing creates a server span per request, propagating W3C trace context from incoming headers.
+func WithTracing() Option {
+	return func(o *options) {
+		o.tracing = true
+	}
+}
+
+// WithFulfillRateLimit protects GET /fulfill with a per-client rate limiter. Concurrent solver work is
+// bounded by the PackFulfillmentService's Solvers, which every API shares.
+func WithFulfillRateLimit(clients *ratelimit.ClientLimiter) Option {
+	return func(o *options) {
+		o.fulfillRate = clients
+	}
+}
+
+// WithConfigAdmin exposes the active configuration on GET /admin/config and reloads it on POST /admin/config/reload.
+func WithConfigAdmin(status, reload http.Handler) Option {
+	return func(o *options) {
+		o.configStatus = status
+		o.configReload = reload
+	}
+}
+
+// WithCustomers serves customer CRUD under /customers. GET /fulfill applies a customer's pack rules through
+// the PackFulfillmentService's Customers.
+func WithCustomers(svc *service.CustomerService) Option {
+	return func(o *options) {
+		o.customers = svc
+	}
+}
+
+// WithShipments serves the carton catalog under /cartons and shipment plans on POST /shipments/plan and
+// POST /orders/plan, which draw on the limits of GET /fulfill.
+func WithShipments(cartons *service.CartonService, shipments *service.ShipmentService) Option {
+	return func(o *options) {
+		o.cartons = cartons
+		o.shipments = shipments
+	}
+}
+
+// WithWebhooks serves webhook subscriptions and their delivery logs under /webhooks.
+func WithWebhooks(svc *service.WebhookService) Option {
+	return func(o *options) {
+		o.webhooks = svc
+	}
+}
+
+// WithEventStream streams domain events as Server-Sent Events on GET /events.
+func WithEventStream(stream *service.EventStream) Option {
+	return func(o *options) {
+		o.events = stream
+	}
+}
+
+// WithGraphQL serves the GraphQL API h, see in.GraphQLHandler, on POST and GET /graphql, rate limited per
+// client like GET /fulfill.
+func WithGraphQL(h http.Handler) Option {
+	return func(o *options) {
+		o.graphql = h
+	}
+}
+
+// WithIdempotency lets clients retry POST, PUT, PATCH and DELETE requests safely by sending an
+// Idempotency-Key header; see in.IdempotencyMiddleware. Keys are scoped to the client as identified by
+// the WithFulfillRateLimit limiter.
+func WithIdempotency(svc *service.IdempotencyService) Option {
+	return func(o *options) {
+		o.idempotency = svc
+	}
+}
+
+// WithLegacyRoutes keeps serving the /v1 routes at their original unversioned paths, with Deprecation,
+// Sunset and successor Link headers. A zero sunset omits the Sunset header.
+func WithLegacyRoutes(sunset time.Time) Option {
+	return func(o *options) {
+		o.legacyRoutes = true
+		o.legacySunset = sunset
+	}
+}
+
+// WithAPIVersion mounts the routes registered by register under /<version>, side by side with /v1.
+func WithAPIVersion(version string, register func(Routes)) Option {
+	return func(o *options) {
+		o.versions = append(o.versions, apiVersion{name: version, register: register})
+	}
+}
+
+// NewHandler sets up the HTTP routes and returns the handler. The API routes are served under /v1, and
+// under any version added with WithAPIVersion; operational routes (Swagger, metrics, admin) are unversioned.
+func NewHandler(prodSvc *service.ProductService, packSvc *service.PackService, fulfillSvc *service.PackFulfillmentService, opts ...Option) http.Handler {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	mux := http.NewServeMux()
 
 	// Swagger UI
 	mux.HandleFunc("GET /swagger/", httpSwagger.WrapHandler)
 
-	// Product routes
-	mux.HandleFunc("POST /products", in.CreateProductHandler(prodSvc))
-	mux.HandleFunc("GET /products", in.ListProductsHandler(prodSvc))
-	mux.HandleFunc("GET /products/{id}", in.GetProductHandler(prodSvc))
-	mux.HandleFunc("DELETE /products/{id}", in.DeleteProductHandler(prodSvc))
+	// API versions
+	v1 := v1Routes(prodSvc, packSvc, fulfillSvc, &o)
+	v1(&routeGroup{mux: mux, prefix: "/v1"})
+	if o.legacyRoutes {
+		legacy := deprecated("/v1", o.legacySunset)
+		v1(&routeGroup{mux: mux, wrap: func(h http.Handler) http.Handler { return legacy(legacyFulfillmentSchema(h)) }})
+	}
+	for _, v := range o.versions {
+		v.register(&routeGroup{mux: mux, prefix: "/" + v.name})
+	}
+
+	// Admin routes
+	if o.configStatus != nil {
+		mux.Handle("GET /admin/config", o.configStatus)
+	}
+	if o.configReload != nil {
+		mux.Handle("POST /admin/config/reload", o.configReload)
+	}
+
+	var handler http.Handler = mux
+
+	// Idempotency replays are still measured and traced
+	if o.idempotency != nil {
+		handler = in.IdempotencyMiddleware(o.idempotency, clientKey(&o))(handler)
+	}
+
+	// Metrics
+	if o.metrics != nil {
+		mux.Handle("GET /metrics", o.metrics.Handler())
+		handler = instrument(o.metrics, handler)
+	}
 
-	// Pack routes (nested under products)
-	mux.HandleFunc("GET /products/{id}/packs", in.ListPacksForProductHandler(packSvc))
-	mux.HandleFunc("PUT /products/{id}/packs", in.UpdatePacksForProductHandler(packSvc))
+	// Tracing is outermost so the server span covers the whole request
+	if o.tracing {
+		handler = traceRequests(handler)
+	}
 
-	// Fulfillment route
-	mux.HandleFunc("GET /fulfill", in.PackFulfillmentHandler(fulfillSvc, packSvc))
+	return handler
+}
+
+// v1Routes returns a function registering the version 1 API routes.
+func v1Routes(prodSvc *service.ProductService, packSvc *service.PackService, fulfillSvc *service.PackFulfillmentService, o *options) func(Routes) {
+	// Fulfillment is rate limited before it competes for a solver slot in the service. The handlers are
+	// shared by every mount, so /v1 and the legacy aliases draw on the same limits.
+	limitRate := func(h http.Handler) http.Handler {
+		if o.fulfillRate != nil {
+			h = o.fulfillRate.Middleware(h)
+		}
+		return h
+	}
+	fulfill := limitRate(in.PackFulfillmentHandler(fulfillSvc))
+	// GraphQL fulfill fields take solver slots one by one, but each request draws on the client's rate.
+	var graphql http.Handler
+	if o.graphql != nil {
+		graphql = limitRate(o.graphql)
+	}
+	var planShipment, planOrder http.Handler
+	if o.shipments != nil {
+		planShipment = limitRate(in.PlanShipmentHandler(o.shipments))
+		planOrder = limitRate(in.PlanOrderHandler(o.shipments))
+	}
+	catalogSvc := &service.CatalogService{Products: prodSvc, Packs: packSvc}
+
+	return func(r Routes) {
+		// Product routes
+		r.HandleFunc("POST /products", in.CreateProductHandler(prodSvc))
+		r.HandleFunc("GET /products", in.ListProductsHandler(prodSvc))
+		r.HandleFunc("GET /products/{id}", in.GetProductHandler(prodSvc))
+		r.HandleFunc("PUT /products/{id}", in.UpdateProductHandler(prodSvc))
+		r.HandleFunc("DELETE /products/{id}", in.DeleteProductHandler(prodSvc))
+
+		// Pack routes (nested under products)
+		r.HandleFunc("GET /products/{id}/packs", in.ListPacksForProductHandler(packSvc))
+		r.HandleFunc("PUT /products/{id}/packs", in.UpdatePacksForProductHandler(packSvc))
+		r.HandleFunc("PUT /products/{id}/packs/{packID}", in.UpdatePackMeasurementsHandler(packSvc))
+
+		// Customer routes
+		if o.customers != nil {
+			r.HandleFunc("POST /customers", in.CreateCustomerHandler(o.customers))
+			r.HandleFunc("GET /customers", in.ListCustomersHandler(o.customers))
+			r.HandleFunc("GET /customers/{id}", in.GetCustomerHandler(o.customers))
+			r.HandleFunc("PUT /customers/{id}", in.UpdateCustomerHandler(o.customers))
+			r.HandleFunc("DELETE /customers/{id}", in.DeleteCustomerHandler(o.customers))
+		}
+
+		// Carton and shipment routes
+		if o.cartons != nil {
+			r.HandleFunc("POST /cartons", in.CreateCartonHandler(o.cartons))
+			r.HandleFunc("GET /cartons", in.ListCartonsHandler(o.cartons))
+			r.HandleFunc("GET /cartons/{id}", in.GetCartonHandler(o.cartons))
+			r.HandleFunc("PUT /cartons/{id}", in.UpdateCartonHandler(o.cartons))
+			r.HandleFunc("DELETE /cartons/{id}", in.DeleteCartonHandler(o.cartons))
+		}
+		if o.shipments != nil {
+			r.Handle("POST /shipments/plan", planShipment)
+			r.Handle("POST /orders/plan", planOrder)
+		}
+
+		// Webhook routes
+		if o.webhooks != nil {
+			r.HandleFunc("POST /webhooks", in.CreateWebhookHandler(o.webhooks))
+			r.HandleFunc("GET /webhooks", in.ListWebhooksHandler(o.webhooks))
+			r.HandleFunc("GET /webhooks/{id}", in.GetWebhookHandler(o.webhooks))
+			r.HandleFunc("DELETE /webhooks/{id}", in.DeleteWebhookHandler(o.webhooks))
+			r.HandleFunc("GET /webhooks/{id}/deliveries", in.ListWebhookDeliveriesHandler(o.webhooks))
+			r.HandleFunc("POST /webhooks/{id}/deliveries/{deliveryID}/retry", in.RetryWebhookDeliveryHandler(o.webhooks))
+		}
+
+		// Event stream
+		if o.events != nil {
+			r.HandleFunc("GET /events", in.EventStreamHandler(o.events))
+		}
+
+		// GraphQL
+		if graphql != nil {
+			r.Handle("POST /graphql", graphql)
+			r.Handle("GET /graphql", graphql)
+		}
+
+		// Bulk catalog routes
+		r.HandleFunc("POST /catalog/import", in.ImportCatalogHandler(catalogSvc))
+		r.HandleFunc("GET /catalog/export", in.ExportCatalogHandler(catalogSvc))
+
+		// Fulfillment routes
+		r.Handle("GET /fulfill", fulfill)
+		r.HandleFunc("GET /products/{id}/fulfillment-table", in.FulfillmentTableStatusHandler(fulfillSvc))
+	}
+}
 
-	return mux
+// clientKey returns how requests are attributed to clients: like the fulfillment rate limiter, by known API
+// key or address, or by address alone without one.
+func clientKey(o *options) func(*http.Request) string {
+	if o.fulfillRate != nil {
+		return o.fulfillRate.Key
+	}
+	return ratelimit.NewClientLimiter(0, 0, ratelimit.ClientIdentity{}).Key
 }
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+	"github.com/rlpaul93/order-fulfillment/internal/infrastructure/ratelimit"
+)
+
+func TestNewHandler_GraphQLIsRateLimited(t *testing.T) {
+	graphql := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {})
+	handler := NewHandler(
+		&service.ProductService{Repo: out.NewProductRepositoryMem()},
+		&service.PackService{Repo: out.NewPackRepositoryMem()},
+		&service.PackFulfillmentService{},
+		WithFulfillRateLimit(ratelimit.NewClientLimiter(1, 1, ratelimit.ClientIdentity{})),
+		WithGraphQL(graphql),
+	)
+
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/graphql", strings.NewReader(`{"query":"{ __typename }"}`)))
+		if rec.Code != want {
+			t.Errorf("request %d: status %d, want %d", i, rec.Code, want)
+		}
+	}
+}
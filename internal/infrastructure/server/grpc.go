@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/rlpaul93/order-fulfillment/api/proto/orderfulfillment/v1"
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/in"
+	"github.com/rlpaul93/order-fulfillment/internal/infrastructure/ratelimit"
+)
+
+// solverMethods are the gRPC methods rate limited per client like GET /fulfill.
+var solverMethods = map[string]bool{
+	pb.OrderFulfillmentService_Fulfill_FullMethodName:      true,
+	pb.OrderFulfillmentService_BatchFulfill_FullMethodName: true,
+}
+
+// NewGRPCServer returns a gRPC server exposing svc, the standard health service and server reflection
+// (for tools such as grpcurl). Every call gets a server span, continuing the trace from incoming W3C
+// trace context metadata, and is logged with its status code. Unless clients is nil, fulfillments beyond
+// a client's rate fail with ResourceExhausted; in BatchFulfill, each order draws on the rate and an order
+// beyond it fails on its own, see in.WithOrderLimit.
+func NewGRPCServer(svc pb.OrderFulfillmentServiceServer, clients *ratelimit.ClientLimiter) *grpc.Server {
+	unary := []grpc.UnaryServerInterceptor{traceUnary}
+	stream := []grpc.StreamServerInterceptor{traceStream}
+	if clients != nil {
+		unary = append(unary, limitUnary(clients))
+		stream = append(stream, limitStream(clients))
+	}
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
+	pb.RegisterOrderFulfillmentServiceServer(srv, svc)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	reflection.Register(srv)
+	return srv
+}
+
+func traceUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, end := startCall(ctx, info.FullMethod)
+	resp, err := handler(ctx, req)
+	end(err)
+	return resp, err
+}
+
+func traceStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, end := startCall(ss.Context(), info.FullMethod)
+	err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	end(err)
+	return err
+}
+
+func limitUnary(clients *ratelimit.ClientLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if solverMethods[info.FullMethod] {
+			if err := allowClient(ctx, clients); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func limitStream(clients *ratelimit.ClientLimiter) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !solverMethods[info.FullMethod] {
+			return handler(srv, ss)
+		}
+		allow := func(ctx context.Context) error { return allowClient(ctx, clients) }
+		return handler(srv, &contextStream{ServerStream: ss, ctx: in.WithOrderLimit(ss.Context(), allow)})
+	}
+}
+
+// allowClient takes a token from the bucket of the caller in ctx, and fails with ResourceExhausted if it is
+// empty. The caller is identified by its x-api-key metadata if that is a known API key, and by its peer
+// address otherwise; gRPC has no trusted proxies, so x-forwarded-for metadata is ignored.
+func allowClient(ctx context.Context, clients *ratelimit.ClientLimiter) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var apiKey, addr string
+	if v := md.Get(ratelimit.APIKeyHeader); len(v) > 0 {
+		apiKey = v[0]
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr = p.Addr.String()
+	}
+	if ok, wait := clients.Allow(clients.ClientKey(apiKey, addr, nil)); !ok {
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded; retry in %s", wait.Round(time.Millisecond))
+	}
+	return nil
+}
+
+// startCall starts the server span of a call to method and returns a function ending it with the call's error.
+func startCall(ctx context.Context, method string) (context.Context, func(error)) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	ctx, span := tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer))
+	span.SetAttributes(attribute.String("rpc.system", "grpc"), attribute.String("rpc.method", method))
+	start := time.Now()
+	return ctx, func(err error) {
+		code := status.Code(err)
+		span.SetAttributes(attribute.String("rpc.grpc.status_code", code.String()))
+		if code == codes.Internal || code == codes.Unknown || code == codes.Unavailable || code == codes.DeadlineExceeded {
+			span.SetStatus(otelcodes.Error, code.String())
+		}
+		span.End()
+		slog.InfoContext(ctx, "gRPC call", "method", method, "code", code.String(), "duration", time.Since(start))
+	}
+}
+
+// contextStream hands a derived context, such as the span's, to stream handlers.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// metadataCarrier adapts gRPC metadata to propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/rlpaul93/order-fulfillment/api/proto/orderfulfillment/v1"
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/in"
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+	"github.com/rlpaul93/order-fulfillment/internal/infrastructure/ratelimit"
+)
+
+// stubFulfillmentServer answers Fulfill and GetProduct with empty responses.
+type stubFulfillmentServer struct {
+	pb.UnimplementedOrderFulfillmentServiceServer
+}
+
+func (stubFulfillmentServer) Fulfill(context.Context, *pb.FulfillRequest) (*pb.FulfillResponse, error) {
+	return &pb.FulfillResponse{}, nil
+}
+
+func (stubFulfillmentServer) GetProduct(context.Context, *pb.GetProductRequest) (*pb.Product, error) {
+	return &pb.Product{}, nil
+}
+
+// newTestGRPCClient serves svc with a rate limit of one fulfillment per client, behind one trusted HTTP proxy,
+// and returns a client connected to it.
+func newTestGRPCClient(t *testing.T, svc pb.OrderFulfillmentServiceServer) pb.OrderFulfillmentServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := NewGRPCServer(svc, ratelimit.NewClientLimiter(1, 1, ratelimit.ClientIdentity{TrustedProxies: 1, APIKeys: []string{"client-a"}}))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewOrderFulfillmentServiceClient(conn)
+}
+
+func TestNewGRPCServer_RateLimitsFulfillments(t *testing.T) {
+	client := newTestGRPCClient(t, stubFulfillmentServer{})
+	ctx := context.Background()
+
+	for i, want := range []codes.Code{codes.OK, codes.ResourceExhausted} {
+		if _, err := client.Fulfill(ctx, &pb.FulfillRequest{}); status.Code(err) != want {
+			t.Errorf("fulfillment %d: code %v, want %v", i, status.Code(err), want)
+		}
+	}
+	if _, err := client.GetProduct(ctx, &pb.GetProductRequest{}); err != nil {
+		t.Errorf("GetProduct is not rate limited, got %v", err)
+	}
+	// A known API key has its own bucket; an unknown one shares the address's.
+	keyed := func(key string) context.Context { return metadata.AppendToOutgoingContext(ctx, "x-api-key", key) }
+	if _, err := client.Fulfill(keyed("client-a"), &pb.FulfillRequest{}); err != nil {
+		t.Errorf("known API key: %v", err)
+	}
+	if _, err := client.Fulfill(keyed("rotated"), &pb.FulfillRequest{}); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("unknown API key: code %v, want %v", status.Code(err), codes.ResourceExhausted)
+	}
+}
+
+func TestNewGRPCServer_IgnoresForwardedFor(t *testing.T) {
+	client := newTestGRPCClient(t, stubFulfillmentServer{})
+
+	for i, want := range []codes.Code{codes.OK, codes.ResourceExhausted} {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-forwarded-for", fmt.Sprintf("203.0.113.%d", i))
+		if _, err := client.Fulfill(ctx, &pb.FulfillRequest{}); status.Code(err) != want {
+			t.Errorf("fulfillment %d: code %v, want %v", i, status.Code(err), want)
+		}
+	}
+}
+
+func TestNewGRPCServer_RateLimitsBatchOrders(t *testing.T) {
+	packSvc := &service.PackService{Repo: out.NewPackRepositoryMem()}
+	client := newTestGRPCClient(t, &in.GRPCServer{
+		Products:    &service.ProductService{Repo: out.NewProductRepositoryMem()},
+		Packs:       packSvc,
+		Fulfillment: &service.PackFulfillmentService{Packs: packSvc},
+	})
+	stream, err := client.BatchFulfill(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		if err := stream.Send(&pb.FulfillRequest{RequestId: id, ProductId: "nope", Quantity: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first order is answered; the others exceed the rate, each on its own, and the stream goes on.
+	wants := []codes.Code{codes.InvalidArgument, codes.ResourceExhausted, codes.ResourceExhausted}
+	for i := 0; ; i++ {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			if i != len(wants) {
+				t.Errorf("expected %d responses, got %d", len(wants), i)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("order %d: stream aborted: %v", i, err)
+		}
+		if got := codes.Code(resp.GetError().GetCode()); i < len(wants) && got != wants[i] {
+			t.Errorf("order %d: code %v, want %v", i, got, wants[i])
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/in"
+)
+
+// legacyDeprecatedAt is when the unversioned routes were deprecated in favour of /v1.
+var legacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// Routes registers the handlers of one API version. Patterns are written without the version prefix,
+// e.g. "GET /fulfill", and are mounted under it, e.g. "GET /v1/fulfill".
+type Routes interface {
+	Handle(pattern string, handler http.Handler)
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+// routeGroup mounts patterns under a path prefix of a shared mux, wrapping each handler.
+type routeGroup struct {
+	mux    *http.ServeMux
+	prefix string
+	wrap   func(http.Handler) http.Handler // optional
+}
+
+func (g *routeGroup) Handle(pattern string, handler http.Handler) {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		method, path = "", pattern
+	}
+	mounted := strings.TrimSpace(method + " " + g.prefix + path)
+	if g.wrap != nil {
+		handler = g.wrap(handler)
+	}
+	g.mux.Handle(mounted, handler)
+}
+
+func (g *routeGroup) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	g.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// deprecated marks responses of a legacy route as deprecated (RFC 9745), announces its sunset (RFC 8594)
+// and links to the route that replaces it.
+func deprecated(successorPrefix string, sunset time.Time) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", legacyDeprecatedAt.Unix()))
+			if !sunset.IsZero() {
+				w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+			w.Header().Add("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, successorPrefix, r.URL.Path))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// legacyFulfillmentSchema pins GET /fulfill on a legacy route to the response shape it had before /v1, schema
+// version 1, so that its clients keep working until the sunset.
+func legacyFulfillmentSchema(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fulfill" {
+			r = r.Clone(r.Context())
+			query := r.URL.Query()
+			query.Set("schema_version", strconv.Itoa(in.FulfillmentSchemaLegacy))
+			r.URL.RawQuery = query.Encode()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
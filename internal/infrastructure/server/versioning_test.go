@@ -0,0 +1,101 @@
+package server
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rlpaul93/order-fulfillment/internal/adapters/out"
+	"github.com/rlpaul93/order-fulfillment/internal/domain/service"
+)
+
+func TestNewHandler_Versioning(t *testing.T) {
+	prodSvc := &service.ProductService{Repo: out.NewProductRepositoryMem()}
+	packSvc := &service.PackService{Repo: out.NewPackRepositoryMem()}
+	fulfillSvc := &service.PackFulfillmentService{}
+	sunset := time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+
+	handler := NewHandler(prodSvc, packSvc, fulfillSvc,
+		WithLegacyRoutes(sunset),
+		WithAPIVersion("v2", func(r Routes) {
+			r.HandleFunc("GET /products", func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			})
+		}),
+	)
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		deprecated bool
+	}{
+		{path: "/v1/products", wantStatus: http.StatusOK},
+		{path: "/products", wantStatus: http.StatusOK, deprecated: true},
+		{path: "/v2/products", wantStatus: http.StatusTeapot},
+		{path: "/v2/fulfill", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if got := rec.Header().Get("Deprecation") != ""; got != tt.deprecated {
+				t.Fatalf("expected deprecated=%v, got Deprecation %q", tt.deprecated, rec.Header().Get("Deprecation"))
+			}
+			if !tt.deprecated {
+				return
+			}
+			if got := rec.Header().Get("Sunset"); got != "Fri, 30 Apr 2027 00:00:00 GMT" {
+				t.Errorf("unexpected Sunset %q", got)
+			}
+			if got := rec.Header().Get("Link"); got != `</v1/products>; rel="successor-version"` {
+				t.Errorf("unexpected Link %q", got)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	NewHandler(prodSvc, packSvc, fulfillSvc).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/products", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected legacy routes to be off by default, got status %d", rec.Code)
+	}
+}
+
+func TestNewHandler_LegacyFulfillKeepsBaselineShape(t *testing.T) {
+	packSvc := &service.PackService{Repo: out.NewPackRepositoryMem()}
+	fulfillSvc := &service.PackFulfillmentService{Packs: packSvc}
+	productID := uuid.New()
+	if _, err := packSvc.ReplaceByProduct(t.Context(), productID, []int{250, 1000}); err != nil {
+		t.Fatal(err)
+	}
+	handler := NewHandler(&service.ProductService{Repo: out.NewProductRepositoryMem()}, packSvc, fulfillSvc,
+		WithLegacyRoutes(time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)))
+
+	for _, query := range []string{"", "&schema_version=2"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fulfill?product_id="+productID.String()+"&quantity=1200"+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%q: expected status 200, got %d", query, rec.Code)
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(rec.Body.Bytes(), &fields); err != nil {
+			t.Fatalf("%q: failed to decode response: %v", query, err)
+		}
+		if _, ok := fields["schema_version"]; ok {
+			t.Errorf("%q: expected the baseline shape, got %s", query, rec.Body)
+		}
+		var result service.PackFulfillmentResult
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			t.Fatalf("%q: failed to decode response: %v", query, err)
+		}
+		if result.TotalItems != 1250 || !maps.Equal(result.Packs, map[int]int{1000: 1, 250: 1}) {
+			t.Errorf("%q: unexpected result %+v", query, result)
+		}
+	}
+}